@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
@@ -17,6 +18,12 @@ func main() {
 	c := cmd.InitCommands(version)
 	if err := c.ExecuteContext(ctx); err != nil {
 		cancel()
+
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+
 		os.Exit(1)
 	}
 
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"os/user"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/ksysoev/wsget/pkg/core"
@@ -14,7 +19,9 @@ import (
 	"github.com/ksysoev/wsget/pkg/core/edit"
 	"github.com/ksysoev/wsget/pkg/core/formater"
 	"github.com/ksysoev/wsget/pkg/input"
+	"github.com/ksysoev/wsget/pkg/protocol"
 	"github.com/ksysoev/wsget/pkg/repo/history"
+	"github.com/ksysoev/wsget/pkg/repo/hosts"
 	"github.com/ksysoev/wsget/pkg/repo/macro"
 	"github.com/ksysoev/wsget/pkg/ws"
 	"github.com/spf13/cobra"
@@ -23,13 +30,45 @@ import (
 
 const (
 	macroDir              = "macro"
+	messagesDir           = "messages"
 	historyFilename       = "history"
 	historyCmdFilename    = "cmd_history"
 	historyBinaryFilename = "history_binary"
+	hostsFilename         = "hosts.yaml"
 	configDirMode         = 0o755
+	timingFileMode        = 0o644
 	defaultConfigDir      = ".wsget"
+	defaultServePort      = 8080
+	defaultProxyPort      = 8081
+	defaultIdleTimeout    = 2 * time.Second
+
+	closeNormalClosure    = 1000
+	closeLastProtocolCode = 1015
+	exitCodeCloseBase     = 100
 )
 
+// keyInput is the source of key events of an interactive session.
+type keyInput interface {
+	Run(ctx context.Context) error
+	Close()
+}
+
+// newKeyInput creates the source of key events of an interactive session reading the keyboard of the terminal.
+// It is a variable so that sessions can run without a terminal in tests.
+var newKeyInput = func(handler input.KeyHandler) keyInput {
+	return input.NewKeyboard(handler)
+}
+
+// ExitError is returned when the process should exit with a specific status code.
+type ExitError struct {
+	Code int
+}
+
+// Error returns the description of the exit status.
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
 // createConnectRunner creates a runner function for the connect command.
 // It takes a single parameter args of type *flags.
 // It returns a function that takes a *cobra.Command and a slice of strings, and returns an error.
@@ -37,7 +76,21 @@ const (
 // It returns an error if runConnectCmd encounters any issues.
 func createConnectRunner(args *flags) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, unnamedArgs []string) error {
-		return runConnectCmd(cmd.Context(), args, unnamedArgs)
+		// Pipe mode is enabled when requests are piped to stdin, unless the session is driven by the single response
+		// or input file options. Redirected stdout keeps the interactive session, colors are disabled for it by the color package.
+		if !cmd.Flags().Changed("pipe") && args.waitResponse < 0 && args.inputFile == "" {
+			args.pipe = !isTerminal(os.Stdin)
+		}
+
+		err := runConnectCmd(cmd.Context(), args, unnamedArgs)
+
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+		}
+
+		return err
 	}
 }
 
@@ -55,38 +108,74 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 		return fmt.Errorf("invalid arguments: %w", err)
 	}
 
-	wsOpts := &ws.Options{
-		SkipSSLVerification: args.insecure,
-		Headers:             args.headers,
-		UserAgent:           "wsget/" + args.version,
-		MaxMessageSize:      args.maxMsgSize,
-		Timeout:             time.Duration(args.timeout) * time.Second,
+	if args.configDir == "" {
+		currentUser, err := user.Current()
+		if err != nil {
+			return fmt.Errorf("fail to get current user: %s", err)
+		}
+
+		args.configDir = filepath.Join(currentUser.HomeDir, defaultConfigDir)
 	}
 
-	if args.verbose {
-		wsOpts.Output = os.Stdout
+	if err := applyFaultProfile(args); err != nil {
+		return fmt.Errorf("failed to apply fault profile: %w", err)
 	}
 
-	wsConn, err := ws.New(wsURL, wsOpts)
-	if err != nil {
-		return fmt.Errorf("unable to connect to the server: %w", err)
+	// Named connections opened during the session apply the host configuration of their own URLs.
+	connFactory := &connectionFactory{args: *args}
+
+	if err := applyHostConfig(wsURL, args); err != nil {
+		return fmt.Errorf("failed to apply host configuration: %w", err)
 	}
 
-	defer func() { _ = wsConn.Close() }()
+	wsOpts, err := newWSOptions(args)
+	if err != nil {
+		return err
+	}
 
-	if args.configDir == "" {
-		currentUser, err := user.Current()
+	if args.timing != "" {
+		timing, err := openTimingOutput(args.timing)
 		if err != nil {
-			return fmt.Errorf("fail to get current user: %s", err)
+			return err
 		}
 
-		args.configDir = filepath.Join(currentUser.HomeDir, defaultConfigDir)
+		defer func() { _ = timing.Close() }()
+
+		wsOpts.Timing = timing
+		connFactory.timing = timing
 	}
 
 	if err = os.MkdirAll(filepath.Join(args.configDir, macroDir), configDirMode); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	hostname, err := urlHostname(wsURL)
+	if err != nil {
+		return err
+	}
+
+	macroRepo, err := macro.LoadMacroForDomain(filepath.Join(args.configDir, macroDir), hostname)
+	if err != nil {
+		return fmt.Errorf("failed to load macros for domain %q: %w", hostname, err)
+	}
+
+	if macroRepo != nil && ws.IsWebSocket(wsURL) {
+		if err := applyMacroSubprotocol(wsOpts, macroRepo.Subprotocol()); err != nil {
+			return err
+		}
+	}
+
+	wsConn, err := ws.NewTransport(wsURL, wsOpts)
+	if err != nil {
+		return fmt.Errorf("unable to connect to the server: %w", err)
+	}
+
+	defer func() { _ = wsConn.Close() }()
+
+	if args.pipe {
+		return runPipe(ctx, args, wsConn)
+	}
+
 	reqHistory, err := history.LoadFromFile(filepath.Join(args.configDir, historyFilename))
 	if err != nil {
 		return fmt.Errorf("failed to load request history: %w", err)
@@ -108,11 +197,6 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 
 	defer func() { _ = binHistory.Close() }()
 
-	macroRepo, err := macro.LoadMacroForDomain(filepath.Join(args.configDir, macroDir), wsConn.Hostname())
-	if err != nil {
-		return fmt.Errorf("failed to load macros for domain %q: %w", wsConn.Hostname(), err)
-	}
-
 	var cmdFactory *command2.Factory
 
 	if macroRepo != nil {
@@ -124,12 +208,34 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 
 	editor := edit.NewMultiMode(os.Stdout, reqHistory, cmdHistory, binHistory)
 
-	client := core.NewCLI(cmdFactory, wsConn, os.Stdout, editor, formater.NewFormat())
+	outputFormat, err := formater.NewSwitch(args.format)
+	if err != nil {
+		return fmt.Errorf("failed to initialize output format: %w", err)
+	}
+
+	client := core.NewCLI(cmdFactory, wsConn, os.Stdout, editor, outputFormat)
+
+	client.SetInfoOutput(os.Stderr)
+
+	connFactory.client = client
+	client.SetConnectionFactory(connFactory)
+
+	if conn, ok := wsConn.(*ws.Connection); ok {
+		conn.SetOnClose(func(ctx context.Context, status ws.CloseStatus) {
+			client.OnConnectionStatus(ctx, core.ConnectionStatus{State: "closed by server: " + status.String()})
+		})
+
+		if args.reconnect {
+			conn.SetOnStateChange(func(ctx context.Context, state ws.State, attempt int) {
+				client.OnConnectionStatus(ctx, newConnectionStatus(state, attempt))
+			})
+		}
+	}
 
-	keyboard := input.NewKeyboard(client)
+	keyboard := newKeyInput(client)
 	defer keyboard.Close()
 
-	opts, err := initRunOptions(args)
+	opts, err := initRunOptions(args, cmdFactory)
 	if err != nil {
 		return fmt.Errorf("failed to initialize run options: %w", err)
 	}
@@ -159,6 +265,10 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 		case <-wsConn.Ready():
 		}
 
+		if conn, ok := wsConn.(*ws.Connection); ok && wsOpts.Output != nil && conn.Subprotocol() != "" {
+			_, _ = fmt.Fprintln(wsOpts.Output, "Subprotocol:", conn.Subprotocol())
+		}
+
 		if err := client.Run(ctx, *opts); err != nil {
 			return fmt.Errorf("CLI run failed: %w", err)
 		}
@@ -167,16 +277,150 @@ func runConnectCmd(ctx context.Context, args *flags, unnamedArgs []string) error
 	})
 
 	err = eg.Wait()
+	closeStatus := wsConn.CloseStatus()
 
-	if errors.Is(err, context.Canceled) || errors.Is(err, core.ErrInterrupted) {
-		return nil
+	// Output formats printed without headers are machine-readable, so the diagnostics are kept out of them.
+	var info io.Writer = os.Stdout
+	if !outputFormat.Headers() {
+		info = os.Stderr
 	}
 
-	fmt.Println("Error:", err)
+	printSessionError(info, err, closeStatus)
+
+	// The summary is skipped in single response mode, which is used in scripts.
+	if metrics := wsConn.Metrics(); args.waitResponse < 0 && (metrics.Sent.Messages > 0 || metrics.Received.Messages > 0) {
+		_, _ = fmt.Fprint(info, metrics.Summary())
+	}
+
+	if code := closeExitCode(closeStatus); code != 0 {
+		return &ExitError{Code: code}
+	}
 
 	return nil
 }
 
+// printSessionError writes the error that ended the session to out.
+// It takes out of type io.Writer, err, the error returned by the session, and closeStatus, the final close status of the connection.
+// Nothing is written if the session was interrupted or the connection was closed locally.
+func printSessionError(out io.Writer, err error, closeStatus ws.CloseStatus) {
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled) || errors.Is(err, core.ErrInterrupted):
+	case errors.Is(err, ws.ErrConnectionClosed) && closeStatus.Code != 0 && !closeStatus.Remote:
+	default:
+		_, _ = fmt.Fprintln(out, "Error:", err)
+	}
+}
+
+// newWSOptions builds the WebSocket connection options from the provided flags.
+// It takes a single parameter args of type *flags.
+// It returns a pointer to ws.Options; the handshake timing output is not set and should be opened by the caller.
+// Every call creates a new instance of the application protocol, so that each connection keeps its own protocol state.
+// It returns an error if the protocol is not supported or its options are invalid.
+func newWSOptions(args *flags) (*ws.Options, error) {
+	proto, err := protocol.New(args.protocol, protocol.Options{
+		Namespaces:  args.namespaces,
+		InitPayload: args.initPayload,
+		MQTTVersion: args.mqttVersion,
+		Headers:     args.stompHeaders,
+		Heartbeat:   args.heartbeat,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid protocol options: %w", err)
+	}
+
+	wsOpts := &ws.Options{
+		SkipSSLVerification:  args.insecure,
+		Headers:              args.headers,
+		Subprotocols:         args.subprotocols,
+		UserAgent:            "wsget/" + args.version,
+		Proxy:                args.proxy,
+		MaxMessageSize:       args.maxMsgSize,
+		Oversize:             args.oversize,
+		OversizeDir:          filepath.Join(args.configDir, messagesDir),
+		Timeout:              time.Duration(args.timeout) * time.Second,
+		Compression:          args.compression,
+		CompressionThreshold: args.compressionThreshold,
+		Protocol:             proto,
+		Keepalive:            args.keepalive,
+		KeepaliveTimeout:     args.keepaliveTimeout,
+		KeepaliveFailures:    args.keepaliveFailures,
+		TLS: ws.TLSOptions{
+			CertFile:   args.cert,
+			KeyFile:    args.key,
+			CAFile:     args.caCert,
+			MinVersion: args.tlsMinVersion,
+			ServerName: args.serverName,
+			KeyLogFile: args.tlsKeyLog,
+		},
+		Dial: ws.DialOptions{
+			UnixSocket: args.unixSocket,
+			Resolve:    args.resolve,
+			Interface:  args.iface,
+		},
+		Faults: ws.FaultOptions{
+			SendLatency:       args.faultLatency,
+			SendJitter:        args.faultJitter,
+			ReadStall:         args.faultReadStall,
+			KillAfter:         args.faultKillAfter,
+			DuplicateRate:     args.faultDuplicate,
+			DropRate:          args.faultDrop,
+			KillAfterMessages: args.faultKillAfterMsgs,
+		},
+	}
+
+	switch {
+	case args.ipv4:
+		wsOpts.Dial.IPVersion = ws.IPv4
+	case args.ipv6:
+		wsOpts.Dial.IPVersion = ws.IPv6
+	}
+
+	if args.verbose {
+		wsOpts.Output = os.Stdout
+
+		// The standard output of pipe mode and of machine-readable output formats contains only messages.
+		if args.pipe || headerless(args.format) {
+			wsOpts.Output = os.Stderr
+		}
+	}
+
+	if args.reconnect {
+		wsOpts.Reconnect = createReconnectPolicy(args)
+	}
+
+	return wsOpts, nil
+}
+
+// headerless reports whether the output format with the given name is printed without headers.
+// It returns false if the format is not supported.
+func headerless(format string) bool {
+	f, err := formater.New(format)
+	if err != nil {
+		return false
+	}
+
+	h, ok := f.(core.HeaderedFormater)
+
+	return ok && !h.Headers()
+}
+
+// closeExitCode maps the final close status of the connection to the process exit status.
+// It takes status of type ws.CloseStatus.
+// It returns 0 for a normal closure, a close initiated by the user or if no close frame was exchanged,
+// and for close frames sent by the server 100 + (code - 1000) for close codes defined by RFC 6455
+// (e.g. 108 for 1008 policy violation), and 100 for any other close code.
+func closeExitCode(status ws.CloseStatus) int {
+	switch {
+	case !status.Remote || status.Code == 0 || status.Code == closeNormalClosure:
+		return 0
+	case status.Code > closeNormalClosure && status.Code <= closeLastProtocolCode:
+		return exitCodeCloseBase + status.Code - closeNormalClosure
+	default:
+		return exitCodeCloseBase
+	}
+}
+
 // validateArgs checks the validity of the provided WebSocket URL and flags.
 // It takes wsURL of type string and args of type *flags.
 // It returns an error if the wsURL is empty or if the single response timeout is set without a request.
@@ -191,16 +435,196 @@ func validateArgs(wsURL string, args *flags) error {
 		return fmt.Errorf("single response timeout could be used only with request")
 	}
 
+	if !args.reconnect && (args.replay || len(args.onReconnect) > 0) {
+		return fmt.Errorf("replay and on-reconnect commands could be used only with reconnect")
+	}
+
+	if args.ipv4 && args.ipv6 {
+		return fmt.Errorf("ipv4 and ipv6 options are mutually exclusive")
+	}
+
+	if len(args.namespaces) > 0 && args.protocol != protocol.NameSocketIO {
+		return fmt.Errorf("namespaces could be used only with socketio protocol")
+	}
+
+	if args.initPayload != "" && args.protocol != protocol.NameGraphQL {
+		return fmt.Errorf("init payload could be used only with graphql protocol")
+	}
+
+	if len(args.stompHeaders) > 0 && args.protocol != protocol.NameSTOMP {
+		return fmt.Errorf("stomp headers could be used only with stomp protocol")
+	}
+
+	if args.heartbeat != 0 && !slices.Contains([]string{protocol.NameSTOMP, protocol.NamePhoenix, protocol.NameMQTT}, args.protocol) {
+		return fmt.Errorf("heartbeat could be used only with stomp, phoenix or mqtt protocol")
+	}
+
+	if args.mqttVersion != "" && args.protocol != protocol.NameMQTT {
+		return fmt.Errorf("mqtt version could be used only with mqtt protocol")
+	}
+
+	if _, err := formater.New(args.format); err != nil {
+		return err
+	}
+
+	if err := validatePipe(args); err != nil {
+		return err
+	}
+
+	return validateTransport(wsURL, args)
+}
+
+// validatePipe checks that the pipe mode options are used only in pipe mode and are not combined with interactive options.
+// It takes args of type *flags.
+// It returns an error if the options conflict.
+func validatePipe(args *flags) error {
+	if !args.pipe {
+		if (args.idleTimeout != 0 && args.idleTimeout != defaultIdleTimeout) || (args.delimiter != "" && args.delimiter != core.DelimiterLine) {
+			return fmt.Errorf("delimiter and idle timeout could be used only in pipe mode")
+		}
+
+		return nil
+	}
+
+	if args.waitResponse >= 0 {
+		return fmt.Errorf("single response timeout could not be used in pipe mode, use idle timeout instead")
+	}
+
+	if args.inputFile != "" {
+		return fmt.Errorf("input file could not be used in pipe mode")
+	}
+
+	return nil
+}
+
+// validateTransport checks that the options specific to WebSocket are not used with the other transports.
+// It takes rawURL of type string and args of type *flags.
+// It returns an error if a WebSocket-only option is set for an sse+http, sse+https, tcp or tls URL.
+func validateTransport(rawURL string, args *flags) error {
+	if ws.IsWebSocket(rawURL) {
+		return nil
+	}
+
+	switch {
+	case args.protocol != "" && args.protocol != protocol.NameRaw:
+		return fmt.Errorf("%s protocol could be used only with WebSocket", args.protocol)
+	case len(args.subprotocols) > 0:
+		return fmt.Errorf("subprotocols could be used only with WebSocket")
+	case args.compression != "" && args.compression != ws.CompressionDisabled:
+		return fmt.Errorf("compression could be used only with WebSocket")
+	case args.keepalive > 0:
+		return fmt.Errorf("keepalive could be used only with WebSocket")
+	case args.reconnect:
+		return fmt.Errorf("reconnect could be used only with WebSocket")
+	case args.oversize != "" && args.oversize != ws.OversizeClose:
+		return fmt.Errorf("oversize policy could be used only with WebSocket")
+	}
+
+	return nil
+}
+
+// applyMacroSubprotocol offers the default subprotocol of the macro files during the handshake.
+// It takes wsOpts of type *ws.Options and subprotocol, the subprotocol declared in the macro files.
+// Subprotocols set with flags take precedence, in this case the options are not changed.
+// It returns an error if the application protocol requires other subprotocols.
+func applyMacroSubprotocol(wsOpts *ws.Options, subprotocol string) error {
+	if subprotocol == "" || len(wsOpts.Subprotocols) > 0 {
+		return nil
+	}
+
+	if wsOpts.Protocol != nil {
+		if required := wsOpts.Protocol.Subprotocols(); len(required) > 0 && !slices.Contains(required, subprotocol) {
+			return fmt.Errorf("subprotocol %q of the macro files conflicts with the protocol subprotocols: %s",
+				subprotocol, strings.Join(required, ", "))
+		}
+	}
+
+	wsOpts.Subprotocols = []string{subprotocol}
+
+	return nil
+}
+
+// urlHostname returns the hostname of the server address.
+// It returns an error if the address is not a valid URL.
+func urlHostname(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+
+	return u.Hostname(), nil
+}
+
+// applyHostConfig fills TLS settings that are not set by flags from the hosts file in the configuration directory.
+// It takes wsURL of type string and args of type *flags, which is updated in place.
+// It returns an error if the URL cannot be parsed or the hosts file cannot be loaded.
+func applyHostConfig(wsURL string, args *flags) error {
+	hostname, err := urlHostname(wsURL)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := hosts.LoadFromFile(filepath.Join(args.configDir, hostsFilename))
+	if err != nil {
+		return err
+	}
+
+	host, ok := cfg.Lookup(hostname)
+	if !ok {
+		return nil
+	}
+
+	args.cert = cmp.Or(args.cert, host.Cert)
+	args.key = cmp.Or(args.key, host.Key)
+	args.caCert = cmp.Or(args.caCert, host.CACert)
+	args.tlsMinVersion = cmp.Or(args.tlsMinVersion, host.TLSMinVersion)
+	args.serverName = cmp.Or(args.serverName, host.ServerName)
+
+	return nil
+}
+
+// openTimingOutput opens the destination for the handshake timing records.
+// It takes path of type string, where "-" means the standard error output.
+// It returns an io.WriteCloser and an error if the file cannot be opened.
+func openTimingOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopCloser{os.Stderr}, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, timingFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open timing file: %w", err)
+	}
+
+	return file, nil
+}
+
+// nopCloser wraps io.Writer with a no-op Close method, so that standard streams are not closed.
+type nopCloser struct {
+	io.Writer
+}
+
+// Close does nothing and returns nil.
+func (nopCloser) Close() error {
 	return nil
 }
 
 // initRunOptions initializes and returns a RunOptions struct based on the provided flags.
-// It takes a single parameter args of type *flags which contains the command-line arguments.
+// It takes args of type *flags which contains the command-line arguments and factory of type core.CommandFactory.
 // It returns a pointer to cli.RunOptions and an error.
-// It returns an error if it fails to open the specified output file.
-func initRunOptions(args *flags) (opts *core.RunOptions, err error) {
+// It returns an error if it fails to open the specified output file or to create an on-reconnect command.
+func initRunOptions(args *flags, factory core.CommandFactory) (opts *core.RunOptions, err error) {
 	opts = &core.RunOptions{}
 
+	for _, rawCmd := range args.onReconnect {
+		cmd, err := factory.Create(rawCmd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid on-reconnect command %q: %w", rawCmd, err)
+		}
+
+		opts.OnReconnect = append(opts.OnReconnect, cmd)
+	}
+
 	if args.outputFile != "" {
 		if opts.OutputFile, err = os.Create(args.outputFile); err != nil {
 			return nil, fmt.Errorf("fail to open output file: %w", err)
@@ -212,6 +636,38 @@ func initRunOptions(args *flags) (opts *core.RunOptions, err error) {
 	return opts, nil
 }
 
+// createReconnectPolicy builds the reconnect policy for the WebSocket connection from the provided flags.
+// It takes a single parameter args of type *flags.
+// It returns a pointer to ws.ReconnectPolicy configured with the attempt limit, backoff delays and replay mode.
+func createReconnectPolicy(args *flags) *ws.ReconnectPolicy {
+	policy := ws.NewReconnectPolicy(args.reconnectAttempts)
+	policy.Replay = args.replay
+
+	if args.reconnectDelay > 0 {
+		policy.InitialDelay = args.reconnectDelay
+	}
+
+	if args.reconnectMaxDelay > 0 {
+		policy.MaxDelay = args.reconnectMaxDelay
+	}
+
+	return policy
+}
+
+// newConnectionStatus converts a WebSocket connection state into a status reported to the CLI.
+// It takes state of type ws.State and attempt of type int, the current reconnect attempt.
+// It returns a core.ConnectionStatus, marked as reconnected when the connection is restored after a drop.
+func newConnectionStatus(state ws.State, attempt int) core.ConnectionStatus {
+	if state == ws.StateReconnecting {
+		return core.ConnectionStatus{State: fmt.Sprintf("%s, attempt %d", state, attempt)}
+	}
+
+	return core.ConnectionStatus{
+		State:       state.String(),
+		Reconnected: state == ws.StateConnected && attempt > 0,
+	}
+}
+
 // createCommands generates a slice of core.Executer based on the provided flags.
 // It takes a single parameter args of type *flags, which contains the command-line arguments.
 // It returns a slice of core.Executer, which represents the sequence of commands to be executed.
@@ -2,17 +2,25 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/coder/websocket"
 	"github.com/ksysoev/wsget/pkg/core"
 	"github.com/ksysoev/wsget/pkg/core/command"
+	"github.com/ksysoev/wsget/pkg/input"
+	"github.com/ksysoev/wsget/pkg/protocol"
+	"github.com/ksysoev/wsget/pkg/ws"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func createEchoWSHandler() http.HandlerFunc {
@@ -180,16 +188,40 @@ func TestInitRunOptions(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "On reconnect commands",
+			args: &flags{
+				onReconnect: []string{"send test request"},
+			},
+			expected: &core.RunOptions{
+				Commands: []core.Executer{
+					command.NewEdit(""),
+				},
+				OnReconnect: []core.Executer{
+					command.NewSend("test request"),
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid on reconnect command",
+			args: &flags{
+				onReconnect: []string{"send"},
+			},
+			expected:    nil,
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			opts, err := initRunOptions(tt.args)
+			opts, err := initRunOptions(tt.args, command.NewFactory(nil))
 			if tt.expectError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expected.Commands, opts.Commands)
+				assert.Equal(t, tt.expected.OnReconnect, opts.OnReconnect)
 
 				if tt.expected.OutputFile != nil {
 					assert.NotNil(t, opts.OutputFile)
@@ -221,6 +253,161 @@ func TestValidateArgs(t *testing.T) {
 			},
 			expectedErr: "single response timeout could be used only with request",
 		},
+		{
+			name:  "Replay without reconnect",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				replay:       true,
+			},
+			expectedErr: "replay and on-reconnect commands could be used only with reconnect",
+		},
+		{
+			name:  "IPv4 and IPv6",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				ipv4:         true,
+				ipv6:         true,
+			},
+			expectedErr: "ipv4 and ipv6 options are mutually exclusive",
+		},
+		{
+			name:  "Namespace Without Socket.IO",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				namespaces:   []string{"/admin"},
+			},
+			expectedErr: "namespaces could be used only with socketio protocol",
+		},
+		{
+			name:  "Init Payload Without GraphQL",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				protocol:     "socketio",
+				initPayload:  `{"token":"abc"}`,
+			},
+			expectedErr: "init payload could be used only with graphql protocol",
+		},
+		{
+			name:  "STOMP Headers Without STOMP",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				stompHeaders: []string{"login:guest"},
+			},
+			expectedErr: "stomp headers could be used only with stomp protocol",
+		},
+		{
+			name:  "Heartbeat Without Heartbeat Protocol",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				protocol:     "jsonrpc",
+				heartbeat:    time.Second,
+			},
+			expectedErr: "heartbeat could be used only with stomp, phoenix or mqtt protocol",
+		},
+		{
+			name:  "MQTT Version Without MQTT",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				protocol:     "stomp",
+				mqttVersion:  "5",
+			},
+			expectedErr: "mqtt version could be used only with mqtt protocol",
+		},
+		{
+			name:  "Protocol Over SSE",
+			wsURL: "sse+https://example.com/events",
+			args: &flags{
+				waitResponse: -1,
+				protocol:     "graphql",
+			},
+			expectedErr: "graphql protocol could be used only with WebSocket",
+		},
+		{
+			name:  "Reconnect Over TCP",
+			wsURL: "tcp://example.com:7000",
+			args: &flags{
+				waitResponse: -1,
+				protocol:     "raw",
+				reconnect:    true,
+			},
+			expectedErr: "reconnect could be used only with WebSocket",
+		},
+		{
+			name:  "Keepalive Over TLS",
+			wsURL: "tls://example.com:7000",
+			args: &flags{
+				waitResponse: -1,
+				keepalive:    time.Second,
+			},
+			expectedErr: "keepalive could be used only with WebSocket",
+		},
+		{
+			name:  "Idle Timeout Without Pipe",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				idleTimeout:  time.Second,
+			},
+			expectedErr: "delimiter and idle timeout could be used only in pipe mode",
+		},
+		{
+			name:  "Input File In Pipe Mode",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				pipe:         true,
+				inputFile:    "requests.yaml",
+			},
+			expectedErr: "input file could not be used in pipe mode",
+		},
+		{
+			name:  "Wait Response In Pipe Mode",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: 1,
+				request:      "test request",
+				pipe:         true,
+			},
+			expectedErr: "single response timeout could not be used in pipe mode, use idle timeout instead",
+		},
+		{
+			name:  "Valid Pipe Arguments",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				pipe:         true,
+				delimiter:    "ndjson",
+				idleTimeout:  time.Second,
+			},
+			expectedErr: "",
+		},
+		{
+			name:  "Valid TCP Arguments",
+			wsURL: "tcp://example.com:7000",
+			args: &flags{
+				waitResponse: -1,
+				protocol:     "raw",
+				compression:  "disabled",
+				oversize:     "close",
+			},
+			expectedErr: "",
+		},
+		{
+			name:  "Unsupported output format",
+			wsURL: "ws://example.com",
+			args: &flags{
+				waitResponse: -1,
+				format:       "xml",
+			},
+			expectedErr: "unsupported output format: xml, supported formats: pretty, raw, ndjson, yaml, hexdump",
+		},
 		{
 			name:  "Valid Arguments",
 			wsURL: "ws://example.com",
@@ -253,6 +440,65 @@ func TestValidateArgs(t *testing.T) {
 	}
 }
 
+func TestCreateReconnectPolicy(t *testing.T) {
+	policy := createReconnectPolicy(&flags{
+		reconnectAttempts: 3,
+		reconnectDelay:    100 * time.Millisecond,
+		reconnectMaxDelay: time.Second,
+		replay:            true,
+	})
+
+	assert.Equal(t, 3, policy.MaxAttempts)
+	assert.Equal(t, 100*time.Millisecond, policy.InitialDelay)
+	assert.Equal(t, time.Second, policy.MaxDelay)
+	assert.True(t, policy.Replay)
+
+	policy = createReconnectPolicy(&flags{})
+
+	assert.Equal(t, ws.DefaultReconnectDelay, policy.InitialDelay)
+	assert.Equal(t, ws.DefaultReconnectMaxDelay, policy.MaxDelay)
+	assert.False(t, policy.Replay)
+}
+
+func TestNewConnectionStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected core.ConnectionStatus
+		state    ws.State
+		attempt  int
+	}{
+		{
+			name:     "initial connection",
+			state:    ws.StateConnected,
+			attempt:  0,
+			expected: core.ConnectionStatus{State: "connected"},
+		},
+		{
+			name:     "reconnected",
+			state:    ws.StateConnected,
+			attempt:  2,
+			expected: core.ConnectionStatus{State: "connected", Reconnected: true},
+		},
+		{
+			name:     "reconnecting",
+			state:    ws.StateReconnecting,
+			attempt:  2,
+			expected: core.ConnectionStatus{State: "reconnecting, attempt 2"},
+		},
+		{
+			name:     "disconnected",
+			state:    ws.StateDisconnected,
+			expected: core.ConnectionStatus{State: "disconnected"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newConnectionStatus(tt.state, tt.attempt))
+		})
+	}
+}
+
 func TestCreateConnectRunner(t *testing.T) {
 	args := &flags{
 		request:      "test",
@@ -296,16 +542,317 @@ func TestRunConnectCmd_SuccessConnect(t *testing.T) {
 
 	ctx := context.Background()
 	args := &flags{
+		configDir:    t.TempDir(),
 		request:      "test request",
 		waitResponse: 1,
 	}
 
-	// tty is not available in the test environment
-	// so the test will fail in some cases and be successful in others
+	stubKeyboard(t)
+
 	err := runConnectCmd(ctx, args, []string{url})
-	if err != nil {
-		assert.ErrorContains(t, err, "open /dev/tty: ")
-	} else {
-		assert.NoError(t, err)
+	assert.NoError(t, err)
+}
+
+func TestApplyHostConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `version: "1"
+hosts:
+  internal.example.com:
+    cert: /certs/client.crt
+    cacert: /certs/ca.pem
+    tls-min-version: "1.3"
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, hostsFilename), []byte(content), 0o600))
+
+	args := &flags{configDir: dir, tlsMinVersion: "1.2"}
+
+	assert.NoError(t, applyHostConfig("wss://gw.internal.example.com/ws", args))
+	assert.Equal(t, "/certs/client.crt", args.cert)
+	assert.Equal(t, "/certs/ca.pem", args.caCert)
+	assert.Equal(t, "1.2", args.tlsMinVersion)
+	assert.Empty(t, args.key)
+
+	args = &flags{configDir: dir}
+
+	assert.NoError(t, applyHostConfig("wss://example.org/ws", args))
+	assert.Empty(t, args.cert)
+
+	assert.NoError(t, applyHostConfig("wss://example.org/ws", &flags{configDir: t.TempDir()}))
+	assert.ErrorContains(t, applyHostConfig("://invalid", &flags{configDir: dir}), "invalid url")
+}
+
+func TestApplyMacroSubprotocol(t *testing.T) {
+	graphql, err := protocol.New(protocol.NameGraphQL, protocol.Options{})
+	require.NoError(t, err)
+
+	jsonrpc, err := protocol.New(protocol.NameJSONRPC, protocol.Options{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		opts        *ws.Options
+		name        string
+		subprotocol string
+		expectedErr string
+		expected    []string
+	}{
+		{name: "no macro subprotocol", opts: &ws.Options{}},
+		{name: "macro subprotocol", opts: &ws.Options{}, subprotocol: "v2.json", expected: []string{"v2.json"}},
+		{
+			name:        "flags take precedence",
+			opts:        &ws.Options{Subprotocols: []string{"v1.json"}},
+			subprotocol: "v2.json",
+			expected:    []string{"v1.json"},
+		},
+		{
+			name:        "protocol without subprotocols",
+			opts:        &ws.Options{Protocol: jsonrpc},
+			subprotocol: "v2.json",
+			expected:    []string{"v2.json"},
+		},
+		{
+			name:        "protocol requires the same subprotocol",
+			opts:        &ws.Options{Protocol: graphql},
+			subprotocol: "graphql-transport-ws",
+			expected:    []string{"graphql-transport-ws"},
+		},
+		{
+			name:        "protocol requires another subprotocol",
+			opts:        &ws.Options{Protocol: graphql},
+			subprotocol: "v2.json",
+			expectedErr: `subprotocol "v2.json" of the macro files conflicts with the protocol subprotocols: graphql-transport-ws`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyMacroSubprotocol(tt.opts, tt.subprotocol)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tt.opts.Subprotocols)
+		})
+	}
+}
+
+func TestHeaderless(t *testing.T) {
+	assert.False(t, headerless(""))
+	assert.False(t, headerless("pretty"))
+	assert.False(t, headerless("hexdump"))
+	assert.True(t, headerless("raw"))
+	assert.True(t, headerless("ndjson"))
+	assert.True(t, headerless("yaml"))
+	assert.False(t, headerless("xml"))
+}
+
+func TestOpenTimingOutput(t *testing.T) {
+	out, err := openTimingOutput("-")
+	assert.NoError(t, err)
+	assert.Equal(t, nopCloser{os.Stderr}, out)
+	assert.NoError(t, out.Close())
+
+	path := filepath.Join(t.TempDir(), "timing.jsonl")
+
+	out, err = openTimingOutput(path)
+	assert.NoError(t, err)
+
+	_, err = io.WriteString(out, "{}\n")
+	assert.NoError(t, err)
+	assert.NoError(t, out.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}\n", string(data))
+
+	_, err = openTimingOutput(filepath.Join(t.TempDir(), "missing", "timing.jsonl"))
+	assert.ErrorContains(t, err, "failed to open timing file")
+}
+
+func TestCloseExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   ws.CloseStatus
+		expected int
+	}{
+		{name: "no close frame", status: ws.CloseStatus{}, expected: 0},
+		{name: "normal closure", status: ws.CloseStatus{Code: 1000, Remote: true}, expected: 0},
+		{name: "going away", status: ws.CloseStatus{Code: 1001, Remote: true}, expected: 101},
+		{name: "policy violation", status: ws.CloseStatus{Code: 1008, Remote: true}, expected: 108},
+		{name: "internal error", status: ws.CloseStatus{Code: 1011, Remote: true}, expected: 111},
+		{name: "application code", status: ws.CloseStatus{Code: 4001, Remote: true}, expected: 100},
+		{name: "local going away", status: ws.CloseStatus{Code: 1001}, expected: 0},
+		{name: "local application code", status: ws.CloseStatus{Code: 4001}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, closeExitCode(tt.status))
+		})
+	}
+}
+
+func TestRunConnectCmd_ServerClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		// The connection is closed while the client waits for the response.
+		_, _, _ = c.Read(r.Context())
+		_ = c.Close(websocket.StatusPolicyViolation, "token expired")
+	}))
+	defer server.Close()
+
+	args := &flags{
+		configDir:    t.TempDir(),
+		request:      "test request",
+		waitResponse: 0,
+	}
+
+	stubKeyboard(t)
+
+	err := runConnectCmd(context.Background(), args, []string{"ws://" + server.Listener.Addr().String()})
+
+	var exitErr *ExitError
+
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 108, exitErr.Code)
+	assert.EqualError(t, err, "exit status 108")
+}
+
+// stubKeyboard replaces the keyboard of interactive sessions with a key input that produces no events,
+// so that the sessions run without a terminal. The keyboard is restored when the test ends.
+func stubKeyboard(t *testing.T) {
+	t.Helper()
+
+	orig := newKeyInput
+	newKeyInput = func(input.KeyHandler) keyInput { return noKeys{} }
+
+	t.Cleanup(func() { newKeyInput = orig })
+}
+
+// noKeys is a key input without events.
+type noKeys struct{}
+
+func (noKeys) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (noKeys) Close() {}
+
+func TestRunConnectCmd_ReconnectDuringCommand(t *testing.T) {
+	var connections atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		if connections.Add(1) == 1 {
+			// The connection is dropped while the client waits for the response.
+			_, _, _ = c.Read(r.Context())
+			_ = c.CloseNow()
+
+			return
+		}
+
+		_ = c.Write(r.Context(), websocket.MessageText, []byte("welcome back"))
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer server.Close()
+
+	output := redirectStdio(t, "")
+
+	stubKeyboard(t)
+
+	args := &flags{
+		configDir:      t.TempDir(),
+		request:        "first",
+		waitResponse:   2,
+		reconnect:      true,
+		reconnectDelay: 10 * time.Millisecond,
 	}
+
+	err := runConnectCmd(context.Background(), args, []string{"ws://" + server.Listener.Addr().String()})
+	require.NoError(t, err)
+
+	assert.Contains(t, output(), "[disconnected]")
+	assert.Contains(t, output(), "[connected]")
+	assert.Contains(t, output(), "welcome back")
+	assert.Equal(t, int32(2), connections.Load())
+}
+
+func TestRunConnectCmd_LocalClose(t *testing.T) {
+	server := httptest.NewServer(createEchoWSHandler())
+	defer server.Close()
+
+	inputFile := filepath.Join(t.TempDir(), "input.yaml")
+	require.NoError(t, os.WriteFile(inputFile, []byte("- close 1001 going away\n"), 0o600))
+
+	redirectStdio(t, "")
+	stubKeyboard(t)
+
+	args := &flags{
+		configDir:    t.TempDir(),
+		inputFile:    inputFile,
+		waitResponse: -1,
+	}
+
+	err := runConnectCmd(context.Background(), args, []string{"ws://" + server.Listener.Addr().String()})
+	assert.NoError(t, err)
+}
+
+func TestRunConnectCmd_StatsInNDJSONFormat(t *testing.T) {
+	server := httptest.NewServer(createEchoWSHandler())
+	defer server.Close()
+
+	inputFile := filepath.Join(t.TempDir(), "input.yaml")
+	input := "- 'send {\"a\": 1}'\n- wait 2\n- stats\n- conns\n- exit\n"
+	require.NoError(t, os.WriteFile(inputFile, []byte(input), 0o600))
+
+	output := redirectStdio(t, "")
+	stubKeyboard(t)
+
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	require.NoError(t, err)
+
+	origErr := os.Stderr
+	os.Stderr = stderr
+
+	t.Cleanup(func() {
+		os.Stderr = origErr
+		_ = stderr.Close()
+	})
+
+	args := &flags{
+		configDir:    t.TempDir(),
+		inputFile:    inputFile,
+		waitResponse: -1,
+		format:       "ndjson",
+	}
+
+	err = runConnectCmd(context.Background(), args, []string{"ws://" + server.Listener.Addr().String()})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(output(), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	for _, line := range lines {
+		var msg map[string]any
+
+		require.NoError(t, json.Unmarshal([]byte(line), &msg), "stdout line is not an envelope: %q", line)
+		assert.Equal(t, `{"a": 1}`, msg["data"])
+	}
+
+	info, err := os.ReadFile(stderr.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(info), core.WelcomMessage)
+	assert.Contains(t, string(info), "* default\tws://")
+	assert.Equal(t, 2, strings.Count(string(info), "sent 1 messages"), "stats and the session summary are written to stderr")
 }
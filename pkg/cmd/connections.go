@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/ws"
+)
+
+// connectionFactory opens named connections requested by the connect command during the session.
+// Every connection uses the flags of the session with the host configuration of its own URL applied.
+type connectionFactory struct {
+	timing io.Writer
+	client *core.CLI
+	args   flags
+}
+
+// Create establishes a new named connection to the provided url over the transport selected by its scheme.
+// It takes ctx of type context.Context, which bounds the lifetime of the connection, name and url of type string,
+// and onMessage, the callback for messages received over the connection.
+// It returns the established connection and an error if the url is invalid or the connection fails.
+// Failures after the connection is established are reported to the CLI as connection status changes.
+func (f *connectionFactory) Create(
+	ctx context.Context,
+	name, url string,
+	onMessage func(context.Context, []byte, bool),
+) (core.ConnectionHandler, error) {
+	args := f.args
+
+	if err := validateTransport(url, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if err := applyHostConfig(url, &args); err != nil {
+		return nil, fmt.Errorf("failed to apply host configuration: %w", err)
+	}
+
+	wsOpts, err := newWSOptions(&args)
+	if err != nil {
+		return nil, err
+	}
+
+	wsOpts.Timing = f.timing
+
+	conn, err := ws.NewTransport(url, wsOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the server: %w", err)
+	}
+
+	conn.SetOnMessage(onMessage)
+
+	if wsConn, ok := conn.(*ws.Connection); ok {
+		wsConn.SetOnClose(func(ctx context.Context, status ws.CloseStatus) {
+			f.notify(ctx, name+": closed by server: "+status.String())
+		})
+
+		if args.reconnect {
+			wsConn.SetOnStateChange(func(ctx context.Context, state ws.State, attempt int) {
+				// On-reconnect commands are sent to the active connection, so they are executed only for the default one.
+				f.notify(ctx, name+": "+newConnectionStatus(state, attempt).State)
+			})
+		}
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		err := conn.Connect(ctx)
+		done <- err
+
+		select {
+		case <-conn.Ready():
+		default:
+			// The failure of the initial connection is returned by Create.
+			return
+		}
+
+		if err != nil && !errors.Is(err, ws.ErrConnectionClosed) && !errors.Is(err, context.Canceled) {
+			f.notify(ctx, fmt.Sprintf("%s: connection failed: %s", name, err))
+		}
+	}()
+
+	select {
+	case <-conn.Ready():
+		return conn, nil
+	case err := <-done:
+		if err == nil {
+			err = ws.ErrConnectionClosed
+		}
+
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// notify reports the connection state change to the CLI.
+func (f *connectionFactory) notify(ctx context.Context, state string) {
+	if f.client != nil {
+		f.client.OnConnectionStatus(ctx, core.ConnectionStatus{State: state})
+	}
+}
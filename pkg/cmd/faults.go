@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// faultProfile represents the YAML file with faults to inject into the connection.
+type faultProfile struct {
+	Version           string        `yaml:"version"`
+	SendLatency       time.Duration `yaml:"send_latency"`
+	SendJitter        time.Duration `yaml:"send_jitter"`
+	ReadStall         time.Duration `yaml:"read_stall"`
+	KillAfter         time.Duration `yaml:"kill_after"`
+	DuplicateRate     float64       `yaml:"duplicate_rate"`
+	DropRate          float64       `yaml:"drop_rate"`
+	KillAfterMessages int           `yaml:"kill_after_messages"`
+}
+
+// applyFaultProfile fills fault injection settings that are not set by flags from the fault profile file.
+// It takes args of type *flags, which is updated in place.
+// It returns an error if the profile cannot be read, parsed or has an unsupported version.
+func applyFaultProfile(args *flags) error {
+	if args.faultProfile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(args.faultProfile)
+	if err != nil {
+		return fmt.Errorf("failed to read fault profile: %w", err)
+	}
+
+	var profile faultProfile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return fmt.Errorf("failed to parse fault profile: %w", err)
+	}
+
+	if profile.Version != "1" {
+		return fmt.Errorf("unsupported fault profile version: %s", profile.Version)
+	}
+
+	args.faultLatency = cmp.Or(args.faultLatency, profile.SendLatency)
+	args.faultJitter = cmp.Or(args.faultJitter, profile.SendJitter)
+	args.faultReadStall = cmp.Or(args.faultReadStall, profile.ReadStall)
+	args.faultKillAfter = cmp.Or(args.faultKillAfter, profile.KillAfter)
+	args.faultDuplicate = cmp.Or(args.faultDuplicate, profile.DuplicateRate)
+	args.faultDrop = cmp.Or(args.faultDrop, profile.DropRate)
+	args.faultKillAfterMsgs = cmp.Or(args.faultKillAfterMsgs, profile.KillAfterMessages)
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyFaultProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "faults.yaml")
+
+	content := `version: "1"
+send_latency: 200ms
+read_stall: 1s
+drop_rate: 0.1
+kill_after_messages: 50
+`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	args := &flags{faultProfile: path, faultLatency: time.Second}
+
+	assert.NoError(t, applyFaultProfile(args))
+	assert.Equal(t, time.Second, args.faultLatency)
+	assert.Equal(t, time.Second, args.faultReadStall)
+	assert.Equal(t, 0.1, args.faultDrop)
+	assert.Equal(t, 50, args.faultKillAfterMsgs)
+	assert.Zero(t, args.faultDuplicate)
+
+	assert.NoError(t, applyFaultProfile(&flags{}))
+	assert.ErrorContains(t, applyFaultProfile(&flags{faultProfile: filepath.Join(dir, "missing.yaml")}), "failed to read fault profile")
+
+	assert.NoError(t, os.WriteFile(path, []byte("version: \"2\"\n"), 0o600))
+	assert.ErrorContains(t, applyFaultProfile(&flags{faultProfile: path}), "unsupported fault profile version: 2")
+}
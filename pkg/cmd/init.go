@@ -3,7 +3,10 @@ package cmd
 import (
 	"cmp"
 	"os"
+	"time"
 
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/protocol"
 	"github.com/ksysoev/wsget/pkg/ws"
 	"github.com/spf13/cobra"
 )
@@ -31,17 +34,61 @@ In this request mode the tool will send the request to the server and print resp
 )
 
 type flags struct {
-	request      string
-	outputFile   string
-	inputFile    string
-	configDir    string
-	version      string
-	headers      []string
-	maxMsgSize   int64
-	waitResponse int
-	timeout      uint32
-	insecure     bool
-	verbose      bool
+	request              string
+	outputFile           string
+	inputFile            string
+	configDir            string
+	version              string
+	proxy                string
+	compression          string
+	oversize             string
+	cert                 string
+	key                  string
+	caCert               string
+	tlsMinVersion        string
+	serverName           string
+	tlsKeyLog            string
+	timing               string
+	unixSocket           string
+	iface                string
+	faultProfile         string
+	protocol             string
+	initPayload          string
+	mqttVersion          string
+	delimiter            string
+	format               string
+	headers              []string
+	subprotocols         []string
+	onReconnect          []string
+	namespaces           []string
+	stompHeaders         []string
+	resolve              []string
+	maxMsgSize           int64
+	reconnectDelay       time.Duration
+	reconnectMaxDelay    time.Duration
+	keepalive            time.Duration
+	heartbeat            time.Duration
+	idleTimeout          time.Duration
+	keepaliveTimeout     time.Duration
+	faultLatency         time.Duration
+	faultJitter          time.Duration
+	faultReadStall       time.Duration
+	faultKillAfter       time.Duration
+	faultDuplicate       float64
+	faultDrop            float64
+	waitResponse         int
+	reconnectAttempts    int
+	keepaliveFailures    int
+	compressionThreshold int
+	faultKillAfterMsgs   int
+	timeout              uint32
+	insecure             bool
+	verbose              bool
+	reconnect            bool
+	replay               bool
+	ipv4                 bool
+	ipv6                 bool
+	pipe                 bool
 }
 
 // InitCommands initializes and returns a new cobra.Command for the wsget tool.
@@ -64,23 +111,76 @@ func InitCommands(version string) *cobra.Command {
 
 	cmd.PersistentFlags().StringVarP(&args.configDir, "config-dir", "c", "", "Configuration directory for storing history and macros")
 
-	cmd.Flags().BoolVarP(&args.insecure, "insecure", "k", false, "Skip SSL certificate verification")
+	addConnectionFlags(cmd, args)
+
 	cmd.Flags().StringVarP(&args.request, "request", "r", "", "WebSocket request that will be sent to the server")
 	cmd.Flags().StringVarP(&args.outputFile, "output", "o", "", "Output file for saving all request and responses")
 	cmd.Flags().IntVarP(&args.waitResponse, "wait-resp", "w", -1, "Timeout for single response in seconds, 0 means no timeout. If this option is set, the tool will exit after receiving the first response")
-	cmd.Flags().StringSliceVarP(&args.headers, "header", "H", []string{}, "HTTP headers to attach to the request")
 	cmd.Flags().StringVarP(&args.inputFile, "input", "i", "", "Input YAML file with list of requests to send to the server")
-	cmd.Flags().BoolVarP(&args.verbose, "verbose", "v", false, "Verbose output")
-	cmd.Flags().Int64VarP(&args.maxMsgSize, "max-size", "s", ws.DefaultMaxMessageSize, "Maximum message size in bytes, non-positive value will be ignored and default value will be used")
-	cmd.Flags().Uint32VarP(&args.timeout, "timeout", "t", 30, "WebSocket handshake timeout in seconds, 0 means no timeout")
+	cmd.Flags().BoolVar(&args.pipe, "pipe", false, "Read requests from stdin and write received messages to stdout one per line, enabled by default when stdin is not a terminal")
+	cmd.Flags().StringVar(&args.delimiter, "delimiter", core.DelimiterLine, "Delimiter of requests read from stdin in pipe mode: line, nul or ndjson")
+	cmd.Flags().DurationVar(&args.idleTimeout, "idle-timeout", defaultIdleTimeout, "Time to wait for messages after stdin is closed in pipe mode, the tool exits when no message is received for this time, 0 exits right after the last request is sent")
+	cmd.Flags().StringVar(&args.format, "format", "", "Output format of messages: pretty, raw, ndjson, yaml or hexdump, pretty by default, in pipe mode messages are written one per line by default")
+	cmd.Flags().StringVar(&args.oversize, "oversize", ws.OversizeClose, "Policy for messages larger than max-size: close the connection, truncate them or stream them to a file in the config directory")
+	cmd.Flags().StringVar(&args.unixSocket, "unix-socket", "", "Connect through this Unix domain socket instead of TCP")
+	cmd.Flags().BoolVar(&args.reconnect, "reconnect", false, "Automatically reconnect when the connection is dropped")
+	cmd.Flags().IntVar(&args.reconnectAttempts, "reconnect-attempts", 0, "Maximum number of consecutive reconnect attempts, 0 means no limit")
+	cmd.Flags().DurationVar(&args.reconnectDelay, "reconnect-delay", ws.DefaultReconnectDelay, "Initial delay before reconnecting, doubled after every failed attempt")
+	cmd.Flags().DurationVar(&args.reconnectMaxDelay, "reconnect-max-delay", ws.DefaultReconnectMaxDelay, "Maximum delay between reconnect attempts")
+	cmd.Flags().BoolVar(&args.replay, "replay", false, "Re-send requests sent since the last connect after reconnection")
+	cmd.Flags().StringArrayVar(&args.onReconnect, "on-reconnect", []string{}, "Command to execute after reconnection, can be repeated")
+	cmd.Flags().StringVar(&args.protocol, "protocol", protocol.NameRaw, "Application protocol running over WebSocket: raw, socketio, graphql, jsonrpc, stomp, phoenix or mqtt")
+	cmd.Flags().StringArrayVar(&args.namespaces, "namespace", []string{}, "Socket.IO namespace to connect, can be repeated, the first one is the default for emit")
+	cmd.Flags().StringVar(&args.initPayload, "init-payload", "", "JSON payload of the GraphQL connection_init message, e.g. with an auth token")
+	cmd.Flags().StringArrayVar(&args.stompHeaders, "stomp-header", []string{}, "Header of the STOMP CONNECT frame in key:value format, e.g. login:guest, can be repeated")
+	cmd.Flags().StringVar(&args.mqttVersion, "mqtt-version", "", "MQTT protocol version: 3.1.1 or 5, defaults to 3.1.1")
+	cmd.Flags().DurationVar(&args.heartbeat, "heartbeat", 0, "Interval of application protocol heartbeats or MQTT keepalive, 0 means the protocol default, negative value disables them")
 
 	args.configDir = cmp.Or(args.configDir, os.Getenv("WSGET_CONFIG_DIR"))
 
 	cmd.AddCommand(initMacroDownloadCommand(args))
+	cmd.AddCommand(initServeCommand())
+	cmd.AddCommand(initProxyCommand(args))
 
 	return cmd
 }
 
+// addConnectionFlags registers the flags configuring the WebSocket connection to the server.
+// They are shared by the root command and the proxy command, which dials the upstream server with the same options.
+func addConnectionFlags(cmd *cobra.Command, args *flags) {
+	cmd.Flags().BoolVarP(&args.insecure, "insecure", "k", false, "Skip SSL certificate verification")
+	cmd.Flags().StringVar(&args.cert, "cert", "", "Client certificate file in PEM format for mutual TLS")
+	cmd.Flags().StringVar(&args.key, "key", "", "Client private key file in PEM format, defaults to the certificate file")
+	cmd.Flags().StringVar(&args.caCert, "cacert", "", "CA certificates file in PEM format to verify the server with, replaces system CAs")
+	cmd.Flags().StringVar(&args.tlsMinVersion, "tls-min-version", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	cmd.Flags().StringVar(&args.serverName, "server-name", "", "Server name for SNI and certificate verification, defaults to the URL host")
+	cmd.Flags().StringVar(&args.tlsKeyLog, "tls-keylog", "", "File to append TLS session secrets to in NSS key log format for decrypting captured traffic")
+	cmd.Flags().StringSliceVarP(&args.headers, "header", "H", []string{}, "HTTP headers to attach to the request")
+	cmd.Flags().BoolVarP(&args.verbose, "verbose", "v", false, "Verbose output")
+	cmd.Flags().Int64VarP(&args.maxMsgSize, "max-size", "s", ws.DefaultMaxMessageSize, "Maximum message size in bytes, non-positive value will be ignored and default value will be used")
+	cmd.Flags().Uint32VarP(&args.timeout, "timeout", "t", 30, "WebSocket handshake timeout in seconds, 0 means no timeout")
+	cmd.Flags().StringVar(&args.timing, "timing", "", "File to append handshake timing to as JSON lines, - means stderr")
+	cmd.Flags().StringArrayVar(&args.resolve, "resolve", []string{}, "Resolve host and port to the address, in host:port:addr format, can be repeated")
+	cmd.Flags().BoolVarP(&args.ipv4, "ipv4", "4", false, "Connect only over IPv4")
+	cmd.Flags().BoolVarP(&args.ipv6, "ipv6", "6", false, "Connect only over IPv6")
+	cmd.Flags().StringVar(&args.iface, "interface", "", "Local interface name or IP address to bind outgoing connections to")
+	cmd.Flags().StringVar(&args.proxy, "proxy", "", "Proxy URL (http://, https:// or socks5://, credentials as user:password@host), overrides HTTP_PROXY and HTTPS_PROXY")
+	cmd.Flags().StringArrayVar(&args.subprotocols, "subprotocol", []string{}, "WebSocket subprotocol to request from the server, can be repeated in order of preference")
+	cmd.Flags().StringVar(&args.compression, "compression", ws.CompressionDisabled, "Permessage-deflate compression mode: disabled, context-takeover or no-context-takeover")
+	cmd.Flags().IntVar(&args.compressionThreshold, "compression-threshold", 0, "Minimum message size in bytes to apply compression, 0 means library default")
+	cmd.Flags().DurationVar(&args.keepalive, "keepalive", 0, "Interval between keepalive pings, 0 disables keepalive")
+	cmd.Flags().DurationVar(&args.keepaliveTimeout, "keepalive-timeout", 0, "Timeout for a keepalive ping response, 0 means the keepalive interval")
+	cmd.Flags().IntVar(&args.keepaliveFailures, "keepalive-failures", ws.DefaultKeepaliveFailures, "Number of consecutive failed keepalive pings after which the connection is considered dead")
+	cmd.Flags().StringVar(&args.faultProfile, "fault-profile", "", "YAML file with faults to inject, fault flags take precedence over it")
+	cmd.Flags().DurationVar(&args.faultLatency, "fault-latency", 0, "Fault injection: delay every outgoing message")
+	cmd.Flags().DurationVar(&args.faultJitter, "fault-jitter", 0, "Fault injection: add a random delay of up to the value to every outgoing message")
+	cmd.Flags().DurationVar(&args.faultReadStall, "fault-read-stall", 0, "Fault injection: pause before reading every incoming message to build up server backpressure")
+	cmd.Flags().Float64Var(&args.faultDuplicate, "fault-duplicate", 0, "Fault injection: probability from 0 to 1 of sending an outgoing message twice")
+	cmd.Flags().Float64Var(&args.faultDrop, "fault-drop", 0, "Fault injection: probability from 0 to 1 of dropping an outgoing message")
+	cmd.Flags().IntVar(&args.faultKillAfterMsgs, "fault-kill-after-messages", 0, "Fault injection: drop the TCP connection without a close frame after the number of messages")
+	cmd.Flags().DurationVar(&args.faultKillAfter, "fault-kill-after", 0, "Fault injection: drop the TCP connection without a close frame after the duration")
+}
+
 // initMacroDownloadCommand initializes a Cobra command for downloading a macro file from a URL.
 // It takes args of type flags to configure the command's behavior.
 // It returns a pointer to a Cobra command configured with necessary flags and options.
@@ -45,4 +45,8 @@ func TestWsgetInitCommands(t *testing.T) {
 	verboseFlag := cmd.Flags().Lookup("verbose")
 	assert.NotNil(t, verboseFlag)
 	assert.Equal(t, "false", verboseFlag.DefValue)
+
+	compressionFlag := cmd.Flags().Lookup("compression")
+	assert.NotNil(t, compressionFlag)
+	assert.Equal(t, "disabled", compressionFlag.DefValue)
 }
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/core/formater"
+	"github.com/ksysoev/wsget/pkg/ws"
+	"github.com/mattn/go-isatty"
+	"golang.org/x/sync/errgroup"
+)
+
+// runPipe exchanges messages with the server in pipe mode: requests are read from the standard input
+// and received messages are written to the standard output one per line, or in the output format if it is set,
+// errors are written to the standard error.
+// It takes ctx of type context.Context, args of type *flags and conn, the connection that is not established yet.
+// It returns an error if the pipe cannot be started, and ExitError if the connection was closed with an error status.
+// The connection is closed when the input is processed.
+func runPipe(ctx context.Context, args *flags, conn ws.Transport) error {
+	opts := core.PipeOptions{
+		Delimiter:   args.delimiter,
+		IdleTimeout: args.idleTimeout,
+	}
+
+	if args.format != "" {
+		outputFormat, err := formater.New(args.format)
+		if err != nil {
+			return fmt.Errorf("failed to initialize output format: %w", err)
+		}
+
+		opts.Formater = outputFormat
+	}
+
+	if args.request != "" {
+		opts.Requests = []string{args.request}
+	}
+
+	if args.outputFile != "" {
+		file, err := os.Create(args.outputFile)
+		if err != nil {
+			return fmt.Errorf("fail to open output file: %w", err)
+		}
+
+		defer func() { _ = file.Close() }()
+
+		opts.OutputFile = file
+	}
+
+	pipe, err := core.NewPipe(conn, os.Stdin, os.Stdout, opts)
+	if err != nil {
+		return fmt.Errorf("failed to initialize pipe mode: %w", err)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		if err := conn.Connect(ctx); err != nil {
+			return fmt.Errorf("websocket connection failed: %w", err)
+		}
+
+		return nil
+	})
+
+	eg.Go(func() error {
+		if err := pipe.Run(ctx); err != nil {
+			return fmt.Errorf("pipe failed: %w", err)
+		}
+
+		select {
+		case <-conn.Ready():
+			_ = conn.Close()
+		case <-ctx.Done():
+		}
+
+		return nil
+	})
+
+	err = eg.Wait()
+	closeStatus := conn.CloseStatus()
+
+	printSessionError(os.Stderr, err, closeStatus)
+
+	if code := closeExitCode(closeStatus); code != 0 {
+		return &ExitError{Code: code}
+	}
+
+	return nil
+}
+
+// isTerminal reports whether the file is a terminal.
+func isTerminal(file *os.File) bool {
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redirectStdio replaces the standard input with a file containing input and the standard output with a file.
+// It returns the function reading the written output, the original streams are restored when the test ends.
+func redirectStdio(t *testing.T, input string) func() string {
+	t.Helper()
+
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "stdin")
+	outPath := filepath.Join(dir, "stdout")
+
+	require.NoError(t, os.WriteFile(inPath, []byte(input), 0o600))
+
+	stdin, err := os.Open(inPath)
+	require.NoError(t, err)
+
+	stdout, err := os.Create(outPath)
+	require.NoError(t, err)
+
+	origIn, origOut := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdin, stdout
+
+	t.Cleanup(func() {
+		os.Stdin, os.Stdout = origIn, origOut
+		_ = stdin.Close()
+		_ = stdout.Close()
+	})
+
+	return func() string {
+		data, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+
+		return string(data)
+	}
+}
+
+func TestRunConnectCmd_Pipe(t *testing.T) {
+	server := httptest.NewServer(createEchoWSHandler())
+	defer server.Close()
+
+	output := redirectStdio(t, "hello\n{\"a\": 1}\n")
+
+	args := &flags{
+		configDir:    t.TempDir(),
+		request:      "first",
+		waitResponse: -1,
+		pipe:         true,
+		idleTimeout:  200 * time.Millisecond,
+	}
+
+	err := runConnectCmd(context.Background(), args, []string{"ws://" + server.Listener.Addr().String()})
+	require.NoError(t, err)
+
+	assert.Equal(t, "first\nhello\n{\"a\":1}\n", output())
+}
+
+func TestRunConnectCmd_PipeFormat(t *testing.T) {
+	server := httptest.NewServer(createEchoWSHandler())
+	defer server.Close()
+
+	output := redirectStdio(t, "hello\n")
+
+	args := &flags{
+		configDir:    t.TempDir(),
+		waitResponse: -1,
+		pipe:         true,
+		idleTimeout:  200 * time.Millisecond,
+		format:       "ndjson",
+	}
+
+	err := runConnectCmd(context.Background(), args, []string{"ws://" + server.Listener.Addr().String()})
+	require.NoError(t, err)
+
+	var msg map[string]any
+
+	require.NoError(t, json.Unmarshal([]byte(output()), &msg))
+	assert.Equal(t, "in", msg["direction"])
+	assert.Equal(t, "text", msg["type"])
+	assert.Equal(t, "hello", msg["data"])
+	assert.InDelta(t, 5, msg["size"], 0)
+}
+
+func TestRunConnectCmd_PipeServerClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusPolicyViolation, "token expired")
+	}))
+	defer server.Close()
+
+	redirectStdio(t, "")
+
+	args := &flags{
+		configDir:    t.TempDir(),
+		waitResponse: -1,
+		pipe:         true,
+		idleTimeout:  time.Second,
+	}
+
+	err := runConnectCmd(context.Background(), args, []string{"ws://" + server.Listener.Addr().String()})
+
+	var exitErr *ExitError
+
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 108, exitErr.Code)
+}
+
+func TestInitCommands_PipeDefaults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		_, data, err := c.Read(r.Context())
+		if err != nil {
+			return
+		}
+
+		// The response arrives after stdin is closed.
+		time.Sleep(300 * time.Millisecond)
+
+		_ = c.Write(r.Context(), websocket.MessageText, data)
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer server.Close()
+
+	output := redirectStdio(t, "{\"ping\": 1}\n")
+
+	cmd := InitCommands("test")
+	cmd.SetArgs([]string{"ws://" + server.Listener.Addr().String(), "--config-dir", t.TempDir()})
+
+	require.NoError(t, cmd.ExecuteContext(context.Background()))
+
+	assert.Equal(t, "{\"ping\":1}\n", output())
+}
+
+func TestRunConnectCmd_PipeMacroSubprotocol(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"v2.json"}})
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		_ = c.Write(r.Context(), websocket.MessageText, []byte("subprotocol: "+c.Subprotocol()))
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer server.Close()
+
+	configDir := t.TempDir()
+	macroFile := "version: 1\ndomains:\n  - 127.0.0.1\nsubprotocol: v2.json\nmacro:\n  test:\n    - send hello\n"
+
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, macroDir), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, macroDir, "local.yaml"), []byte(macroFile), 0o600))
+
+	output := redirectStdio(t, "")
+
+	args := &flags{
+		configDir:    configDir,
+		waitResponse: -1,
+		pipe:         true,
+		idleTimeout:  200 * time.Millisecond,
+	}
+
+	err := runConnectCmd(context.Background(), args, []string{"ws://" + server.Listener.Addr().String()})
+	require.NoError(t, err)
+
+	assert.Equal(t, "subprotocol: v2.json\n", output())
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/ksysoev/wsget/pkg/core/formater"
+	"github.com/ksysoev/wsget/pkg/server"
+	"github.com/ksysoev/wsget/pkg/ws"
+	"github.com/spf13/cobra"
+)
+
+// initProxyCommand initializes a Cobra command for relaying WebSocket clients to an upstream server.
+// It takes args of type *flags, which holds the options of the upstream connection.
+// It returns a pointer to a Cobra command configured with the connection, listening address and output flags.
+func initProxyCommand(args *flags) *cobra.Command {
+	listenArgs := &listenFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "proxy <upstream-url> [flags]",
+		Short:   "Relay WebSocket clients to an upstream server and print the traffic",
+		Example: `wsget proxy wss://ws.postman-echo.com/raw --port 8081`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, unnamedArgs []string) error {
+			return runProxyCmd(cmd.Context(), args, listenArgs, unnamedArgs[0])
+		},
+	}
+
+	addConnectionFlags(cmd, args)
+	addListenFlags(cmd, listenArgs, defaultProxyPort)
+	cmd.Flags().StringVarP(&args.outputFile, "output", "o", "", "Output file for saving all relayed messages")
+
+	return cmd
+}
+
+// runProxyCmd relays clients connecting to the local address to the upstream server until the context is canceled.
+// It takes ctx of type context.Context, args of type *flags, listenArgs of type *listenFlags and upstreamURL of type string.
+// It returns an error if the arguments are invalid, the output file cannot be opened,
+// the address cannot be listened on or serving fails.
+func runProxyCmd(ctx context.Context, args *flags, listenArgs *listenFlags, upstreamURL string) error {
+	if args.ipv4 && args.ipv6 {
+		return fmt.Errorf("invalid arguments: ipv4 and ipv6 options are mutually exclusive")
+	}
+
+	if args.configDir == "" {
+		currentUser, err := user.Current()
+		if err != nil {
+			return fmt.Errorf("fail to get current user: %s", err)
+		}
+
+		args.configDir = filepath.Join(currentUser.HomeDir, defaultConfigDir)
+	}
+
+	if err := applyFaultProfile(args); err != nil {
+		return fmt.Errorf("failed to apply fault profile: %w", err)
+	}
+
+	if err := applyHostConfig(upstreamURL, args); err != nil {
+		return fmt.Errorf("failed to apply host configuration: %w", err)
+	}
+
+	var timing io.Writer
+
+	if args.timing != "" {
+		timingOutput, err := openTimingOutput(args.timing)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = timingOutput.Close() }()
+
+		timing = timingOutput
+	}
+
+	var file io.Writer
+
+	if args.outputFile != "" {
+		outputFile, err := os.Create(args.outputFile)
+		if err != nil {
+			return fmt.Errorf("fail to open output file: %w", err)
+		}
+
+		defer func() { _ = outputFile.Close() }()
+
+		file = outputFile
+	}
+
+	dial := func(subprotocols []string) (server.Upstream, error) {
+		wsOpts, err := newWSOptions(args)
+		if err != nil {
+			return nil, err
+		}
+
+		wsOpts.Timing = timing
+
+		// Subprotocols set by flags take precedence over the ones offered by the client.
+		if len(wsOpts.Subprotocols) == 0 {
+			wsOpts.Subprotocols = subprotocols
+		}
+
+		conn, err := ws.New(upstreamURL, wsOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		return conn, nil
+	}
+
+	listener, err := listen(ctx, listenArgs)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Relaying to %s\n", upstreamURL)
+
+	return server.NewProxy(dial, formater.NewFormat(), os.Stdout, file).Serve(ctx, listener)
+}
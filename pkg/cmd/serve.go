@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/ksysoev/wsget/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+type listenFlags struct {
+	host       string
+	unixSocket string
+	port       int
+}
+
+type serveFlags struct {
+	rules string
+	listenFlags
+}
+
+// initServeCommand initializes a Cobra command for running a local WebSocket mock server.
+// It returns a pointer to a Cobra command configured with listening address and rules file flags.
+func initServeCommand() *cobra.Command {
+	args := &serveFlags{}
+
+	cmd := &cobra.Command{
+		Use:     "serve [flags]",
+		Short:   "Start a local WebSocket mock server answering according to a rules file",
+		Example: `wsget serve --port 8080 --rules rules.yaml`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runServeCmd(cmd.Context(), args)
+		},
+	}
+
+	cmd.Flags().StringVarP(&args.rules, "rules", "r", "", "YAML rules file describing server replies, the server echoes messages without it")
+	addListenFlags(cmd, &args.listenFlags, defaultServePort)
+
+	return cmd
+}
+
+// addListenFlags registers the flags configuring the local address to listen on.
+// It takes cmd of type *cobra.Command, args of type *listenFlags and defaultPort of type int.
+func addListenFlags(cmd *cobra.Command, args *listenFlags, defaultPort int) {
+	cmd.Flags().StringVar(&args.host, "host", "127.0.0.1", "Host to listen on")
+	cmd.Flags().IntVarP(&args.port, "port", "p", defaultPort, "Port to listen on")
+	cmd.Flags().StringVar(&args.unixSocket, "unix-socket", "", "Listen on this Unix domain socket instead of TCP")
+}
+
+// runServeCmd loads the rules and serves WebSocket clients until the context is canceled.
+// It takes ctx of type context.Context and args of type *serveFlags.
+// It returns an error if the rules cannot be loaded, the address cannot be listened on or serving fails.
+func runServeCmd(ctx context.Context, args *serveFlags) error {
+	var rules *server.Rules
+
+	if args.rules != "" {
+		var err error
+
+		if rules, err = server.LoadRules(args.rules); err != nil {
+			return err
+		}
+	}
+
+	listener, err := listen(ctx, &args.listenFlags)
+	if err != nil {
+		return err
+	}
+
+	return server.New(rules, os.Stdout).Serve(ctx, listener)
+}
+
+// listen opens the listener on the configured Unix socket or TCP address and prints the address.
+// It takes ctx of type context.Context and args of type *listenFlags.
+// It returns a net.Listener and an error if the address cannot be listened on.
+func listen(ctx context.Context, args *listenFlags) (net.Listener, error) {
+	network, address := "tcp", net.JoinHostPort(args.host, strconv.Itoa(args.port))
+	if args.unixSocket != "" {
+		network, address = "unix", args.unixSocket
+	}
+
+	listener, err := (&net.ListenConfig{}).Listen(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	if network == "unix" {
+		fmt.Printf("Listening on unix socket %s\n", address)
+	} else {
+		fmt.Printf("Listening on ws://%s\n", listener.Addr())
+	}
+
+	return listener, nil
+}
@@ -2,7 +2,6 @@ package core
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"io"
 	"time"
@@ -12,6 +11,7 @@ import (
 
 const (
 	CommandsLimit = 100
+	StatusesLimit = 16
 
 	HideCursor = "\x1b[?25l"
 	ShowCursor = "\x1b[?25h"
@@ -28,15 +28,26 @@ type CLI struct {
 	editor      Editor
 	inputStream chan KeyEvent
 	messages    chan Message
+	statuses    chan ConnectionStatus
 	done        chan struct{}
 	output      io.Writer
+	infoOutput  io.Writer
 	commands    chan Executer
 	cmdFactory  CommandFactory
+	registry    *connectionRegistry
 }
 
 type RunOptions struct {
-	OutputFile io.Writer
-	Commands   []Executer
+	OutputFile  io.Writer
+	Commands    []Executer
+	OnReconnect []Executer
+}
+
+// ConnectionStatus describes a change of the connection state reported to the CLI.
+// Reconnected is set when the connection was re-established after being dropped.
+type ConnectionStatus struct {
+	State       string
+	Reconnected bool
 }
 
 type Formater interface {
@@ -44,14 +55,47 @@ type Formater interface {
 	FormatForFile(msgType string, msgData string) (string, error)
 }
 
+// TimedFormater is implemented by formaters that print the time of the message,
+// the time the message was received or sent is printed instead of the time it is formatted.
+type TimedFormater interface {
+	FormatMessageAt(msgType string, msgData string, at time.Time) (string, error)
+	FormatForFileAt(msgType string, msgData string, at time.Time) (string, error)
+}
+
+// HeaderedFormater is implemented by formaters that control whether the direction headers
+// are printed with messages, formaters that do not implement it are printed with headers.
+type HeaderedFormater interface {
+	Headers() bool
+}
+
+// FormatSwitcher is implemented by formaters that allow changing the output format while the session runs.
+type FormatSwitcher interface {
+	SetFormat(name string) error
+}
+
 type CommandFactory interface {
 	Create(raw string) (Executer, error)
+	CreatePrint(msg Message) Executer
+}
+
+// PingStats contains round-trip time statistics of ping frames sent over the connection.
+type PingStats struct {
+	Count    int
+	Timeouts int
+	Min      time.Duration
+	Avg      time.Duration
+	P95      time.Duration
+	Max      time.Duration
+	Last     time.Duration
 }
 
 type ExecutionContext interface {
 	Print(data string, attr ...color.Attribute) error
+	PrintInfo(data string, attr ...color.Attribute) error
 	PrintToFile(data string) error
 	FormatMessage(msg Message, noColor bool) (string, error)
+	SetFormat(name string) error
+	PrintHeaders() bool
 	SendRequest(req string) error
 	SendBinaryRequest(data []byte) error
 	WaitForResponse(timeout time.Duration) (Message, error)
@@ -60,6 +104,14 @@ type ExecutionContext interface {
 	BinaryMode(initBuffer string) (string, error)
 	CreateCommand(raw string) (Executer, error)
 	Ping() error
+	PingStats() PingStats
+	Metrics() Metrics
+	CloseConnection(code int, reason string) error
+	Connect(name, url string) error
+	UseConnection(name string) error
+	Disconnect(name string) error
+	Connections() []ConnectionInfo
+	Protocol() Protocol
 }
 
 type Editor interface {
@@ -78,6 +130,11 @@ type ConnectionHandler interface {
 	Send(ctx context.Context, msg string) error
 	SendBinary(ctx context.Context, data []byte) error
 	Ping(ctx context.Context) error
+	PingStats() PingStats
+	Metrics() Metrics
+	CloseWithStatus(code int, reason string) error
+	URL() string
+	Protocol() Protocol
 }
 
 // NewCLI creates a new CLI instance with the given wsConn, input, and output.
@@ -90,28 +147,15 @@ func NewCLI(cmdFactory CommandFactory, wsConn ConnectionHandler, output io.Write
 		wsConn:      wsConn,
 		inputStream: make(chan KeyEvent),
 		messages:    make(chan Message),
+		statuses:    make(chan ConnectionStatus, StatusesLimit),
 		done:        make(chan struct{}),
 		output:      output,
 		commands:    make(chan Executer, CommandsLimit),
 		cmdFactory:  cmdFactory,
+		registry:    newConnectionRegistry(wsConn),
 	}
 
-	wsConn.SetOnMessage(func(ctx context.Context, msg []byte, isBinary bool) {
-		if isBinary {
-			data := base64.StdEncoding.EncodeToString(msg)
-			c.onMessage(ctx, Message{
-				Data: data,
-				Type: ResponseBinary,
-			})
-
-			return
-		}
-
-		c.onMessage(ctx, Message{
-			Data: string(msg),
-			Type: Response,
-		})
-	})
+	wsConn.SetOnMessage(c.subscribe(DefaultConnection))
 
 	editor.SetInput(c.inputStream)
 
@@ -133,6 +177,28 @@ func (c *CLI) onMessage(ctx context.Context, msg Message) {
 	}
 }
 
+// OnConnectionStatus reports a connection state change to the CLI.
+// It takes ctx of type context.Context and status of type ConnectionStatus.
+// The status is printed by the running CLI, and on reconnection the configured OnReconnect commands are executed.
+// The call never blocks, it is made from the read loop of the connection: when StatusesLimit statuses are
+// waiting to be printed, the oldest one is dropped. The call returns without effect if the CLI is stopped.
+func (c *CLI) OnConnectionStatus(_ context.Context, status ConnectionStatus) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case c.statuses <- status:
+			return
+		default:
+		}
+
+		select {
+		case <-c.statuses:
+		default:
+		}
+	}
+}
+
 // Run runs the CLI with the provided options.
 // It listens for user input and executes commands accordingly.
 func (c *CLI) Run(ctx context.Context, opts RunOptions) error {
@@ -144,23 +210,20 @@ func (c *CLI) Run(ctx context.Context, opts RunOptions) error {
 
 	c.hideCursor()
 
-	_, _ = fmt.Fprintln(c.output, WelcomMessage)
+	_, _ = fmt.Fprintln(c.info(), WelcomMessage)
 
 	for _, cmd := range opts.Commands {
 		c.commands <- cmd
 	}
 
 	exCtx := newExecutionContext(ctx, c, opts.OutputFile)
+	exCtx.onReconnect = opts.OnReconnect
 
 	for {
 		select {
 		case cmd := <-c.commands:
-			var err error
-			for cmd != nil {
-				cmd, err = cmd.Execute(exCtx)
-				if err != nil {
-					return fmt.Errorf("failed to execute command: %w", err)
-				}
+			if err := exCtx.execute(cmd); err != nil {
+				return err
 			}
 		case event := <-c.inputStream:
 			switch event.Key {
@@ -172,7 +235,7 @@ func (c *CLI) Run(ctx context.Context, opts RunOptions) error {
 
 				c.commands <- cmd
 			case KeyCtrlL:
-				_, _ = fmt.Fprintln(c.output, ClearTerminal+WelcomMessage)
+				_, _ = fmt.Fprintln(c.info(), ClearTerminal+WelcomMessage)
 			case KeyEnter:
 				cmd, err := c.cmdFactory.Create("edit")
 				if err != nil {
@@ -210,27 +273,52 @@ func (c *CLI) Run(ctx context.Context, opts RunOptions) error {
 				return nil
 			}
 
-			cmd, err := c.cmdFactory.Create(fmt.Sprintf("print %s %s", msg.Type.String(), msg.Data))
-			if err != nil {
-				return fmt.Errorf("fail to create print command: %w", err)
-			}
+			c.commands <- c.cmdFactory.CreatePrint(msg)
 
-			c.commands <- cmd
+		case status := <-c.statuses:
+			exCtx.onStatus(status)
 
 		case <-ctx.Done():
 			return nil
 		}
+
+		if err := exCtx.executePending(); err != nil {
+			return err
+		}
 	}
 }
 
+// SetInfoOutput sets the writer for the welcome message, connection statuses and terminal controls
+// used while the output format is printed without headers, so that the output contains only messages.
+// By default they are written to the output in all formats.
+func (c *CLI) SetInfoOutput(w io.Writer) {
+	c.infoOutput = w
+}
+
+// headers reports whether the direction headers are printed with messages in the current output format.
+func (c *CLI) headers() bool {
+	f, ok := c.formater.(HeaderedFormater)
+
+	return !ok || f.Headers()
+}
+
+// info returns the writer for the welcome message, connection statuses and terminal controls.
+func (c *CLI) info() io.Writer {
+	if c.infoOutput != nil && !c.headers() {
+		return c.infoOutput
+	}
+
+	return c.output
+}
+
 // hideCursor hides the cursor in the terminal output.
 func (c *CLI) hideCursor() {
-	_, _ = fmt.Fprint(c.output, HideCursor)
+	_, _ = fmt.Fprint(c.info(), HideCursor)
 }
 
 // showCursor shows the cursor in the terminal output.
 func (c *CLI) showCursor() {
-	_, _ = fmt.Fprint(c.output, ShowCursor)
+	_, _ = fmt.Fprint(c.info(), ShowCursor)
 }
 
 type MessageType uint8
@@ -257,7 +345,11 @@ func (mt MessageType) String() string {
 	}
 }
 
+// Message is a message sent or received during the session.
+// Time is when the message was received or sent, it is zero if the time is unknown.
 type Message struct {
-	Data string      `json:"data"`
-	Type MessageType `json:"type"`
+	Time       time.Time   `json:"time"`
+	Data       string      `json:"data"`
+	Connection string      `json:"connection,omitempty"`
+	Type       MessageType `json:"type"`
 }
@@ -1,16 +1,19 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewCLI(t *testing.T) {
@@ -63,6 +66,51 @@ func TestNewCLI(t *testing.T) {
 	}
 }
 
+func TestCLI_RunInfoOutput(t *testing.T) {
+	tests := []struct {
+		name           string
+		format         string
+		expectedOutput string
+		expectedInfo   string
+	}{
+		{
+			name:           "format with headers",
+			format:         "pretty",
+			expectedOutput: HideCursor + WelcomMessage + "\n" + ShowCursor,
+		},
+		{
+			name:         "format without headers",
+			format:       "raw",
+			expectedInfo: HideCursor + WelcomMessage + "\n" + ShowCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wsConn := NewMockConnectionHandler(t)
+			wsConn.EXPECT().SetOnMessage(mock.Anything)
+
+			editor := NewMockEditor(t)
+			editor.EXPECT().SetInput(mock.Anything)
+
+			output, info := &bytes.Buffer{}, &bytes.Buffer{}
+			formater := &switchingFormater{MockFormater: NewMockFormater(t), name: tt.format}
+
+			cli := NewCLI(NewMockCommandFactory(t), wsConn, output, editor, formater)
+			cli.SetInfoOutput(info)
+
+			cmd := NewMockExecuter(t)
+			cmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+			err := cli.Run(context.Background(), RunOptions{Commands: []Executer{cmd}})
+			require.ErrorIs(t, err, ErrInterrupted)
+
+			assert.Equal(t, tt.expectedOutput, output.String())
+			assert.Equal(t, tt.expectedInfo, info.String())
+		})
+	}
+}
+
 func TestNewCLIRunWithCommands(t *testing.T) {
 	wsConn := NewMockConnectionHandler(t)
 	wsConn.EXPECT().SetOnMessage(mock.Anything)
@@ -498,9 +546,9 @@ func TestCLI_Run_MessagesChannel(t *testing.T) {
 
 	mockCmd := NewMockExecuter(t)
 	mockCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
-	factory.EXPECT().Create(mock.MatchedBy(func(s string) bool {
-		return strings.HasPrefix(s, "print Response")
-	})).Return(mockCmd, nil)
+	factory.EXPECT().CreatePrint(mock.MatchedBy(func(msg Message) bool {
+		return msg.Type == Response && !msg.Time.IsZero()
+	})).Return(mockCmd)
 
 	editor := NewMockEditor(t)
 	editor.EXPECT().SetInput(mock.Anything)
@@ -567,3 +615,147 @@ func TestCLI_OnMessage_Binary(t *testing.T) {
 		t.Error("Timeout waiting for binary message")
 	}
 }
+
+func TestCLI_Run_ConnectionStatus(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+
+	factory := NewMockCommandFactory(t)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	output := &strings.Builder{}
+	cli := NewCLI(factory, wsConn, output, editor, NewMockFormater(t))
+
+	reconnectCmd := NewMockExecuter(t)
+	reconnectCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errChan := make(chan error)
+
+	go func() {
+		errChan <- cli.Run(ctx, RunOptions{OnReconnect: []Executer{reconnectCmd}})
+	}()
+
+	cli.OnConnectionStatus(ctx, ConnectionStatus{State: "reconnecting"})
+	cli.OnConnectionStatus(ctx, ConnectionStatus{State: "connected", Reconnected: true})
+
+	select {
+	case err := <-errChan:
+		assert.ErrorIs(t, err, ErrInterrupted)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Test timed out waiting for on reconnect command")
+	}
+
+	assert.Contains(t, output.String(), "[reconnecting]")
+	assert.Contains(t, output.String(), "[connected]")
+}
+
+func TestCLI_OnConnectionStatus_NonBlockingAfterRunExits(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	cli := NewCLI(NewMockCommandFactory(t), wsConn, &strings.Builder{}, editor, NewMockFormater(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NoError(t, cli.Run(ctx, RunOptions{}))
+
+	done := make(chan struct{})
+
+	go func() {
+		cli.OnConnectionStatus(context.Background(), ConnectionStatus{State: "disconnected"})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Error("OnConnectionStatus blocked after Run exited")
+	}
+}
+
+func TestCLI_OnConnectionStatus_DuringCommand(t *testing.T) {
+	wsConn := NewMockConnectionHandler(t)
+	wsConn.EXPECT().SetOnMessage(mock.Anything)
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything)
+
+	output := &strings.Builder{}
+	cli := NewCLI(NewMockCommandFactory(t), wsConn, output, editor, NewMockFormater(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	waiting := make(chan struct{})
+
+	waitCmd := NewMockExecuter(t)
+	waitCmd.EXPECT().Execute(mock.Anything).RunAndReturn(func(exCtx ExecutionContext) (Executer, error) {
+		close(waiting)
+
+		msg, err := exCtx.WaitForResponse(time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "pong", msg.Data)
+
+		return nil, nil
+	})
+
+	reconnectCmd := NewMockExecuter(t)
+	reconnectCmd.EXPECT().Execute(mock.Anything).Return(nil, ErrInterrupted)
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- cli.Run(ctx, RunOptions{Commands: []Executer{waitCmd}, OnReconnect: []Executer{reconnectCmd}})
+	}()
+
+	<-waiting
+
+	delivered := make(chan struct{})
+
+	go func() {
+		for range StatusesLimit * 2 {
+			cli.OnConnectionStatus(ctx, ConnectionStatus{State: "reconnecting"})
+		}
+
+		cli.OnConnectionStatus(ctx, ConnectionStatus{State: "connected", Reconnected: true})
+		close(delivered)
+	}()
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("OnConnectionStatus blocked while a command was running")
+	}
+
+	cli.messages <- Message{Type: Response, Data: "pong"}
+
+	select {
+	case err := <-errChan:
+		assert.ErrorIs(t, err, ErrInterrupted)
+	case <-time.After(time.Second):
+		t.Fatal("Test timed out waiting for on reconnect command")
+	}
+
+	assert.Contains(t, output.String(), "[reconnecting]")
+	assert.Contains(t, output.String(), "[connected]")
+}
+
+func TestCLI_OnConnectionStatus_DropsOldest(t *testing.T) {
+	cli, _ := newTestCLI(t)
+
+	for i := range StatusesLimit + 2 {
+		cli.OnConnectionStatus(context.Background(), ConnectionStatus{State: strconv.Itoa(i)})
+	}
+
+	assert.Len(t, cli.statuses, StatusesLimit)
+	assert.Equal(t, "2", (<-cli.statuses).State)
+}
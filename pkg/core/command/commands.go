@@ -2,8 +2,10 @@ package command
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -17,6 +19,11 @@ const (
 	LineClear   = "\x1b[2K"
 	HideCursor  = "\x1b[?25l"
 	ShowCursor  = "\x1b[?25h"
+
+	CloseNormalClosure = 1000
+	MaxCloseReasonSize = 123
+
+	ProtocolResponseTimeout = 30 * time.Second
 )
 
 type Edit struct {
@@ -63,7 +70,7 @@ func (c *Send) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
-	return NewPrintMsg(core.Message{Type: core.Request, Data: c.request}), nil
+	return NewPrintMsg(core.Message{Type: core.Request, Data: c.request, Time: time.Now()}), nil
 }
 
 type SendBinary struct {
@@ -87,7 +94,7 @@ func (c *SendBinary) Execute(exCtx core.ExecutionContext) (core.Executer, error)
 		return nil, fmt.Errorf("failed to send binary request: %w", err)
 	}
 
-	return NewPrintMsg(core.Message{Type: core.RequestBinary, Data: c.request}), nil
+	return NewPrintMsg(core.Message{Type: core.RequestBinary, Data: c.request, Time: time.Now()}), nil
 }
 
 type PrintMsg struct {
@@ -102,7 +109,7 @@ func NewPrintMsg(msg core.Message) *PrintMsg {
 }
 
 // Execute executes the PrintMsg command and returns nil and error.
-// It formats the message and prints it to the output file.
+// It formats the message and prints it with the direction header, unless the output format is printed without headers.
 // If an output file is provided, it writes the formatted message to the file.
 func (c *PrintMsg) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
 	output, err := exCtx.FormatMessage(c.msg, false)
@@ -110,21 +117,28 @@ func (c *PrintMsg) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
 		return nil, fmt.Errorf("fail to format message: %w", err)
 	}
 
+	var (
+		header string
+		attr   color.Attribute
+	)
+
 	switch c.msg.Type {
 	case core.Request:
-		err = exCtx.Print("->\n", color.FgGreen)
+		header, attr = "->\n", color.FgGreen
 	case core.Response:
-		err = exCtx.Print("<-\n", color.FgRed)
+		header, attr = "<-\n", color.FgRed
 	case core.ResponseBinary:
-		err = exCtx.Print("0101 <-\n", color.FgRed)
+		header, attr = "0101 <-\n", color.FgRed
 	case core.RequestBinary:
-		err = exCtx.Print("0101 ->\n", color.FgGreen)
+		header, attr = "0101 ->\n", color.FgGreen
 	default:
 		return nil, fmt.Errorf("unsupported message type: %s", c.msg.Type.String())
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("fail to print message: %w", err)
+	if exCtx.PrintHeaders() {
+		if err := exCtx.Print(header, attr); err != nil {
+			return nil, fmt.Errorf("fail to print message: %w", err)
+		}
 	}
 
 	if err := exCtx.Print(output + "\n"); err != nil {
@@ -208,7 +222,7 @@ func (c *CmdEdit) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
 
 	cmd, err := exCtx.CreateCommand(rawCmd)
 	if err != nil {
-		printErr := exCtx.Print(fmt.Sprintf("Invalid command: %s\n", rawCmd), color.FgRed)
+		printErr := exCtx.PrintInfo(fmt.Sprintf("Invalid command: %s\n", rawCmd), color.FgRed)
 		if printErr != nil {
 			return nil, fmt.Errorf("failed to print error message: %w", printErr)
 		}
@@ -365,7 +379,7 @@ func NewPingCommand() *PingCommand {
 func (c *PingCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
 	startTime := time.Now()
 
-	if err := exCtx.Print("-> ping\n", color.FgGreen); err != nil {
+	if err := exCtx.PrintInfo("-> ping\n", color.FgGreen); err != nil {
 		return nil, fmt.Errorf("failed to print ping message: %w", err)
 	}
 
@@ -375,9 +389,376 @@ func (c *PingCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error
 
 	duration := time.Since(startTime)
 
-	if err := exCtx.Print(fmt.Sprintf("<- pong, %v\n", duration), color.FgRed); err != nil {
+	if err := exCtx.PrintInfo(fmt.Sprintf("<- pong, %v\n", duration), color.FgRed); err != nil {
 		return nil, fmt.Errorf("failed to print pong message: %w", err)
 	}
 
 	return nil, nil
 }
+
+type PingStatsCommand struct{}
+
+// NewPingStatsCommand creates a new PingStatsCommand instance.
+// It takes no parameters and returns a pointer to a PingStatsCommand.
+func NewPingStatsCommand() *PingStatsCommand {
+	return &PingStatsCommand{}
+}
+
+// Execute prints the round-trip time statistics of the pings sent over the connection.
+// It returns an error if printing the statistics fails.
+func (c *PingStatsCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	stats := exCtx.PingStats()
+
+	output := fmt.Sprintf("ping statistics: %d pings, %d timeouts\n", stats.Count, stats.Timeouts)
+
+	if stats.Count > 0 {
+		output += fmt.Sprintf(
+			"rtt min/avg/p95/max = %v/%v/%v/%v, last %v\n",
+			stats.Min, stats.Avg, stats.P95, stats.Max, stats.Last,
+		)
+	}
+
+	if err := exCtx.PrintInfo(output, color.FgYellow); err != nil {
+		return nil, fmt.Errorf("failed to print ping statistics: %w", err)
+	}
+
+	return nil, nil
+}
+
+type CloseCommand struct {
+	reason string
+	code   int
+}
+
+// NewCloseCommand creates a new CloseCommand instance.
+// It takes code of type int, the WebSocket close status code, and reason of type string sent in the close frame.
+// It returns a pointer to a CloseCommand.
+func NewCloseCommand(code int, reason string) *CloseCommand {
+	return &CloseCommand{code: code, reason: reason}
+}
+
+// Execute closes the WebSocket connection with the command's status code and reason.
+// It returns an error if printing the close message fails or the connection cannot be closed.
+func (c *CloseCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	output := fmt.Sprintf("-> close %d", c.code)
+	if c.reason != "" {
+		output += " " + c.reason
+	}
+
+	if err := exCtx.PrintInfo(output+"\n", color.FgGreen); err != nil {
+		return nil, fmt.Errorf("failed to print close message: %w", err)
+	}
+
+	if err := exCtx.CloseConnection(c.code, c.reason); err != nil {
+		return nil, fmt.Errorf("failed to close connection: %w", err)
+	}
+
+	return nil, nil
+}
+
+type StatsCommand struct{}
+
+// NewStatsCommand creates a new StatsCommand instance.
+// It takes no parameters and returns a pointer to a StatsCommand.
+func NewStatsCommand() *StatsCommand {
+	return &StatsCommand{}
+}
+
+// Execute prints the message statistics of the connection: message and byte counts per direction,
+// message rates over the last 10 and 60 seconds and the message size distribution.
+// It returns an error if printing the statistics fails.
+func (c *StatsCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	metrics := exCtx.Metrics()
+
+	output := metrics.Summary() +
+		formatDirectionMetrics("sent", metrics.Sent) +
+		formatDirectionMetrics("received", metrics.Received)
+
+	if err := exCtx.PrintInfo(output, color.FgYellow); err != nil {
+		return nil, fmt.Errorf("failed to print statistics: %w", err)
+	}
+
+	return nil, nil
+}
+
+// formatDirectionMetrics formats rates and size distribution of messages in one direction.
+// It returns an empty string if no messages were transferred in the direction.
+func formatDirectionMetrics(direction string, m core.DirectionMetrics) string {
+	if m.Messages == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(
+		&sb, "%s: %.1f msg/s, %.0f bytes/s (10s), %.1f msg/s, %.0f bytes/s (60s), avg interval %v\n",
+		direction, m.Rate10s, m.ByteRate10s, m.Rate60s, m.ByteRate60s, m.AvgInterval.Round(time.Millisecond),
+	)
+	fmt.Fprintf(&sb, "%s sizes: min %d, avg %d, max %d bytes\n", direction, m.MinSize, m.AvgSize(), m.MaxSize)
+
+	for i, count := range m.SizeBuckets {
+		if count == 0 {
+			continue
+		}
+
+		if i < len(core.MessageSizeBounds) {
+			fmt.Fprintf(&sb, "  <= %d bytes: %d\n", core.MessageSizeBounds[i], count)
+		} else {
+			fmt.Fprintf(&sb, "  > %d bytes: %d\n", core.MessageSizeBounds[len(core.MessageSizeBounds)-1], count)
+		}
+	}
+
+	return sb.String()
+}
+
+type ConnectCommand struct {
+	name string
+	url  string
+}
+
+// NewConnectCommand creates a new ConnectCommand instance.
+// It takes name of type string, the name of the new connection, and url of type string, the WebSocket server address.
+// It returns a pointer to a ConnectCommand.
+func NewConnectCommand(name, url string) *ConnectCommand {
+	return &ConnectCommand{name: name, url: url}
+}
+
+// Execute opens the named connection and makes it active.
+// It returns an error if the connection cannot be established or printing the status fails.
+func (c *ConnectCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if err := exCtx.Connect(c.name, c.url); err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	if err := exCtx.PrintInfo(fmt.Sprintf("[%s: connected to %s]\n", c.name, c.url), color.FgYellow); err != nil {
+		return nil, fmt.Errorf("failed to print connection status: %w", err)
+	}
+
+	return nil, nil
+}
+
+type UseCommand struct {
+	name string
+}
+
+// NewUseCommand creates a new UseCommand instance.
+// It takes name of type string, the name of the connection to make active.
+// It returns a pointer to a UseCommand.
+func NewUseCommand(name string) *UseCommand {
+	return &UseCommand{name: name}
+}
+
+// Execute makes the named connection active, so that following requests are sent to it.
+// It returns an error if there is no connection with the name or printing the status fails.
+func (c *UseCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if err := exCtx.UseConnection(c.name); err != nil {
+		return nil, fmt.Errorf("failed to switch connection: %w", err)
+	}
+
+	if err := exCtx.PrintInfo(fmt.Sprintf("[using %s]\n", c.name), color.FgYellow); err != nil {
+		return nil, fmt.Errorf("failed to print connection status: %w", err)
+	}
+
+	return nil, nil
+}
+
+type DisconnectCommand struct {
+	name string
+}
+
+// NewDisconnectCommand creates a new DisconnectCommand instance.
+// It takes name of type string, the name of the connection to close.
+// It returns a pointer to a DisconnectCommand.
+func NewDisconnectCommand(name string) *DisconnectCommand {
+	return &DisconnectCommand{name: name}
+}
+
+// Execute closes the named connection and removes it from the session.
+// It returns an error if the connection cannot be closed or printing the status fails.
+func (c *DisconnectCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if err := exCtx.Disconnect(c.name); err != nil {
+		return nil, fmt.Errorf("failed to disconnect: %w", err)
+	}
+
+	if err := exCtx.PrintInfo(fmt.Sprintf("[%s: disconnected]\n", c.name), color.FgYellow); err != nil {
+		return nil, fmt.Errorf("failed to print connection status: %w", err)
+	}
+
+	return nil, nil
+}
+
+type ConnsCommand struct{}
+
+// NewConnsCommand creates a new ConnsCommand instance.
+// It takes no parameters and returns a pointer to a ConnsCommand.
+func NewConnsCommand() *ConnsCommand {
+	return &ConnsCommand{}
+}
+
+// Execute prints the connections of the session, marking the active one with an asterisk.
+// It returns an error if printing the list fails.
+func (c *ConnsCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	var sb strings.Builder
+
+	for _, conn := range exCtx.Connections() {
+		mark := " "
+		if conn.Active {
+			mark = "*"
+		}
+
+		fmt.Fprintf(&sb, "%s %s\t%s\n", mark, conn.Name, conn.URL)
+	}
+
+	if err := exCtx.PrintInfo(sb.String(), color.FgYellow); err != nil {
+		return nil, fmt.Errorf("failed to print connections: %w", err)
+	}
+
+	return nil, nil
+}
+
+type FormatCommand struct {
+	name string
+}
+
+// NewFormatCommand creates a new FormatCommand instance.
+// It takes name of type string, the name of the output format to switch to.
+// It returns a pointer to a FormatCommand.
+func NewFormatCommand(name string) *FormatCommand {
+	return &FormatCommand{name: name}
+}
+
+// Execute changes the output format of the following messages.
+// It returns an error if the format is not supported or printing the status fails.
+func (c *FormatCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	if err := exCtx.SetFormat(c.name); err != nil {
+		return nil, fmt.Errorf("failed to change output format: %w", err)
+	}
+
+	if err := exCtx.PrintInfo(fmt.Sprintf("[format %s]\n", c.name), color.FgYellow); err != nil {
+		return nil, fmt.Errorf("failed to print format status: %w", err)
+	}
+
+	return nil, nil
+}
+
+type ProtocolCommand struct {
+	name string
+	args string
+}
+
+// NewProtocolCommand creates a new ProtocolCommand instance.
+// It takes name of type string, the command provided by the application protocol like "emit",
+// and args of type string, the raw arguments of the command.
+// It returns a pointer to a ProtocolCommand.
+func NewProtocolCommand(name, args string) *ProtocolCommand {
+	return &ProtocolCommand{name: name, args: args}
+}
+
+// Execute encodes the command with the protocol of the active connection and sends the resulting frames.
+// It returns a Sequence printing the sent frames and waiting for the responses they expect,
+// and an error if the connection has no protocol, the protocol rejects the command or sending fails.
+// A command rejected because the server has not acknowledged the session is reported and the session continues.
+func (c *ProtocolCommand) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	proto := exCtx.Protocol()
+	if proto == nil {
+		return nil, fmt.Errorf("%s command requires a protocol mode, see --protocol", c.name)
+	}
+
+	frames, err := proto.Encode(c.name, c.args)
+	if errors.Is(err, core.ErrNotAcknowledged) {
+		if err := exCtx.PrintInfo(fmt.Sprintf("[%s is not sent: %s]\n", c.name, err), color.FgYellow); err != nil {
+			return nil, fmt.Errorf("fail to print: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s command: %w", c.name, err)
+	}
+
+	prints := make([]core.Executer, 0, len(frames))
+	tracker, _ := proto.(core.ResponseTracker)
+
+	var awaits []core.Executer
+
+	for _, frame := range frames {
+		msg := core.Message{Type: core.Request, Data: frame.Display, Time: time.Now()}
+
+		if frame.Binary {
+			err = exCtx.SendBinaryRequest(frame.Data)
+		} else {
+			err = exCtx.SendRequest(string(frame.Data))
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to send %s frame: %w", proto.Name(), err)
+		}
+
+		switch {
+		case msg.Data != "":
+		case frame.Binary:
+			msg = core.Message{Type: core.RequestBinary, Data: base64.StdEncoding.EncodeToString(frame.Data), Time: msg.Time}
+		default:
+			msg.Data = string(frame.Data)
+		}
+
+		prints = append(prints, NewPrintMsg(msg))
+
+		if frame.Response != "" && tracker != nil {
+			awaits = append(awaits, NewAwaitResponse(tracker, frame.Response, ProtocolResponseTimeout))
+		}
+	}
+
+	return NewSequence(append(prints, awaits...)), nil
+}
+
+type AwaitResponse struct {
+	deadline time.Time
+	tracker  core.ResponseTracker
+	id       string
+}
+
+// NewAwaitResponse creates a new AwaitResponse instance.
+// It takes tracker of type core.ResponseTracker, the protocol correlating responses, id of type string,
+// the correlation id of the expected response, and timeout of type time.Duration.
+// It returns a pointer to an AwaitResponse.
+func NewAwaitResponse(tracker core.ResponseTracker, id string, timeout time.Duration) *AwaitResponse {
+	return &AwaitResponse{tracker: tracker, id: id, deadline: time.Now().Add(timeout)}
+}
+
+// Execute waits for the next message and prints it, messages received before the expected response,
+// like notifications, are printed as well and waiting continues.
+// If the response is not received before the deadline, the missing response is reported and the session continues.
+// It returns an error only if the session is interrupted.
+func (c *AwaitResponse) Execute(exCtx core.ExecutionContext) (core.Executer, error) {
+	timeout := time.Until(c.deadline)
+	if timeout <= 0 {
+		return nil, c.noResponse(exCtx)
+	}
+
+	msg, err := exCtx.WaitForResponse(timeout)
+	if errors.Is(err, core.ErrInterrupted) {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, c.noResponse(exCtx)
+	}
+
+	if c.tracker.IsResponse(msg.Data, c.id) {
+		return NewPrintMsg(msg), nil
+	}
+
+	return NewSequence([]core.Executer{NewPrintMsg(msg), c}), nil
+}
+
+// noResponse reports that the response to the request was not received.
+// It returns an error if the report cannot be printed.
+func (c *AwaitResponse) noResponse(exCtx core.ExecutionContext) error {
+	if err := exCtx.PrintInfo(fmt.Sprintf("[no response for request %s]\n", c.id), color.FgYellow); err != nil {
+		return fmt.Errorf("fail to print: %w", err)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -9,8 +10,10 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/protocol"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExit_Execute(t *testing.T) {
@@ -149,6 +152,8 @@ func TestPrintMsg_Execute(t *testing.T) {
 				Return(tt.mockFormatOutput, tt.mockFormatError).
 				Maybe()
 
+			exCtx.EXPECT().PrintHeaders().Return(true).Maybe()
+
 			if tt.mockFormatError == nil {
 				switch tt.message.Type {
 				case core.Request:
@@ -246,7 +251,7 @@ func TestCmdEdit_Execute(t *testing.T) {
 			exCtx := core.NewMockExecutionContext(t)
 			exCtx.EXPECT().CommandMode("").Return(tt.mockRawCommand, tt.mockCommandError).Maybe()
 			exCtx.EXPECT().CreateCommand(tt.mockRawCommand).Return(tt.mockCreateCmd, tt.mockCreateCmdErr).Maybe()
-			exCtx.EXPECT().Print("Invalid command: "+tt.mockRawCommand+"\n", color.FgRed).Return(nil).Maybe()
+			exCtx.EXPECT().PrintInfo("Invalid command: "+tt.mockRawCommand+"\n", color.FgRed).Return(nil).Maybe()
 
 			cmd := NewCmdEdit()
 			nextCmd, err := cmd.Execute(exCtx)
@@ -618,7 +623,7 @@ func TestSend_Execute(t *testing.T) {
 				assert.Nil(t, nextCmd)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedNextCmd, nextCmd)
+				assert.Equal(t, tt.expectedNextCmd, withoutTime(t, nextCmd))
 			}
 		})
 	}
@@ -755,8 +760,8 @@ func TestPingExecute_Success(t *testing.T) {
 	exCtx := core.NewMockExecutionContext(t)
 
 	exCtx.EXPECT().Ping().Return(nil)
-	exCtx.EXPECT().Print(mock.Anything, color.FgGreen).Return(nil)
-	exCtx.EXPECT().Print(mock.Anything, color.FgRed).Return(nil)
+	exCtx.EXPECT().PrintInfo(mock.Anything, color.FgGreen).Return(nil)
+	exCtx.EXPECT().PrintInfo(mock.Anything, color.FgRed).Return(nil)
 	nextCmd, err := cmd.Execute(exCtx)
 
 	assert.Nil(t, nextCmd)
@@ -768,7 +773,7 @@ func TestPingExecute_Failure(t *testing.T) {
 
 	exCtx := core.NewMockExecutionContext(t)
 
-	exCtx.EXPECT().Print(mock.Anything, color.FgGreen).Return(nil)
+	exCtx.EXPECT().PrintInfo(mock.Anything, color.FgGreen).Return(nil)
 	exCtx.EXPECT().Ping().Return(assert.AnError)
 
 	nextCmd, err := cmd.Execute(exCtx)
@@ -777,6 +782,59 @@ func TestPingExecute_Failure(t *testing.T) {
 	assert.ErrorIs(t, err, assert.AnError)
 }
 
+func TestPingStatsCommand_Execute(t *testing.T) {
+	tests := []struct {
+		printErr error
+		name     string
+		expected string
+		stats    core.PingStats
+	}{
+		{
+			name:     "no pings",
+			stats:    core.PingStats{},
+			expected: "ping statistics: 0 pings, 0 timeouts\n",
+		},
+		{
+			name: "with pings",
+			stats: core.PingStats{
+				Count:    3,
+				Timeouts: 1,
+				Min:      time.Millisecond,
+				Avg:      2 * time.Millisecond,
+				P95:      3 * time.Millisecond,
+				Max:      3 * time.Millisecond,
+				Last:     2 * time.Millisecond,
+			},
+			expected: "ping statistics: 3 pings, 1 timeouts\nrtt min/avg/p95/max = 1ms/2ms/3ms/3ms, last 2ms\n",
+		},
+		{
+			name:     "print error",
+			stats:    core.PingStats{},
+			expected: "ping statistics: 0 pings, 0 timeouts\n",
+			printErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exCtx := core.NewMockExecutionContext(t)
+
+			exCtx.EXPECT().PingStats().Return(tt.stats)
+			exCtx.EXPECT().PrintInfo(tt.expected, color.FgYellow).Return(tt.printErr)
+
+			nextCmd, err := NewPingStatsCommand().Execute(exCtx)
+
+			assert.Nil(t, nextCmd)
+
+			if tt.printErr != nil {
+				assert.ErrorIs(t, err, tt.printErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestSendBinary_Execute(t *testing.T) {
 	t.Parallel()
 
@@ -843,7 +901,7 @@ func TestSendBinary_Execute(t *testing.T) {
 				assert.Nil(t, nextCmd)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedNextCmd, nextCmd)
+				assert.Equal(t, tt.expectedNextCmd, withoutTime(t, nextCmd))
 			}
 		})
 	}
@@ -916,3 +974,420 @@ func TestBinEdit_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestCloseCommand_Execute(t *testing.T) {
+	tests := []struct {
+		printErr error
+		closeErr error
+		name     string
+		reason   string
+		expected string
+		wantErr  string
+		code     int
+	}{
+		{
+			name:     "normal closure",
+			code:     1000,
+			expected: "-> close 1000\n",
+		},
+		{
+			name:     "with reason",
+			code:     1008,
+			reason:   "policy violation",
+			expected: "-> close 1008 policy violation\n",
+		},
+		{
+			name:     "print error",
+			code:     1000,
+			expected: "-> close 1000\n",
+			printErr: assert.AnError,
+			wantErr:  "failed to print close message",
+		},
+		{
+			name:     "close error",
+			code:     1000,
+			expected: "-> close 1000\n",
+			closeErr: assert.AnError,
+			wantErr:  "failed to close connection",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exCtx := core.NewMockExecutionContext(t)
+
+			exCtx.EXPECT().PrintInfo(tt.expected, color.FgGreen).Return(tt.printErr)
+
+			if tt.printErr == nil {
+				exCtx.EXPECT().CloseConnection(tt.code, tt.reason).Return(tt.closeErr)
+			}
+
+			nextCmd, err := NewCloseCommand(tt.code, tt.reason).Execute(exCtx)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Nil(t, nextCmd)
+		})
+	}
+}
+
+func TestStatsCommand_Execute(t *testing.T) {
+	buckets := make([]uint64, len(core.MessageSizeBounds)+1)
+	buckets[0] = 2
+	buckets[len(buckets)-1] = 1
+
+	tests := []struct {
+		printErr error
+		name     string
+		expected string
+		metrics  core.Metrics
+	}{
+		{
+			name:     "no messages",
+			metrics:  core.Metrics{Uptime: 5 * time.Second},
+			expected: "sent 0 messages (0 bytes), received 0 messages (0 bytes) in 5s\n",
+		},
+		{
+			name: "received messages",
+			metrics: core.Metrics{
+				Uptime: time.Minute,
+				Received: core.DirectionMetrics{
+					Messages:    3,
+					Bytes:       300060,
+					MinSize:     10,
+					MaxSize:     300000,
+					Rate10s:     0.3,
+					Rate60s:     0.05,
+					ByteRate10s: 30006,
+					ByteRate60s: 5001,
+					AvgInterval: 1500 * time.Millisecond,
+					SizeBuckets: buckets,
+				},
+			},
+			expected: "sent 0 messages (0 bytes), received 3 messages (300060 bytes) in 1m0s\n" +
+				"received: 0.3 msg/s, 30006 bytes/s (10s), 0.1 msg/s, 5001 bytes/s (60s), avg interval 1.5s\n" +
+				"received sizes: min 10, avg 100020, max 300000 bytes\n" +
+				"  <= 64 bytes: 2\n" +
+				"  > 262144 bytes: 1\n",
+		},
+		{
+			name:     "print error",
+			metrics:  core.Metrics{},
+			expected: "sent 0 messages (0 bytes), received 0 messages (0 bytes) in 0s\n",
+			printErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exCtx := core.NewMockExecutionContext(t)
+
+			exCtx.EXPECT().Metrics().Return(tt.metrics)
+			exCtx.EXPECT().PrintInfo(tt.expected, color.FgYellow).Return(tt.printErr)
+
+			nextCmd, err := NewStatsCommand().Execute(exCtx)
+
+			if tt.printErr != nil {
+				assert.ErrorContains(t, err, "failed to print statistics")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Nil(t, nextCmd)
+		})
+	}
+}
+
+func TestConnectCommand_Execute(t *testing.T) {
+	tests := []struct {
+		connectErr error
+		name       string
+		wantErr    string
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:       "connect error",
+			connectErr: assert.AnError,
+			wantErr:    "failed to connect",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exCtx := core.NewMockExecutionContext(t)
+			exCtx.EXPECT().Connect("api", "ws://api").Return(tt.connectErr)
+
+			if tt.connectErr == nil {
+				exCtx.EXPECT().PrintInfo("[api: connected to ws://api]\n", color.FgYellow).Return(nil)
+			}
+
+			nextCmd, err := NewConnectCommand("api", "ws://api").Execute(exCtx)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Nil(t, nextCmd)
+		})
+	}
+}
+
+func TestPrintMsg_ExecuteWithoutHeaders(t *testing.T) {
+	msg := core.Message{Type: core.Response, Data: `{"a":1}`}
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().FormatMessage(msg, false).Return(`{"a":1}`, nil)
+	exCtx.EXPECT().PrintHeaders().Return(false)
+	exCtx.EXPECT().Print("{\"a\":1}\n").Return(nil)
+	exCtx.EXPECT().FormatMessage(msg, true).Return(`{"a":1}`, nil)
+	exCtx.EXPECT().PrintToFile("{\"a\":1}\n").Return(nil)
+
+	nextCmd, err := NewPrintMsg(msg).Execute(exCtx)
+	assert.NoError(t, err)
+	assert.Nil(t, nextCmd)
+}
+
+func TestUseCommand_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().UseConnection("api").Return(nil)
+	exCtx.EXPECT().PrintInfo("[using api]\n", color.FgYellow).Return(nil)
+
+	nextCmd, err := NewUseCommand("api").Execute(exCtx)
+	assert.NoError(t, err)
+	assert.Nil(t, nextCmd)
+
+	exCtx = core.NewMockExecutionContext(t)
+	exCtx.EXPECT().UseConnection("unknown").Return(core.ErrUnknownConnection)
+
+	_, err = NewUseCommand("unknown").Execute(exCtx)
+	assert.ErrorIs(t, err, core.ErrUnknownConnection)
+}
+
+func TestDisconnectCommand_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Disconnect("api").Return(nil)
+	exCtx.EXPECT().PrintInfo("[api: disconnected]\n", color.FgYellow).Return(nil)
+
+	nextCmd, err := NewDisconnectCommand("api").Execute(exCtx)
+	assert.NoError(t, err)
+	assert.Nil(t, nextCmd)
+
+	exCtx = core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Disconnect("default").Return(assert.AnError)
+
+	_, err = NewDisconnectCommand("default").Execute(exCtx)
+	assert.ErrorContains(t, err, "failed to disconnect")
+}
+
+func TestFormatCommand_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetFormat("ndjson").Return(nil)
+	exCtx.EXPECT().PrintInfo("[format ndjson]\n", color.FgYellow).Return(nil)
+
+	nextCmd, err := NewFormatCommand("ndjson").Execute(exCtx)
+	assert.NoError(t, err)
+	assert.Nil(t, nextCmd)
+
+	exCtx = core.NewMockExecutionContext(t)
+	exCtx.EXPECT().SetFormat("xml").Return(assert.AnError)
+
+	_, err = NewFormatCommand("xml").Execute(exCtx)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorContains(t, err, "failed to change output format")
+}
+
+func TestConnsCommand_Execute(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Connections().Return([]core.ConnectionInfo{
+		{Name: "default", URL: "ws://localhost"},
+		{Name: "api", URL: "ws://api", Active: true},
+	})
+	exCtx.EXPECT().PrintInfo("  default\tws://localhost\n* api\tws://api\n", color.FgYellow).Return(nil)
+
+	nextCmd, err := NewConnsCommand().Execute(exCtx)
+	assert.NoError(t, err)
+	assert.Nil(t, nextCmd)
+}
+
+func TestProtocolCommand_Execute(t *testing.T) {
+	proto := core.NewMockProtocol(t)
+	proto.EXPECT().Encode("emit", "chat").Return([]core.ProtocolFrame{
+		{Data: []byte(`420["chat"]`), Display: `{"type":"emit","event":"chat"}`},
+		{Data: []byte("raw")},
+		{Data: []byte{1, 2}, Binary: true},
+	}, nil)
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Protocol().Return(proto)
+	exCtx.EXPECT().SendRequest(`420["chat"]`).Return(nil)
+	exCtx.EXPECT().SendRequest("raw").Return(nil)
+	exCtx.EXPECT().SendBinaryRequest([]byte{1, 2}).Return(nil)
+
+	nextCmd, err := NewProtocolCommand("emit", "chat").Execute(exCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, NewSequence([]core.Executer{
+		NewPrintMsg(core.Message{Type: core.Request, Data: `{"type":"emit","event":"chat"}`}),
+		NewPrintMsg(core.Message{Type: core.Request, Data: "raw"}),
+		NewPrintMsg(core.Message{Type: core.RequestBinary, Data: "AQI="}),
+	}), withoutTime(t, nextCmd))
+}
+
+func TestProtocolCommand_Execute_Errors(t *testing.T) {
+	t.Run("no protocol", func(t *testing.T) {
+		exCtx := core.NewMockExecutionContext(t)
+		exCtx.EXPECT().Protocol().Return(nil)
+
+		_, err := NewProtocolCommand("emit", "chat").Execute(exCtx)
+		assert.EqualError(t, err, "emit command requires a protocol mode, see --protocol")
+	})
+
+	t.Run("encode failure", func(t *testing.T) {
+		proto := core.NewMockProtocol(t)
+		proto.EXPECT().Encode("subscribe", "chat").Return(nil, core.ErrUnsupportedCommand)
+
+		exCtx := core.NewMockExecutionContext(t)
+		exCtx.EXPECT().Protocol().Return(proto)
+
+		_, err := NewProtocolCommand("subscribe", "chat").Execute(exCtx)
+		assert.ErrorIs(t, err, core.ErrUnsupportedCommand)
+	})
+
+	t.Run("send failure", func(t *testing.T) {
+		proto := core.NewMockProtocol(t)
+		proto.EXPECT().Encode("emit", "chat").Return([]core.ProtocolFrame{{Data: []byte(`420["chat"]`)}}, nil)
+		proto.EXPECT().Name().Return("Socket.IO")
+
+		exCtx := core.NewMockExecutionContext(t)
+		exCtx.EXPECT().Protocol().Return(proto)
+		exCtx.EXPECT().SendRequest(`420["chat"]`).Return(assert.AnError)
+
+		_, err := NewProtocolCommand("emit", "chat").Execute(exCtx)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestProtocolCommand_Execute_NotAcknowledged(t *testing.T) {
+	// The server never acknowledges the GraphQL connection.
+	proto, err := protocol.NewGraphQL("", 10*time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = proto.Open()
+	require.NoError(t, err)
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Protocol().Return(proto)
+	exCtx.EXPECT().PrintInfo("[subscribe is not sent: connection is not acknowledged by the server within 10ms]\n", color.FgYellow).Return(nil)
+
+	nextCmd, err := NewProtocolCommand("subscribe", "subscription { ticks }").Execute(exCtx)
+	assert.NoError(t, err)
+	assert.Nil(t, nextCmd)
+}
+
+// responseTracker correlates the responses by their display, which is mapped to the correlation id.
+type responseTracker struct {
+	responses map[string]string
+}
+
+func (r *responseTracker) IsResponse(msg, id string) bool {
+	return r.responses[msg] == id
+}
+
+func TestProtocolCommand_Execute_AwaitsResponse(t *testing.T) {
+	proto := &struct {
+		*core.MockProtocol
+		*responseTracker
+	}{core.NewMockProtocol(t), &responseTracker{}}
+	proto.EXPECT().Encode("call", "eth_blockNumber").Return([]core.ProtocolFrame{{Data: []byte(`{"id":1}`), Response: "1"}}, nil)
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().Protocol().Return(proto)
+	exCtx.EXPECT().SendRequest(`{"id":1}`).Return(nil)
+
+	nextCmd, err := NewProtocolCommand("call", "eth_blockNumber").Execute(exCtx)
+	require.NoError(t, err)
+
+	seq, ok := nextCmd.(*Sequence)
+	require.True(t, ok)
+	require.Len(t, seq.subCommands, 2)
+	assert.Equal(t, NewPrintMsg(core.Message{Type: core.Request, Data: `{"id":1}`}), withoutTime(t, seq.subCommands[0]))
+
+	await, ok := seq.subCommands[1].(*AwaitResponse)
+	require.True(t, ok)
+	assert.Equal(t, "1", await.id)
+	assert.Equal(t, proto, await.tracker)
+}
+
+func TestAwaitResponse_Execute(t *testing.T) {
+	tracker := &responseTracker{responses: map[string]string{`{"type":"result","id":1}`: "1", `{"type":"result","id":2}`: "2"}}
+	notification := core.Message{Type: core.Response, Data: `{"type":"notification"}`}
+	otherResponse := core.Message{Type: core.Response, Data: `{"type":"result","id":2}`}
+	response := core.Message{Type: core.Response, Data: `{"type":"result","id":1}`}
+
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().WaitForResponse(mock.Anything).Return(notification, nil).Once()
+
+	await := NewAwaitResponse(tracker, "1", time.Minute)
+
+	nextCmd, err := await.Execute(exCtx)
+	require.NoError(t, err)
+	assert.Equal(t, NewSequence([]core.Executer{NewPrintMsg(notification), await}), nextCmd)
+
+	exCtx.EXPECT().WaitForResponse(mock.Anything).Return(otherResponse, nil).Once()
+
+	nextCmd, err = await.Execute(exCtx)
+	require.NoError(t, err)
+	assert.Equal(t, NewSequence([]core.Executer{NewPrintMsg(otherResponse), await}), nextCmd)
+
+	exCtx.EXPECT().WaitForResponse(mock.Anything).Return(response, nil).Once()
+
+	nextCmd, err = await.Execute(exCtx)
+	require.NoError(t, err)
+	assert.Equal(t, NewPrintMsg(response), nextCmd)
+}
+
+func TestAwaitResponse_Execute_NoResponse(t *testing.T) {
+	exCtx := core.NewMockExecutionContext(t)
+	exCtx.EXPECT().PrintInfo("[no response for request 1]\n", color.FgYellow).Return(nil).Twice()
+
+	nextCmd, err := NewAwaitResponse(&responseTracker{}, "1", 0).Execute(exCtx)
+	assert.NoError(t, err)
+	assert.Nil(t, nextCmd)
+
+	exCtx.EXPECT().WaitForResponse(mock.Anything).Return(core.Message{}, context.DeadlineExceeded).Once()
+
+	nextCmd, err = NewAwaitResponse(&responseTracker{}, "1", time.Minute).Execute(exCtx)
+	assert.NoError(t, err)
+	assert.Nil(t, nextCmd)
+
+	exCtx.EXPECT().WaitForResponse(mock.Anything).Return(core.Message{}, core.ErrInterrupted).Once()
+
+	_, err = NewAwaitResponse(&responseTracker{}, "1", time.Minute).Execute(exCtx)
+	assert.ErrorIs(t, err, core.ErrInterrupted)
+}
+
+// withoutTime asserts that the messages printed by the command have the time they were sent
+// and clears it, so that the command can be compared with the expected one.
+func withoutTime(t *testing.T, cmd core.Executer) core.Executer {
+	t.Helper()
+
+	switch c := cmd.(type) {
+	case *PrintMsg:
+		assert.False(t, c.msg.Time.IsZero(), "message time is not set")
+
+		c.msg.Time = time.Time{}
+	case *Sequence:
+		for _, sub := range c.subCommands {
+			withoutTime(t, sub)
+		}
+	}
+
+	return cmd
+}
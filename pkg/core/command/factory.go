@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +12,15 @@ import (
 
 type MacroRepo interface {
 	Get(name, argString string) (core.Executer, error)
+	Has(name string) bool
+}
+
+// macroOverridable lists the commands that give way to user macros with the same name,
+// so that macro files written before the commands were added keep working.
+var macroOverridable = []string{
+	"stats", "close", "connect", "use", "disconnect", "conns", "format",
+	"emit", "subscribe", "unsubscribe", "complete", "call", "stomp-send", "join", "leave", "push", "mqtt-sub", "mqtt-pub",
+	"mqtt-connect",
 }
 
 type Factory struct {
@@ -29,6 +39,10 @@ func (f *Factory) Create(raw string) (core.Executer, error) {
 	parts := strings.SplitN(raw, " ", PartsNumber)
 	cmd := parts[0]
 
+	if f.macro != nil && slices.Contains(macroOverridable, cmd) && f.macro.Has(cmd) {
+		return f.createMacro(cmd, parts)
+	}
+
 	switch cmd {
 	case "exit":
 		return NewExit(), nil
@@ -52,6 +66,24 @@ func (f *Factory) Create(raw string) (core.Executer, error) {
 		return createSleep(raw, parts)
 	case "ping":
 		return NewPingCommand(), nil
+	case "stats":
+		return createStats(parts)
+	case "close":
+		return createClose(parts)
+	case "connect":
+		return createConnect(raw, parts)
+	case "use":
+		return createUse(raw, parts)
+	case "disconnect":
+		return createDisconnect(raw, parts)
+	case "conns":
+		return NewConnsCommand(), nil
+	case "format":
+		return createFormat(raw, parts)
+	case "emit", "subscribe", "unsubscribe", "complete", "call", "stomp-send", "join", "leave", "push", "mqtt-sub", "mqtt-pub":
+		return createProtocolCommand(raw, parts)
+	case "mqtt-connect":
+		return createMQTTConnect(parts), nil
 	default:
 		return f.createMacro(cmd, parts)
 	}
@@ -82,6 +114,12 @@ func createSendBinary(parts []string) (core.Executer, error) {
 	return NewSendBinary(parts[1]), nil
 }
 
+// CreatePrint creates the command printing the message received or sent during the session.
+// It takes msg of type core.Message, its time is printed by the output formats with timestamps.
+func (f *Factory) CreatePrint(msg core.Message) core.Executer {
+	return NewPrintMsg(msg)
+}
+
 func createPrint(raw string, parts []string) (core.Executer, error) {
 	if len(parts) == 1 {
 		return nil, &ErrEmptyRequest{}
@@ -93,12 +131,14 @@ func createPrint(raw string, parts []string) (core.Executer, error) {
 		return nil, fmt.Errorf("not enough arguments for print command: %s", raw)
 	}
 
-	msgType, err := parseMsgType(args[0])
+	typeName, connName, _ := strings.Cut(args[0], "@")
+
+	msgType, err := parseMsgType(typeName)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewPrintMsg(core.Message{Type: msgType, Data: args[1]}), nil
+	return NewPrintMsg(core.Message{Type: msgType, Data: args[1], Connection: connName}), nil
 }
 
 func parseMsgType(s string) (core.MessageType, error) {
@@ -164,6 +204,108 @@ func createSleep(raw string, parts []string) (core.Executer, error) {
 	return NewSleepCommand(time.Duration(sec) * time.Second), nil
 }
 
+func createStats(parts []string) (core.Executer, error) {
+	if len(parts) < PartsNumber {
+		return NewStatsCommand(), nil
+	}
+
+	switch parts[1] {
+	case "ping":
+		return NewPingStatsCommand(), nil
+	default:
+		return nil, fmt.Errorf("unknown statistics: %s", parts[1])
+	}
+}
+
+func createClose(parts []string) (core.Executer, error) {
+	if len(parts) < PartsNumber {
+		return NewCloseCommand(CloseNormalClosure, ""), nil
+	}
+
+	args := strings.SplitN(parts[1], " ", PartsNumber)
+
+	code, err := strconv.Atoi(args[0])
+	if err != nil || !isValidCloseCode(code) {
+		return nil, fmt.Errorf("invalid close code: %s", args[0])
+	}
+
+	reason := ""
+	if len(args) > 1 {
+		reason = args[1]
+	}
+
+	if len(reason) > MaxCloseReasonSize {
+		return nil, fmt.Errorf("close reason is too long: %d bytes, maximum is %d", len(reason), MaxCloseReasonSize)
+	}
+
+	return NewCloseCommand(code, reason), nil
+}
+
+// isValidCloseCode reports whether the close status code can be sent in a close frame according to RFC 6455.
+func isValidCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1014:
+		return true
+	default:
+		return code >= 3000 && code <= 4999
+	}
+}
+
+func createConnect(raw string, parts []string) (core.Executer, error) {
+	if len(parts) < PartsNumber {
+		return nil, fmt.Errorf("not enough arguments for connect command: %s", raw)
+	}
+
+	args := strings.Fields(parts[1])
+	if len(args) != PartsNumber {
+		return nil, fmt.Errorf("connect command expects a name and a url: %s", raw)
+	}
+
+	return NewConnectCommand(args[0], args[1]), nil
+}
+
+func createUse(raw string, parts []string) (core.Executer, error) {
+	if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+		return nil, fmt.Errorf("not enough arguments for use command: %s", raw)
+	}
+
+	return NewUseCommand(strings.TrimSpace(parts[1])), nil
+}
+
+func createDisconnect(raw string, parts []string) (core.Executer, error) {
+	if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+		return nil, fmt.Errorf("not enough arguments for disconnect command: %s", raw)
+	}
+
+	return NewDisconnectCommand(strings.TrimSpace(parts[1])), nil
+}
+
+func createFormat(raw string, parts []string) (core.Executer, error) {
+	if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+		return nil, fmt.Errorf("not enough arguments for format command: %s", raw)
+	}
+
+	return NewFormatCommand(strings.TrimSpace(parts[1])), nil
+}
+
+func createProtocolCommand(raw string, parts []string) (core.Executer, error) {
+	if len(parts) < PartsNumber || strings.TrimSpace(parts[1]) == "" {
+		return nil, fmt.Errorf("not enough arguments for %s command: %s", parts[0], raw)
+	}
+
+	return NewProtocolCommand(parts[0], strings.TrimSpace(parts[1])), nil
+}
+
+// createMQTTConnect creates the mqtt-connect protocol command, its arguments are optional.
+func createMQTTConnect(parts []string) core.Executer {
+	args := ""
+	if len(parts) > 1 {
+		args = strings.TrimSpace(parts[1])
+	}
+
+	return NewProtocolCommand(parts[0], args)
+}
+
 func (f *Factory) createMacro(cmd string, parts []string) (core.Executer, error) {
 	args := ""
 	if len(parts) > 1 {
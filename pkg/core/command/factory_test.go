@@ -140,6 +140,209 @@ func TestFactory_Create(t *testing.T) {
 			want:    NewPingCommand(),
 			wantErr: false,
 		},
+		{
+			name:    "stats ping command",
+			raw:     "stats ping",
+			macro:   nil,
+			want:    NewPingStatsCommand(),
+			wantErr: false,
+		},
+		{
+			name:    "stats command without arguments",
+			raw:     "stats",
+			macro:   nil,
+			want:    NewStatsCommand(),
+			wantErr: false,
+		},
+		{
+			name:    "stats command with unknown statistics",
+			raw:     "stats unknown",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "close command",
+			raw:     "close",
+			macro:   nil,
+			want:    NewCloseCommand(1000, ""),
+			wantErr: false,
+		},
+		{
+			name:    "close command with code and reason",
+			raw:     "close 4001 session expired",
+			macro:   nil,
+			want:    NewCloseCommand(4001, "session expired"),
+			wantErr: false,
+		},
+		{
+			name:    "close command with reserved code",
+			raw:     "close 1006",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "close command with invalid code",
+			raw:     "close going-away",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "close command with too long reason",
+			raw:     "close 1000 " + strings.Repeat("a", 124),
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "connect command",
+			raw:     "connect api wss://api.example.com",
+			macro:   nil,
+			want:    NewConnectCommand("api", "wss://api.example.com"),
+			wantErr: false,
+		},
+		{
+			name:    "connect command without url",
+			raw:     "connect api",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "connect command without arguments",
+			raw:     "connect",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "use command",
+			raw:     "use api",
+			macro:   nil,
+			want:    NewUseCommand("api"),
+			wantErr: false,
+		},
+		{
+			name:    "use command without name",
+			raw:     "use",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "disconnect command",
+			raw:     "disconnect api",
+			macro:   nil,
+			want:    NewDisconnectCommand("api"),
+			wantErr: false,
+		},
+		{
+			name:    "disconnect command without name",
+			raw:     "disconnect ",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "format command",
+			raw:     "format ndjson",
+			macro:   nil,
+			want:    NewFormatCommand("ndjson"),
+			wantErr: false,
+		},
+		{
+			name:    "format command without name",
+			raw:     "format",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "conns command",
+			raw:     "conns",
+			macro:   nil,
+			want:    NewConnsCommand(),
+			wantErr: false,
+		},
+		{
+			name:    "emit command",
+			raw:     `emit /chat message {"text":"hi"}`,
+			macro:   nil,
+			want:    NewProtocolCommand("emit", `/chat message {"text":"hi"}`),
+			wantErr: false,
+		},
+		{
+			name:    "subscribe command",
+			raw:     "subscribe subscription { ticks }",
+			macro:   nil,
+			want:    NewProtocolCommand("subscribe", "subscription { ticks }"),
+			wantErr: false,
+		},
+		{
+			name:    "complete command",
+			raw:     "complete 1",
+			macro:   nil,
+			want:    NewProtocolCommand("complete", "1"),
+			wantErr: false,
+		},
+		{
+			name:    "call command",
+			raw:     `call eth_getBalance ["0xabc", "latest"]`,
+			macro:   nil,
+			want:    NewProtocolCommand("call", `eth_getBalance ["0xabc", "latest"]`),
+			wantErr: false,
+		},
+		{
+			name:    "stomp-send command",
+			raw:     `stomp-send /queue/orders {"id": 1}`,
+			macro:   nil,
+			want:    NewProtocolCommand("stomp-send", `/queue/orders {"id": 1}`),
+			wantErr: false,
+		},
+		{
+			name:    "unsubscribe command",
+			raw:     "unsubscribe sub-0",
+			macro:   nil,
+			want:    NewProtocolCommand("unsubscribe", "sub-0"),
+			wantErr: false,
+		},
+		{
+			name:    "push command",
+			raw:     `push room:lobby new_msg {"body": "hi"}`,
+			macro:   nil,
+			want:    NewProtocolCommand("push", `room:lobby new_msg {"body": "hi"}`),
+			wantErr: false,
+		},
+		{
+			name:    "mqtt-connect command without arguments",
+			raw:     "mqtt-connect",
+			macro:   nil,
+			want:    NewProtocolCommand("mqtt-connect", ""),
+			wantErr: false,
+		},
+		{
+			name:    "mqtt-sub command",
+			raw:     "mqtt-sub sensors/+/temp 1",
+			macro:   nil,
+			want:    NewProtocolCommand("mqtt-sub", "sensors/+/temp 1"),
+			wantErr: false,
+		},
+		{
+			name:    "mqtt-pub command without payload",
+			raw:     "mqtt-pub ",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "emit command without event",
+			raw:     "emit ",
+			macro:   nil,
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name:    "repeat command",
 			raw:     "repeat 3 send test",
@@ -189,6 +392,13 @@ func TestFactory_Create(t *testing.T) {
 			want:    NewPrintMsg(core.Message{Type: core.Response, Data: "test message"}),
 			wantErr: false,
 		},
+		{
+			name:    "print command with connection name",
+			raw:     "print Response@api test message",
+			macro:   nil,
+			want:    NewPrintMsg(core.Message{Type: core.Response, Data: "test message", Connection: "api"}),
+			wantErr: false,
+		},
 		{
 			name:    "print command without message",
 			raw:     "print",
@@ -322,3 +532,38 @@ func TestFactory_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestFactory_Create_MacroOverridesCommand(t *testing.T) {
+	macroCmd := NewSend("macro")
+
+	macro := NewMockMacroRepo(t)
+	macro.EXPECT().Has("subscribe").Return(true)
+	macro.EXPECT().Has("emit").Return(false)
+	macro.EXPECT().Has("close").Return(true)
+	macro.EXPECT().Get("close", "").Return(macroCmd, nil)
+	macro.EXPECT().Get("subscribe", "prices").Return(macroCmd, nil)
+
+	factory := NewFactory(macro)
+
+	cmd, err := factory.Create("subscribe prices")
+	assert.NoError(t, err)
+	assert.Equal(t, macroCmd, cmd)
+
+	cmd, err = factory.Create("close")
+	assert.NoError(t, err)
+	assert.Equal(t, macroCmd, cmd)
+
+	cmd, err = factory.Create("emit chat hi")
+	assert.NoError(t, err)
+	assert.Equal(t, NewProtocolCommand("emit", "chat hi"), cmd)
+
+	cmd, err = factory.Create("send hello")
+	assert.NoError(t, err)
+	assert.Equal(t, NewSend("hello"), cmd)
+}
+
+func TestFactory_CreatePrint(t *testing.T) {
+	msg := core.Message{Type: core.Response, Data: "hello", Connection: "api", Time: time.Now()}
+
+	assert.Equal(t, NewPrintMsg(msg), NewFactory(nil).CreatePrint(msg))
+}
@@ -40,15 +40,16 @@ func (t *Templates) GetExecuter(args []string) (core.Executer, error) {
 	data := struct {
 		Args []string
 	}{args}
-	cmds := make([]core.Executer, len(t.list))
 
-	for i, tmpl := range t.list {
-		var output bytes.Buffer
-		if err := tmpl.Execute(&output, data); err != nil {
-			return nil, err
-		}
+	outputs, err := t.Render(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]core.Executer, len(outputs))
 
-		cmd, err := NewFactory(nil).Create(output.String())
+	for i, output := range outputs {
+		cmd, err := NewFactory(nil).Create(output)
 		if err != nil {
 			return nil, err
 		}
@@ -62,3 +63,21 @@ func (t *Templates) GetExecuter(args []string) (core.Executer, error) {
 
 	return NewSequence(cmds), nil
 }
+
+// Render executes the templates with the provided data.
+// It takes data of type any, which is passed to every template.
+// It returns the outputs of the templates in order and an error if a template execution fails.
+func (t *Templates) Render(data any) ([]string, error) {
+	outputs := make([]string, len(t.list))
+
+	for i, tmpl := range t.list {
+		var output bytes.Buffer
+		if err := tmpl.Execute(&output, data); err != nil {
+			return nil, err
+		}
+
+		outputs[i] = output.String()
+	}
+
+	return outputs, nil
+}
@@ -81,6 +81,52 @@ func (_c *MockMacroRepo_Get_Call) RunAndReturn(run func(string, string) (core.Ex
 	return _c
 }
 
+// Has provides a mock function with given fields: name
+func (_m *MockMacroRepo) Has(name string) bool {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Has")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockMacroRepo_Has_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Has'
+type MockMacroRepo_Has_Call struct {
+	*mock.Call
+}
+
+// Has is a helper method to define mock.On call
+//   - name string
+func (_e *MockMacroRepo_Expecter) Has(name interface{}) *MockMacroRepo_Has_Call {
+	return &MockMacroRepo_Has_Call{Call: _e.mock.On("Has", name)}
+}
+
+func (_c *MockMacroRepo_Has_Call) Run(run func(name string)) *MockMacroRepo_Has_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockMacroRepo_Has_Call) Return(_a0 bool) *MockMacroRepo_Has_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMacroRepo_Has_Call) RunAndReturn(run func(string) bool) *MockMacroRepo_Has_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockMacroRepo creates a new instance of MockMacroRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockMacroRepo(t interface {
@@ -130,3 +130,18 @@ func TestTemplates_GetExecuter(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplates_Render(t *testing.T) {
+	tmpls, err := NewMacro([]string{"hello {{.Name}}", "static"})
+	assert.NoError(t, err)
+
+	outputs, err := tmpls.Render(struct{ Name string }{"world"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hello world", "static"}, outputs)
+
+	tmpls, err = NewMacro([]string{"{{.Missing}}"})
+	assert.NoError(t, err)
+
+	_, err = tmpls.Render(struct{}{})
+	assert.Error(t, err)
+}
@@ -77,6 +77,54 @@ func (_c *MockCommandFactory_Create_Call) RunAndReturn(run func(string) (Execute
 	return _c
 }
 
+// CreatePrint provides a mock function with given fields: msg
+func (_m *MockCommandFactory) CreatePrint(msg Message) Executer {
+	ret := _m.Called(msg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePrint")
+	}
+
+	var r0 Executer
+	if rf, ok := ret.Get(0).(func(Message) Executer); ok {
+		r0 = rf(msg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Executer)
+		}
+	}
+
+	return r0
+}
+
+// MockCommandFactory_CreatePrint_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreatePrint'
+type MockCommandFactory_CreatePrint_Call struct {
+	*mock.Call
+}
+
+// CreatePrint is a helper method to define mock.On call
+//   - msg Message
+func (_e *MockCommandFactory_Expecter) CreatePrint(msg interface{}) *MockCommandFactory_CreatePrint_Call {
+	return &MockCommandFactory_CreatePrint_Call{Call: _e.mock.On("CreatePrint", msg)}
+}
+
+func (_c *MockCommandFactory_CreatePrint_Call) Run(run func(msg Message)) *MockCommandFactory_CreatePrint_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(Message))
+	})
+	return _c
+}
+
+func (_c *MockCommandFactory_CreatePrint_Call) Return(_a0 Executer) *MockCommandFactory_CreatePrint_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockCommandFactory_CreatePrint_Call) RunAndReturn(run func(Message) Executer) *MockCommandFactory_CreatePrint_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockCommandFactory creates a new instance of MockCommandFactory. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockCommandFactory(t interface {
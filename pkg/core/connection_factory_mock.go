@@ -0,0 +1,99 @@
+// Code generated by mockery v2.53.6. DO NOT EDIT.
+
+//go:build !compile
+
+package core
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockConnectionFactory is an autogenerated mock type for the ConnectionFactory type
+type MockConnectionFactory struct {
+	mock.Mock
+}
+
+type MockConnectionFactory_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockConnectionFactory) EXPECT() *MockConnectionFactory_Expecter {
+	return &MockConnectionFactory_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function with given fields: ctx, name, url, onMessage
+func (_m *MockConnectionFactory) Create(ctx context.Context, name string, url string, onMessage func(context.Context, []byte, bool)) (ConnectionHandler, error) {
+	ret := _m.Called(ctx, name, url, onMessage)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 ConnectionHandler
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, func(context.Context, []byte, bool)) (ConnectionHandler, error)); ok {
+		return rf(ctx, name, url, onMessage)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, func(context.Context, []byte, bool)) ConnectionHandler); ok {
+		r0 = rf(ctx, name, url, onMessage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(ConnectionHandler)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, func(context.Context, []byte, bool)) error); ok {
+		r1 = rf(ctx, name, url, onMessage)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockConnectionFactory_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockConnectionFactory_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+//   - url string
+//   - onMessage func(context.Context , []byte , bool)
+func (_e *MockConnectionFactory_Expecter) Create(ctx interface{}, name interface{}, url interface{}, onMessage interface{}) *MockConnectionFactory_Create_Call {
+	return &MockConnectionFactory_Create_Call{Call: _e.mock.On("Create", ctx, name, url, onMessage)}
+}
+
+func (_c *MockConnectionFactory_Create_Call) Run(run func(ctx context.Context, name string, url string, onMessage func(context.Context, []byte, bool))) *MockConnectionFactory_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(func(context.Context, []byte, bool)))
+	})
+	return _c
+}
+
+func (_c *MockConnectionFactory_Create_Call) Return(_a0 ConnectionHandler, _a1 error) *MockConnectionFactory_Create_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockConnectionFactory_Create_Call) RunAndReturn(run func(context.Context, string, string, func(context.Context, []byte, bool)) (ConnectionHandler, error)) *MockConnectionFactory_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockConnectionFactory creates a new instance of MockConnectionFactory. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockConnectionFactory(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockConnectionFactory {
+	mock := &MockConnectionFactory{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -23,6 +23,98 @@ func (_m *MockConnectionHandler) EXPECT() *MockConnectionHandler_Expecter {
 	return &MockConnectionHandler_Expecter{mock: &_m.Mock}
 }
 
+// CloseWithStatus provides a mock function with given fields: code, reason
+func (_m *MockConnectionHandler) CloseWithStatus(code int, reason string) error {
+	ret := _m.Called(code, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloseWithStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, string) error); ok {
+		r0 = rf(code, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_CloseWithStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CloseWithStatus'
+type MockConnectionHandler_CloseWithStatus_Call struct {
+	*mock.Call
+}
+
+// CloseWithStatus is a helper method to define mock.On call
+//   - code int
+//   - reason string
+func (_e *MockConnectionHandler_Expecter) CloseWithStatus(code interface{}, reason interface{}) *MockConnectionHandler_CloseWithStatus_Call {
+	return &MockConnectionHandler_CloseWithStatus_Call{Call: _e.mock.On("CloseWithStatus", code, reason)}
+}
+
+func (_c *MockConnectionHandler_CloseWithStatus_Call) Run(run func(code int, reason string)) *MockConnectionHandler_CloseWithStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_CloseWithStatus_Call) Return(_a0 error) *MockConnectionHandler_CloseWithStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_CloseWithStatus_Call) RunAndReturn(run func(int, string) error) *MockConnectionHandler_CloseWithStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Metrics provides a mock function with no fields
+func (_m *MockConnectionHandler) Metrics() Metrics {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Metrics")
+	}
+
+	var r0 Metrics
+	if rf, ok := ret.Get(0).(func() Metrics); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(Metrics)
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_Metrics_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Metrics'
+type MockConnectionHandler_Metrics_Call struct {
+	*mock.Call
+}
+
+// Metrics is a helper method to define mock.On call
+func (_e *MockConnectionHandler_Expecter) Metrics() *MockConnectionHandler_Metrics_Call {
+	return &MockConnectionHandler_Metrics_Call{Call: _e.mock.On("Metrics")}
+}
+
+func (_c *MockConnectionHandler_Metrics_Call) Run(run func()) *MockConnectionHandler_Metrics_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_Metrics_Call) Return(_a0 Metrics) *MockConnectionHandler_Metrics_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_Metrics_Call) RunAndReturn(run func() Metrics) *MockConnectionHandler_Metrics_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Ping provides a mock function with given fields: ctx
 func (_m *MockConnectionHandler) Ping(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -69,6 +161,98 @@ func (_c *MockConnectionHandler_Ping_Call) RunAndReturn(run func(context.Context
 	return _c
 }
 
+// PingStats provides a mock function with no fields
+func (_m *MockConnectionHandler) PingStats() PingStats {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PingStats")
+	}
+
+	var r0 PingStats
+	if rf, ok := ret.Get(0).(func() PingStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(PingStats)
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_PingStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PingStats'
+type MockConnectionHandler_PingStats_Call struct {
+	*mock.Call
+}
+
+// PingStats is a helper method to define mock.On call
+func (_e *MockConnectionHandler_Expecter) PingStats() *MockConnectionHandler_PingStats_Call {
+	return &MockConnectionHandler_PingStats_Call{Call: _e.mock.On("PingStats")}
+}
+
+func (_c *MockConnectionHandler_PingStats_Call) Run(run func()) *MockConnectionHandler_PingStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_PingStats_Call) Return(_a0 PingStats) *MockConnectionHandler_PingStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_PingStats_Call) RunAndReturn(run func() PingStats) *MockConnectionHandler_PingStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Protocol provides a mock function with no fields
+func (_m *MockConnectionHandler) Protocol() Protocol {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Protocol")
+	}
+
+	var r0 Protocol
+	if rf, ok := ret.Get(0).(func() Protocol); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Protocol)
+		}
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_Protocol_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Protocol'
+type MockConnectionHandler_Protocol_Call struct {
+	*mock.Call
+}
+
+// Protocol is a helper method to define mock.On call
+func (_e *MockConnectionHandler_Expecter) Protocol() *MockConnectionHandler_Protocol_Call {
+	return &MockConnectionHandler_Protocol_Call{Call: _e.mock.On("Protocol")}
+}
+
+func (_c *MockConnectionHandler_Protocol_Call) Run(run func()) *MockConnectionHandler_Protocol_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_Protocol_Call) Return(_a0 Protocol) *MockConnectionHandler_Protocol_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_Protocol_Call) RunAndReturn(run func() Protocol) *MockConnectionHandler_Protocol_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Send provides a mock function with given fields: ctx, msg
 func (_m *MockConnectionHandler) Send(ctx context.Context, msg string) error {
 	ret := _m.Called(ctx, msg)
@@ -196,6 +380,51 @@ func (_c *MockConnectionHandler_SetOnMessage_Call) RunAndReturn(run func(func(co
 	return _c
 }
 
+// URL provides a mock function with no fields
+func (_m *MockConnectionHandler) URL() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for URL")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockConnectionHandler_URL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'URL'
+type MockConnectionHandler_URL_Call struct {
+	*mock.Call
+}
+
+// URL is a helper method to define mock.On call
+func (_e *MockConnectionHandler_Expecter) URL() *MockConnectionHandler_URL_Call {
+	return &MockConnectionHandler_URL_Call{Call: _e.mock.On("URL")}
+}
+
+func (_c *MockConnectionHandler_URL_Call) Run(run func()) *MockConnectionHandler_URL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockConnectionHandler_URL_Call) Return(_a0 string) *MockConnectionHandler_URL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockConnectionHandler_URL_Call) RunAndReturn(run func() string) *MockConnectionHandler_URL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockConnectionHandler creates a new instance of MockConnectionHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockConnectionHandler(t interface {
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// DefaultConnection is the name of the connection the session was started with.
+	DefaultConnection = "default"
+
+	closeNormalClosure = 1000
+)
+
+var (
+	ErrConnectionsNotSupported = errors.New("multiple connections are not supported")
+	ErrUnknownConnection       = errors.New("unknown connection")
+)
+
+// connectionColors is the palette used to tag messages of different connections.
+var connectionColors = []color.Attribute{
+	color.FgCyan,
+	color.FgMagenta,
+	color.FgBlue,
+	color.FgYellow,
+	color.FgHiCyan,
+	color.FgHiMagenta,
+	color.FgHiBlue,
+	color.FgHiYellow,
+}
+
+// ConnectionFactory creates additional named connections for the session.
+type ConnectionFactory interface {
+	Create(ctx context.Context, name, url string, onMessage func(context.Context, []byte, bool)) (ConnectionHandler, error)
+}
+
+// ConnectionInfo describes a named connection of the session.
+type ConnectionInfo struct {
+	Name   string
+	URL    string
+	Active bool
+}
+
+// connectionRegistry keeps the named connections of the session and the name of the active one.
+// Message callbacks of the connections read the registry concurrently, so access is synchronized.
+type connectionRegistry struct {
+	factory ConnectionFactory
+	conns   map[string]ConnectionHandler
+	active  string
+	order   []string
+	l       sync.Mutex
+}
+
+// newConnectionRegistry creates a registry with the default connection as the active one.
+func newConnectionRegistry(conn ConnectionHandler) *connectionRegistry {
+	return &connectionRegistry{
+		conns:  map[string]ConnectionHandler{DefaultConnection: conn},
+		order:  []string{DefaultConnection},
+		active: DefaultConnection,
+	}
+}
+
+// tag returns the name used to tag messages of the connection,
+// or an empty string if the session has a single connection and messages are not tagged.
+func (r *connectionRegistry) tag(name string) string {
+	if r == nil {
+		return ""
+	}
+
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	if len(r.conns) < 2 {
+		return ""
+	}
+
+	return name
+}
+
+// activeName returns the name of the active connection.
+func (r *connectionRegistry) activeName() string {
+	if r == nil {
+		return DefaultConnection
+	}
+
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	return r.active
+}
+
+// activeConn returns the active connection.
+func (r *connectionRegistry) activeConn() ConnectionHandler {
+	r.l.Lock()
+	defer r.l.Unlock()
+
+	return r.conns[r.active]
+}
+
+// activeConn returns the connection requests are sent to.
+// The active connection is changed by connect, use and disconnect commands,
+// so it is read from the registry under its lock.
+func (c *CLI) activeConn() ConnectionHandler {
+	if c.registry == nil {
+		return c.wsConn
+	}
+
+	return c.registry.activeConn()
+}
+
+// SetConnectionFactory enables connect, use and disconnect commands,
+// which manage additional named connections created by the provided factory.
+func (c *CLI) SetConnectionFactory(factory ConnectionFactory) {
+	if c.registry == nil {
+		c.registry = newConnectionRegistry(c.wsConn)
+	}
+
+	c.registry.l.Lock()
+	defer c.registry.l.Unlock()
+
+	c.registry.factory = factory
+}
+
+// subscribe returns the message callback for the named connection,
+// which tags the messages with the connection name when the session has several connections.
+func (c *CLI) subscribe(name string) func(context.Context, []byte, bool) {
+	return func(ctx context.Context, msg []byte, isBinary bool) {
+		message := Message{
+			Data:       string(msg),
+			Type:       Response,
+			Connection: c.registry.tag(name),
+			Time:       time.Now(),
+		}
+
+		if isBinary {
+			message.Data = base64.StdEncoding.EncodeToString(msg)
+			message.Type = ResponseBinary
+		}
+
+		c.onMessage(ctx, message)
+	}
+}
+
+// connect creates a new named connection and makes it active.
+// It returns an error if connections are not supported, the name is invalid or taken, or the connection fails.
+func (c *CLI) connect(ctx context.Context, name, url string) error {
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("invalid connection name: %q", name)
+	}
+
+	if c.registry == nil {
+		return ErrConnectionsNotSupported
+	}
+
+	c.registry.l.Lock()
+	factory := c.registry.factory
+	_, exists := c.registry.conns[name]
+	c.registry.l.Unlock()
+
+	if factory == nil {
+		return ErrConnectionsNotSupported
+	}
+
+	if exists {
+		return fmt.Errorf("connection %q already exists", name)
+	}
+
+	conn, err := factory.Create(ctx, name, url, c.subscribe(name))
+	if err != nil {
+		return fmt.Errorf("failed to connect %q: %w", name, err)
+	}
+
+	c.registry.l.Lock()
+	c.registry.conns[name] = conn
+	c.registry.order = append(c.registry.order, name)
+	c.registry.l.Unlock()
+
+	return c.use(name)
+}
+
+// use makes the named connection active, so that requests are sent to it.
+// It returns ErrUnknownConnection if there is no connection with the name.
+func (c *CLI) use(name string) error {
+	if c.registry == nil {
+		return ErrConnectionsNotSupported
+	}
+
+	c.registry.l.Lock()
+	defer c.registry.l.Unlock()
+
+	if _, ok := c.registry.conns[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownConnection, name)
+	}
+
+	c.registry.active = name
+
+	return nil
+}
+
+// disconnect closes the named connection and removes it from the session.
+// If the connection was active, the default connection becomes active.
+// It returns an error if the connection does not exist, is the default connection or fails to close.
+func (c *CLI) disconnect(name string) error {
+	if name == DefaultConnection {
+		return fmt.Errorf("the %s connection cannot be disconnected, use exit instead", DefaultConnection)
+	}
+
+	if c.registry == nil {
+		return ErrConnectionsNotSupported
+	}
+
+	c.registry.l.Lock()
+
+	conn, ok := c.registry.conns[name]
+	if !ok {
+		c.registry.l.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownConnection, name)
+	}
+
+	delete(c.registry.conns, name)
+	c.registry.order = slices.DeleteFunc(c.registry.order, func(n string) bool { return n == name })
+
+	if c.registry.active == name {
+		c.registry.active = DefaultConnection
+	}
+
+	c.registry.l.Unlock()
+
+	if err := conn.CloseWithStatus(closeNormalClosure, "disconnect"); err != nil {
+		return fmt.Errorf("failed to close connection %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// connections returns the connections of the session in the order they were created.
+func (c *CLI) connections() []ConnectionInfo {
+	if c.registry == nil {
+		return nil
+	}
+
+	c.registry.l.Lock()
+	defer c.registry.l.Unlock()
+
+	infos := make([]ConnectionInfo, 0, len(c.registry.order))
+
+	for _, name := range c.registry.order {
+		infos = append(infos, ConnectionInfo{
+			Name:   name,
+			URL:    c.registry.conns[name].URL(),
+			Active: name == c.registry.active,
+		})
+	}
+
+	return infos
+}
+
+// connectionColor returns the color used to tag messages of the named connection.
+func connectionColor(name string) color.Attribute {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+
+	return connectionColors[h.Sum32()%uint32(len(connectionColors))] //nolint:gosec // the palette length fits in uint32
+}
@@ -0,0 +1,255 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCLI(t *testing.T) (*CLI, *MockConnectionHandler) {
+	t.Helper()
+
+	conn := NewMockConnectionHandler(t)
+	conn.EXPECT().SetOnMessage(mock.Anything).Return()
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().SetInput(mock.Anything).Return()
+
+	return NewCLI(NewMockCommandFactory(t), conn, io.Discard, editor, NewMockFormater(t)), conn
+}
+
+func TestCLI_Connect(t *testing.T) {
+	ctx := context.Background()
+	cli, defaultConn := newTestCLI(t)
+
+	assert.ErrorIs(t, cli.connect(ctx, "api", "ws://api"), ErrConnectionsNotSupported)
+
+	factory := NewMockConnectionFactory(t)
+	cli.SetConnectionFactory(factory)
+
+	apiConn := NewMockConnectionHandler(t)
+	factory.EXPECT().Create(ctx, "api", "ws://api", mock.Anything).Return(apiConn, nil)
+	factory.EXPECT().Create(ctx, "broken", "ws://broken", mock.Anything).Return(nil, fmt.Errorf("dial error"))
+
+	require.NoError(t, cli.connect(ctx, "api", "ws://api"))
+	assert.Equal(t, apiConn, cli.activeConn())
+	assert.Equal(t, "api", cli.registry.activeName())
+
+	assert.Error(t, cli.connect(ctx, "api", "ws://api"))
+	assert.Error(t, cli.connect(ctx, "bad name", "ws://api"))
+	assert.ErrorContains(t, cli.connect(ctx, "broken", "ws://broken"), "dial error")
+
+	defaultConn.EXPECT().URL().Return("ws://default")
+	apiConn.EXPECT().URL().Return("ws://api")
+
+	assert.Equal(t, []ConnectionInfo{
+		{Name: DefaultConnection, URL: "ws://default"},
+		{Name: "api", URL: "ws://api", Active: true},
+	}, cli.connections())
+}
+
+func TestCLI_UseAndDisconnect(t *testing.T) {
+	ctx := context.Background()
+	cli, defaultConn := newTestCLI(t)
+
+	factory := NewMockConnectionFactory(t)
+	cli.SetConnectionFactory(factory)
+
+	apiConn := NewMockConnectionHandler(t)
+	factory.EXPECT().Create(ctx, "api", "ws://api", mock.Anything).Return(apiConn, nil)
+
+	require.NoError(t, cli.connect(ctx, "api", "ws://api"))
+
+	require.NoError(t, cli.use(DefaultConnection))
+	assert.Equal(t, defaultConn, cli.activeConn())
+
+	assert.ErrorIs(t, cli.use("unknown"), ErrUnknownConnection)
+	assert.ErrorIs(t, cli.disconnect("unknown"), ErrUnknownConnection)
+	assert.Error(t, cli.disconnect(DefaultConnection))
+
+	require.NoError(t, cli.use("api"))
+
+	apiConn.EXPECT().CloseWithStatus(closeNormalClosure, "disconnect").Return(nil)
+
+	require.NoError(t, cli.disconnect("api"))
+	assert.Equal(t, defaultConn, cli.activeConn())
+	assert.Equal(t, DefaultConnection, cli.registry.activeName())
+	assert.Empty(t, cli.registry.tag(DefaultConnection))
+}
+
+func TestCLI_UseWhileSending(t *testing.T) {
+	ctx := context.Background()
+	cli, defaultConn := newTestCLI(t)
+
+	factory := NewMockConnectionFactory(t)
+	cli.SetConnectionFactory(factory)
+
+	apiConn := NewMockConnectionHandler(t)
+	factory.EXPECT().Create(ctx, "api", "ws://api", mock.Anything).Return(apiConn, nil)
+
+	require.NoError(t, cli.connect(ctx, "api", "ws://api"))
+
+	defaultConn.EXPECT().Send(ctx, "hello").Return(nil).Maybe()
+	apiConn.EXPECT().Send(ctx, "hello").Return(nil).Maybe()
+
+	exCtx := newExecutionContext(ctx, cli, nil)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := range 100 {
+			name := DefaultConnection
+			if i%2 == 0 {
+				name = "api"
+			}
+
+			assert.NoError(t, cli.use(name))
+		}
+	}()
+
+	for range 100 {
+		assert.NoError(t, exCtx.SendRequest("hello"))
+	}
+
+	<-done
+}
+
+func TestCLI_ConnectionsNotSupported(t *testing.T) {
+	cli := &CLI{}
+
+	assert.ErrorIs(t, cli.connect(context.Background(), "api", "ws://api"), ErrConnectionsNotSupported)
+	assert.ErrorIs(t, cli.use("api"), ErrConnectionsNotSupported)
+	assert.ErrorIs(t, cli.disconnect("api"), ErrConnectionsNotSupported)
+	assert.Nil(t, cli.connections())
+}
+
+func TestCLI_SubscribeTagsMessages(t *testing.T) {
+	ctx := context.Background()
+	cli, _ := newTestCLI(t)
+
+	factory := NewMockConnectionFactory(t)
+	cli.SetConnectionFactory(factory)
+
+	var onMessage func(context.Context, []byte, bool)
+
+	factory.EXPECT().Create(ctx, "api", "ws://api", mock.Anything).
+		RunAndReturn(func(_ context.Context, _, _ string, cb func(context.Context, []byte, bool)) (ConnectionHandler, error) {
+			onMessage = cb
+			return NewMockConnectionHandler(t), nil
+		})
+
+	require.NoError(t, cli.connect(ctx, "api", "ws://api"))
+
+	go onMessage(ctx, []byte("hello"), false)
+
+	select {
+	case msg := <-cli.messages:
+		assert.WithinDuration(t, time.Now(), msg.Time, time.Second)
+
+		msg.Time = time.Time{}
+		assert.Equal(t, Message{Data: "hello", Type: Response, Connection: "api"}, msg)
+	case <-time.After(time.Second):
+		t.Fatal("message was not received")
+	}
+}
+
+func TestExecutionContext_FormatMessageWithConnections(t *testing.T) {
+	ctx := context.Background()
+	cli, _ := newTestCLI(t)
+
+	formater := NewMockFormater(t)
+	formater.EXPECT().FormatForFile("Response", "data").Return("data", nil)
+	formater.EXPECT().FormatForFile("Request", "req").Return("req", nil)
+	cli.formater = formater
+
+	factory := NewMockConnectionFactory(t)
+	factory.EXPECT().Create(ctx, "api", "ws://api", mock.Anything).Return(NewMockConnectionHandler(t), nil)
+	cli.SetConnectionFactory(factory)
+
+	exCtx := newExecutionContext(ctx, cli, nil)
+
+	output, err := exCtx.FormatMessage(Message{Type: Response, Data: "data"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "data", output)
+
+	require.NoError(t, cli.connect(ctx, "api", "ws://api"))
+
+	output, err = exCtx.FormatMessage(Message{Type: Response, Data: "data", Connection: DefaultConnection}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "[default] data", output)
+
+	output, err = exCtx.FormatMessage(Message{Type: Request, Data: "req"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "[api] req", output)
+}
+
+func TestExecutionContext_WaitForResponseSkipsInactiveConnections(t *testing.T) {
+	ctx := context.Background()
+	cli, _ := newTestCLI(t)
+
+	factory := NewMockConnectionFactory(t)
+	factory.EXPECT().Create(ctx, "api", "ws://api", mock.Anything).Return(NewMockConnectionHandler(t), nil)
+	cli.SetConnectionFactory(factory)
+
+	require.NoError(t, cli.connect(ctx, "api", "ws://api"))
+
+	printCmd := NewMockExecuter(t)
+	cmdFactory := NewMockCommandFactory(t)
+	cmdFactory.EXPECT().CreatePrint(mock.MatchedBy(func(msg Message) bool {
+		return msg.Data == "other" && msg.Connection == DefaultConnection
+	})).Return(printCmd)
+	cli.cmdFactory = cmdFactory
+
+	go func() {
+		cli.messages <- Message{Type: Response, Data: "other", Connection: DefaultConnection}
+		cli.messages <- Message{Type: Response, Data: "mine", Connection: "api"}
+	}()
+
+	exCtx := newExecutionContext(ctx, cli, nil)
+
+	msg, err := exCtx.WaitForResponse(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "mine", msg.Data)
+	assert.Equal(t, []Executer{printCmd}, exCtx.pending)
+}
+
+func TestExecutionContext_WaitForResponseKeepsAllOtherMessages(t *testing.T) {
+	ctx := context.Background()
+	cli, _ := newTestCLI(t)
+
+	factory := NewMockConnectionFactory(t)
+	factory.EXPECT().Create(ctx, "api", "ws://api", mock.Anything).Return(NewMockConnectionHandler(t), nil)
+	cli.SetConnectionFactory(factory)
+
+	require.NoError(t, cli.connect(ctx, "api", "ws://api"))
+
+	cmdFactory := NewMockCommandFactory(t)
+	cmdFactory.EXPECT().CreatePrint(mock.Anything).Return(NewMockExecuter(t))
+	cli.cmdFactory = cmdFactory
+
+	// More messages than the command queue holds arrive from the inactive connection.
+	count := CommandsLimit * 2
+
+	go func() {
+		for range count {
+			cli.messages <- Message{Type: Response, Data: "other", Connection: DefaultConnection}
+		}
+
+		cli.messages <- Message{Type: Response, Data: "mine", Connection: "api"}
+	}()
+
+	exCtx := newExecutionContext(ctx, cli, nil)
+
+	msg, err := exCtx.WaitForResponse(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "mine", msg.Data)
+	assert.Len(t, exCtx.pending, count)
+	assert.Empty(t, cli.commands)
+}
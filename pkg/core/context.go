@@ -9,10 +9,15 @@ import (
 	"github.com/fatih/color"
 )
 
+// executionContext is the context commands of the CLI are executed in.
+// Commands queued while another command runs, like printing messages of other connections and
+// the OnReconnect commands, are kept in pending and executed when the running command completes.
 type executionContext struct {
-	cli        *CLI
-	outputFile io.Writer
-	ctx        context.Context
+	cli         *CLI
+	outputFile  io.Writer
+	ctx         context.Context
+	pending     []Executer
+	onReconnect []Executer
 }
 
 // newExecutionContext creates a new executionContext instance for the provided CLI and output file.
@@ -34,6 +39,15 @@ func (c *executionContext) Print(data string, attr ...color.Attribute) error {
 	return err
 }
 
+// PrintInfo writes the given data, like command results and statuses, with optional color attributes.
+// It takes data of type string, which is the text to be printed, and attr, variadic arguments of type color.Attribute for styling.
+// The data is written to the info output of the CLI while the output format is printed without headers.
+// It returns an error if writing fails.
+func (c *executionContext) PrintInfo(data string, attr ...color.Attribute) error {
+	_, err := color.New(attr...).Fprint(c.cli.info(), data)
+	return err
+}
+
 // PrintToFile writes the given data to the specified output file in the execution context.
 // It takes data of type string, which is the content to be written to the file.
 // It returns an error if writing to the output file fails or if there is an I/O issue.
@@ -50,36 +64,135 @@ func (c *executionContext) PrintToFile(data string) error {
 // FormatMessage formats a Message based on its type and data.
 // It takes msg of type Message and noColor of type bool to control if color formatting is applied.
 // It returns a string containing the formatted message and an error if message formatting fails.
+// When the session has several connections, the message is prefixed with the name of its connection,
+// requests without a connection name are attributed to the active connection.
+// Messages in output formats printed without headers are not prefixed to keep them machine-readable.
 func (c *executionContext) FormatMessage(msg Message, noColor bool) (string, error) {
-	if noColor {
-		return c.cli.formater.FormatForFile(msg.Type.String(), msg.Data)
+	var (
+		output string
+		err    error
+	)
+
+	timed, isTimed := c.cli.formater.(TimedFormater)
+	isTimed = isTimed && !msg.Time.IsZero()
+
+	switch {
+	case isTimed && noColor:
+		output, err = timed.FormatForFileAt(msg.Type.String(), msg.Data, msg.Time)
+	case isTimed:
+		output, err = timed.FormatMessageAt(msg.Type.String(), msg.Data, msg.Time)
+	case noColor:
+		output, err = c.cli.formater.FormatForFile(msg.Type.String(), msg.Data)
+	default:
+		output, err = c.cli.formater.FormatMessage(msg.Type.String(), msg.Data)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if !c.PrintHeaders() {
+		return output, nil
+	}
+
+	name := msg.Connection
+	if name == "" {
+		name = c.cli.registry.tag(c.cli.registry.activeName())
+	}
+
+	if name == "" {
+		return output, nil
+	}
+
+	prefix := "[" + name + "] "
+	if !noColor {
+		prefix = color.New(connectionColor(name)).Sprint(prefix)
 	}
 
-	return c.cli.formater.FormatMessage(msg.Type.String(), msg.Data)
+	return prefix + output, nil
+}
+
+// SetFormat changes the output format of the session.
+// It takes name of type string, the name of the output format.
+// It returns an error if the format is not supported or the formater does not allow changing the format.
+func (c *executionContext) SetFormat(name string) error {
+	switcher, ok := c.cli.formater.(FormatSwitcher)
+	if !ok {
+		return fmt.Errorf("output format cannot be changed")
+	}
+
+	return switcher.SetFormat(name)
+}
+
+// PrintHeaders reports whether the direction headers are printed with messages in the current output format.
+func (c *executionContext) PrintHeaders() bool {
+	return c.cli.headers()
 }
 
 // SendRequest sends a request message through the execution context's WebSocket connection.
 // It takes req of type string, which represents the request to be sent.
 // It returns an error if the WebSocket connection fails to send the request.
 func (c *executionContext) SendRequest(req string) error {
-	return c.cli.wsConn.Send(c.ctx, req)
+	return c.cli.activeConn().Send(c.ctx, req)
 }
 
 // SendBinaryRequest sends binary data as a request through the execution context's WebSocket connection.
 // It takes data of type []byte, which represents the binary data to be sent.
 // It returns an error if the WebSocket connection fails to send the binary data.
 func (c *executionContext) SendBinaryRequest(data []byte) error {
-	return c.cli.wsConn.SendBinary(c.ctx, data)
+	return c.cli.activeConn().SendBinary(c.ctx, data)
 }
 
 // Ping sends a ping message through the execution context's WebSocket connection.
 // It returns an error if the WebSocket connection fails to send the ping.
 func (c *executionContext) Ping() error {
-	return c.cli.wsConn.Ping(c.ctx)
+	return c.cli.activeConn().Ping(c.ctx)
+}
+
+// PingStats returns the round-trip time statistics of pings sent over the execution context's WebSocket connection.
+func (c *executionContext) PingStats() PingStats {
+	return c.cli.activeConn().PingStats()
+}
+
+// Metrics returns the message statistics of the execution context's WebSocket connection.
+func (c *executionContext) Metrics() Metrics {
+	return c.cli.activeConn().Metrics()
+}
+
+// CloseConnection closes the execution context's WebSocket connection with the provided status code and reason.
+// It takes code of type int, a WebSocket close status code, and reason of type string.
+// It returns an error if the connection is not established or the close handshake fails.
+func (c *executionContext) CloseConnection(code int, reason string) error {
+	return c.cli.activeConn().CloseWithStatus(code, reason)
+}
+
+// Connect opens a new named connection to the provided url and makes it active.
+// It takes name of type string, the name of the connection, and url of type string, the WebSocket server address.
+// It returns an error if the name is invalid or already taken, or the connection fails.
+func (c *executionContext) Connect(name, url string) error {
+	return c.cli.connect(c.ctx, name, url)
+}
+
+// UseConnection makes the named connection active, so that requests are sent to it.
+// It returns an error if there is no connection with the provided name.
+func (c *executionContext) UseConnection(name string) error {
+	return c.cli.use(name)
+}
+
+// Disconnect closes the named connection and removes it from the session.
+// It returns an error if there is no connection with the provided name, it is the default connection, or closing fails.
+func (c *executionContext) Disconnect(name string) error {
+	return c.cli.disconnect(name)
+}
+
+// Connections returns the connections of the session in the order they were created.
+func (c *executionContext) Connections() []ConnectionInfo {
+	return c.cli.connections()
 }
 
 // WaitForResponse waits for a response message from the CLI within a specified timeout period.
 // It takes timeout of type time.Duration to define the maximum wait time. If timeout is 0, it waits indefinitely.
+// Messages of other than the active connection are queued for printing and do not satisfy the wait.
 // It returns a Message containing the received data and an error if the context deadline exceeds or other issues occur.
 func (c *executionContext) WaitForResponse(timeout time.Duration) (Message, error) {
 	ctx := c.ctx
@@ -91,33 +204,120 @@ func (c *executionContext) WaitForResponse(timeout time.Duration) (Message, erro
 		defer cancel()
 	}
 
-	select {
-	case msg := <-c.cli.messages:
-		return msg, nil
-	case <-ctx.Done():
-		return Message{}, ctx.Err()
+	for {
+		select {
+		case msg := <-c.cli.messages:
+			if msg.Connection == "" || msg.Connection == c.cli.registry.activeName() {
+				return msg, nil
+			}
+
+			c.deferPrint(msg)
+		case status := <-c.cli.statuses:
+			c.onStatus(status)
+		case <-c.cli.done:
+			return Message{}, ErrInterrupted
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		}
+	}
+}
+
+// deferPrint queues printing of the message received while waiting for a response of another connection,
+// the message is printed when the running command completes.
+func (c *executionContext) deferPrint(msg Message) {
+	c.pending = append(c.pending, c.cli.cmdFactory.CreatePrint(msg))
+}
+
+// onStatus prints the connection status and, on reconnection, queues the OnReconnect commands.
+func (c *executionContext) onStatus(status ConnectionStatus) {
+	_, _ = color.New(color.FgYellow).Fprintf(c.cli.info(), "[%s]\n", status.State)
+
+	if status.Reconnected {
+		c.pending = append(c.pending, c.onReconnect...)
+	}
+}
+
+// execute runs the command and the commands it returns until the chain is completed.
+// It returns an error if any command of the chain fails.
+func (c *executionContext) execute(cmd Executer) error {
+	var err error
+
+	for cmd != nil {
+		cmd, err = cmd.Execute(c)
+		if err != nil {
+			return fmt.Errorf("failed to execute command: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// executePending runs the commands queued while other commands were running, in the order they were queued.
+// It returns an error if any command fails.
+func (c *executionContext) executePending() error {
+	for len(c.pending) > 0 {
+		cmd := c.pending[0]
+		c.pending = c.pending[1:]
+
+		if err := c.execute(cmd); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 // EditorMode allows the user to edit text in an editor with a provided initial buffer.
 // It takes initBuffer of type string, which initializes the editor with existing content.
 // It returns a string containing the final edited content and an error if the editing process fails.
 func (c *executionContext) EditorMode(initBuffer string) (string, error) {
-	return c.cli.editor.Edit(c.ctx, initBuffer)
+	return c.edit(func(ctx context.Context) (string, error) { return c.cli.editor.Edit(ctx, initBuffer) })
 }
 
 // CommandMode initiates command mode in the editor with the provided initial buffer.
 // It takes initBuffer of type string, which is the input buffer to initialize the command mode.
 // It returns a string representing the final buffer after editing and an error if command mode fails.
 func (c *executionContext) CommandMode(initBuffer string) (string, error) {
-	return c.cli.editor.CommandMode(c.ctx, initBuffer)
+	return c.edit(func(ctx context.Context) (string, error) { return c.cli.editor.CommandMode(ctx, initBuffer) })
 }
 
 // BinaryMode initiates binary edit mode in the editor with the provided initial buffer.
 // It takes initBuffer of type string, which is the input buffer to initialize the binary edit mode.
 // It returns a string representing the final buffer after editing and an error if binary edit mode fails.
 func (c *executionContext) BinaryMode(initBuffer string) (string, error) {
-	return c.cli.editor.BinaryEdit(c.ctx, initBuffer)
+	return c.edit(func(ctx context.Context) (string, error) { return c.cli.editor.BinaryEdit(ctx, initBuffer) })
+}
+
+// edit runs the editor and receives connection statuses reported while the user edits the text.
+// The statuses are printed after the editor is closed, so that they do not break the edited text.
+// It returns the edited text and an error if editing fails.
+func (c *executionContext) edit(editor func(ctx context.Context) (string, error)) (string, error) {
+	type result struct {
+		err  error
+		text string
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		text, err := editor(c.ctx)
+		done <- result{text: text, err: err}
+	}()
+
+	var statuses []ConnectionStatus
+
+	for {
+		select {
+		case res := <-done:
+			for _, status := range statuses {
+				c.onStatus(status)
+			}
+
+			return res.text, res.err
+		case status := <-c.cli.statuses:
+			statuses = append(statuses, status)
+		}
+	}
 }
 
 // CreateCommand creates an Executer from a raw command string.
@@ -126,3 +326,8 @@ func (c *executionContext) BinaryMode(initBuffer string) (string, error) {
 func (c *executionContext) CreateCommand(raw string) (Executer, error) {
 	return c.cli.cmdFactory.Create(raw)
 }
+
+// Protocol returns the application protocol of the active connection, or nil if it exchanges raw messages.
+func (c *executionContext) Protocol() Protocol {
+	return c.cli.activeConn().Protocol()
+}
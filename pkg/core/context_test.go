@@ -5,11 +5,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewExecutionContext(t *testing.T) {
@@ -138,6 +140,26 @@ func TestExecutionContext_Print(t *testing.T) {
 	}
 }
 
+func TestExecutionContext_PrintInfo(t *testing.T) {
+	formater := &switchingFormater{MockFormater: NewMockFormater(t), name: "pretty"}
+	output, info := &bytes.Buffer{}, &bytes.Buffer{}
+	cli := &CLI{output: output, formater: formater}
+	cli.SetInfoOutput(info)
+
+	ec := &executionContext{ctx: t.Context(), cli: cli}
+
+	assert.NoError(t, ec.PrintInfo("[connected]\n"))
+	assert.Equal(t, "[connected]\n", output.String(), "statuses are printed with messages in headered formats")
+	assert.Empty(t, info.String())
+
+	output.Reset()
+
+	assert.NoError(t, ec.SetFormat("raw"))
+	assert.NoError(t, ec.PrintInfo("[disconnected]\n"))
+	assert.Empty(t, output.String(), "statuses are kept out of machine-readable output")
+	assert.Equal(t, "[disconnected]\n", info.String())
+}
+
 func TestExecutionContext_EditorMode(t *testing.T) {
 	mockEditor := NewMockEditor(t)
 	ctx := context.Background()
@@ -429,6 +451,43 @@ func TestExecutionContext_FormatMessage(t *testing.T) {
 	}
 }
 
+// timedFormater is a formater printing the time of the message.
+type timedFormater struct {
+	*MockFormater
+}
+
+func (f *timedFormater) FormatMessageAt(msgType, msgData string, at time.Time) (string, error) {
+	return at.Format(time.RFC3339) + " " + msgType + " " + msgData, nil
+}
+
+func (f *timedFormater) FormatForFileAt(msgType, msgData string, at time.Time) (string, error) {
+	return at.Format(time.RFC3339) + " file " + msgType + " " + msgData, nil
+}
+
+func TestExecutionContext_FormatMessageTime(t *testing.T) {
+	formater := NewMockFormater(t)
+	formater.EXPECT().FormatMessage("Response", "untimed").Return("now Response untimed", nil)
+
+	exCtx := &executionContext{
+		ctx: t.Context(),
+		cli: &CLI{formater: &timedFormater{MockFormater: formater}},
+	}
+
+	received := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	output, err := exCtx.FormatMessage(Message{Type: Response, Data: "data", Time: received}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-01T10:30:00Z Response data", output)
+
+	output, err = exCtx.FormatMessage(Message{Type: Response, Data: "data", Time: received}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-05-01T10:30:00Z file Response data", output)
+
+	output, err = exCtx.FormatMessage(Message{Type: Response, Data: "untimed"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "now Response untimed", output)
+}
+
 func TestExecutionContext_Ping(t *testing.T) {
 	mockWsConn := NewMockConnectionHandler(t)
 
@@ -446,6 +505,52 @@ func TestExecutionContext_Ping(t *testing.T) {
 	assert.NoError(t, err, "Expected no error on Ping")
 }
 
+func TestExecutionContext_PingStats(t *testing.T) {
+	mockWsConn := NewMockConnectionHandler(t)
+
+	expected := PingStats{Count: 1, Min: time.Millisecond}
+	mockWsConn.EXPECT().PingStats().Return(expected)
+
+	excCtx := &executionContext{
+		ctx: t.Context(),
+		cli: &CLI{
+			wsConn: mockWsConn,
+		},
+	}
+
+	assert.Equal(t, expected, excCtx.PingStats())
+}
+
+func TestExecutionContext_Metrics(t *testing.T) {
+	mockWsConn := NewMockConnectionHandler(t)
+
+	expected := Metrics{Received: DirectionMetrics{Messages: 3, Bytes: 42}}
+	mockWsConn.EXPECT().Metrics().Return(expected)
+
+	excCtx := &executionContext{
+		ctx: t.Context(),
+		cli: &CLI{
+			wsConn: mockWsConn,
+		},
+	}
+
+	assert.Equal(t, expected, excCtx.Metrics())
+}
+
+func TestExecutionContext_CloseConnection(t *testing.T) {
+	mockWsConn := NewMockConnectionHandler(t)
+	mockWsConn.EXPECT().CloseWithStatus(1008, "policy violation").Return(assert.AnError)
+
+	excCtx := &executionContext{
+		ctx: t.Context(),
+		cli: &CLI{
+			wsConn: mockWsConn,
+		},
+	}
+
+	assert.ErrorIs(t, excCtx.CloseConnection(1008, "policy violation"), assert.AnError)
+}
+
 func TestExecutionContext_SendBinaryRequest(t *testing.T) {
 	t.Parallel()
 
@@ -537,3 +642,97 @@ func TestExecutionContext_BinaryMode(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutionContext_Protocol(t *testing.T) {
+	mockWsConn := NewMockConnectionHandler(t)
+	proto := NewMockProtocol(t)
+
+	mockWsConn.EXPECT().Protocol().Return(proto)
+
+	excCtx := &executionContext{
+		ctx: t.Context(),
+		cli: &CLI{
+			wsConn: mockWsConn,
+		},
+	}
+
+	assert.Equal(t, proto, excCtx.Protocol())
+}
+
+// switchingFormater is a formater that allows changing the output format, headers are printed only in the pretty format.
+type switchingFormater struct {
+	*MockFormater
+	name string
+}
+
+func (f *switchingFormater) SetFormat(name string) error {
+	if name != "pretty" && name != "raw" {
+		return fmt.Errorf("unsupported output format: %s", name)
+	}
+
+	f.name = name
+
+	return nil
+}
+
+func (f *switchingFormater) Headers() bool {
+	return f.name == "pretty"
+}
+
+func TestExecutionContext_SetFormat(t *testing.T) {
+	formater := &switchingFormater{MockFormater: NewMockFormater(t), name: "pretty"}
+	excCtx := &executionContext{ctx: t.Context(), cli: &CLI{formater: formater}}
+
+	assert.True(t, excCtx.PrintHeaders())
+
+	assert.NoError(t, excCtx.SetFormat("raw"))
+	assert.False(t, excCtx.PrintHeaders())
+
+	assert.EqualError(t, excCtx.SetFormat("xml"), "unsupported output format: xml")
+	assert.Equal(t, "raw", formater.name)
+
+	formater.EXPECT().FormatMessage("Response", "data").Return("data", nil)
+
+	output, err := excCtx.FormatMessage(Message{Type: Response, Data: "data", Connection: "api"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", output, "messages without headers are not prefixed with the connection name")
+}
+
+func TestExecutionContext_SetFormat_NotSupported(t *testing.T) {
+	excCtx := &executionContext{ctx: t.Context(), cli: &CLI{formater: NewMockFormater(t)}}
+
+	assert.EqualError(t, excCtx.SetFormat("raw"), "output format cannot be changed")
+	assert.True(t, excCtx.PrintHeaders())
+}
+
+func TestExecutionContext_EditorModeStatuses(t *testing.T) {
+	ctx := context.Background()
+	cli, _ := newTestCLI(t)
+
+	output := &strings.Builder{}
+	cli.output = output
+
+	editor := NewMockEditor(t)
+	editor.EXPECT().Edit(ctx, "").RunAndReturn(func(context.Context, string) (string, error) {
+		cli.OnConnectionStatus(ctx, ConnectionStatus{State: "connected", Reconnected: true})
+
+		require.Eventually(t, func() bool { return len(cli.statuses) == 0 }, time.Second, time.Millisecond)
+		assert.Empty(t, output.String(), "statuses are printed after the editor is closed")
+
+		return "text", nil
+	})
+
+	cli.editor = editor
+
+	reconnectCmd := NewMockExecuter(t)
+
+	exCtx := newExecutionContext(ctx, cli, nil)
+	exCtx.onReconnect = []Executer{reconnectCmd}
+
+	text, err := exCtx.EditorMode("")
+	require.NoError(t, err)
+
+	assert.Equal(t, "text", text)
+	assert.Equal(t, "[connected]\n", output.String())
+	assert.Equal(t, []Executer{reconnectCmd}, exCtx.pending)
+}
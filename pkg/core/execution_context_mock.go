@@ -80,6 +80,53 @@ func (_c *MockExecutionContext_BinaryMode_Call) RunAndReturn(run func(string) (s
 	return _c
 }
 
+// CloseConnection provides a mock function with given fields: code, reason
+func (_m *MockExecutionContext) CloseConnection(code int, reason string) error {
+	ret := _m.Called(code, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloseConnection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, string) error); ok {
+		r0 = rf(code, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_CloseConnection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CloseConnection'
+type MockExecutionContext_CloseConnection_Call struct {
+	*mock.Call
+}
+
+// CloseConnection is a helper method to define mock.On call
+//   - code int
+//   - reason string
+func (_e *MockExecutionContext_Expecter) CloseConnection(code interface{}, reason interface{}) *MockExecutionContext_CloseConnection_Call {
+	return &MockExecutionContext_CloseConnection_Call{Call: _e.mock.On("CloseConnection", code, reason)}
+}
+
+func (_c *MockExecutionContext_CloseConnection_Call) Run(run func(code int, reason string)) *MockExecutionContext_CloseConnection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_CloseConnection_Call) Return(_a0 error) *MockExecutionContext_CloseConnection_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_CloseConnection_Call) RunAndReturn(run func(int, string) error) *MockExecutionContext_CloseConnection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CommandMode provides a mock function with given fields: initBuffer
 func (_m *MockExecutionContext) CommandMode(initBuffer string) (string, error) {
 	ret := _m.Called(initBuffer)
@@ -136,6 +183,100 @@ func (_c *MockExecutionContext_CommandMode_Call) RunAndReturn(run func(string) (
 	return _c
 }
 
+// Connect provides a mock function with given fields: name, url
+func (_m *MockExecutionContext) Connect(name string, url string) error {
+	ret := _m.Called(name, url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Connect")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, url)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Connect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Connect'
+type MockExecutionContext_Connect_Call struct {
+	*mock.Call
+}
+
+// Connect is a helper method to define mock.On call
+//   - name string
+//   - url string
+func (_e *MockExecutionContext_Expecter) Connect(name interface{}, url interface{}) *MockExecutionContext_Connect_Call {
+	return &MockExecutionContext_Connect_Call{Call: _e.mock.On("Connect", name, url)}
+}
+
+func (_c *MockExecutionContext_Connect_Call) Run(run func(name string, url string)) *MockExecutionContext_Connect_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Connect_Call) Return(_a0 error) *MockExecutionContext_Connect_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Connect_Call) RunAndReturn(run func(string, string) error) *MockExecutionContext_Connect_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Connections provides a mock function with no fields
+func (_m *MockExecutionContext) Connections() []ConnectionInfo {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Connections")
+	}
+
+	var r0 []ConnectionInfo
+	if rf, ok := ret.Get(0).(func() []ConnectionInfo); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]ConnectionInfo)
+		}
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Connections_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Connections'
+type MockExecutionContext_Connections_Call struct {
+	*mock.Call
+}
+
+// Connections is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Connections() *MockExecutionContext_Connections_Call {
+	return &MockExecutionContext_Connections_Call{Call: _e.mock.On("Connections")}
+}
+
+func (_c *MockExecutionContext_Connections_Call) Run(run func()) *MockExecutionContext_Connections_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Connections_Call) Return(_a0 []ConnectionInfo) *MockExecutionContext_Connections_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Connections_Call) RunAndReturn(run func() []ConnectionInfo) *MockExecutionContext_Connections_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateCommand provides a mock function with given fields: raw
 func (_m *MockExecutionContext) CreateCommand(raw string) (Executer, error) {
 	ret := _m.Called(raw)
@@ -194,6 +335,52 @@ func (_c *MockExecutionContext_CreateCommand_Call) RunAndReturn(run func(string)
 	return _c
 }
 
+// Disconnect provides a mock function with given fields: name
+func (_m *MockExecutionContext) Disconnect(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Disconnect")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Disconnect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Disconnect'
+type MockExecutionContext_Disconnect_Call struct {
+	*mock.Call
+}
+
+// Disconnect is a helper method to define mock.On call
+//   - name string
+func (_e *MockExecutionContext_Expecter) Disconnect(name interface{}) *MockExecutionContext_Disconnect_Call {
+	return &MockExecutionContext_Disconnect_Call{Call: _e.mock.On("Disconnect", name)}
+}
+
+func (_c *MockExecutionContext_Disconnect_Call) Run(run func(name string)) *MockExecutionContext_Disconnect_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Disconnect_Call) Return(_a0 error) *MockExecutionContext_Disconnect_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Disconnect_Call) RunAndReturn(run func(string) error) *MockExecutionContext_Disconnect_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // EditorMode provides a mock function with given fields: initBuffer
 func (_m *MockExecutionContext) EditorMode(initBuffer string) (string, error) {
 	ret := _m.Called(initBuffer)
@@ -307,6 +494,51 @@ func (_c *MockExecutionContext_FormatMessage_Call) RunAndReturn(run func(Message
 	return _c
 }
 
+// Metrics provides a mock function with no fields
+func (_m *MockExecutionContext) Metrics() Metrics {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Metrics")
+	}
+
+	var r0 Metrics
+	if rf, ok := ret.Get(0).(func() Metrics); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(Metrics)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Metrics_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Metrics'
+type MockExecutionContext_Metrics_Call struct {
+	*mock.Call
+}
+
+// Metrics is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Metrics() *MockExecutionContext_Metrics_Call {
+	return &MockExecutionContext_Metrics_Call{Call: _e.mock.On("Metrics")}
+}
+
+func (_c *MockExecutionContext_Metrics_Call) Run(run func()) *MockExecutionContext_Metrics_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Metrics_Call) Return(_a0 Metrics) *MockExecutionContext_Metrics_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Metrics_Call) RunAndReturn(run func() Metrics) *MockExecutionContext_Metrics_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Ping provides a mock function with no fields
 func (_m *MockExecutionContext) Ping() error {
 	ret := _m.Called()
@@ -352,6 +584,51 @@ func (_c *MockExecutionContext_Ping_Call) RunAndReturn(run func() error) *MockEx
 	return _c
 }
 
+// PingStats provides a mock function with no fields
+func (_m *MockExecutionContext) PingStats() PingStats {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PingStats")
+	}
+
+	var r0 PingStats
+	if rf, ok := ret.Get(0).(func() PingStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(PingStats)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_PingStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PingStats'
+type MockExecutionContext_PingStats_Call struct {
+	*mock.Call
+}
+
+// PingStats is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) PingStats() *MockExecutionContext_PingStats_Call {
+	return &MockExecutionContext_PingStats_Call{Call: _e.mock.On("PingStats")}
+}
+
+func (_c *MockExecutionContext_PingStats_Call) Run(run func()) *MockExecutionContext_PingStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_PingStats_Call) Return(_a0 PingStats) *MockExecutionContext_PingStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_PingStats_Call) RunAndReturn(run func() PingStats) *MockExecutionContext_PingStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Print provides a mock function with given fields: data, attr
 func (_m *MockExecutionContext) Print(data string, attr ...color.Attribute) error {
 	_va := make([]interface{}, len(attr))
@@ -413,6 +690,112 @@ func (_c *MockExecutionContext_Print_Call) RunAndReturn(run func(string, ...colo
 	return _c
 }
 
+// PrintHeaders provides a mock function with no fields
+func (_m *MockExecutionContext) PrintHeaders() bool {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PrintHeaders")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_PrintHeaders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PrintHeaders'
+type MockExecutionContext_PrintHeaders_Call struct {
+	*mock.Call
+}
+
+// PrintHeaders is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) PrintHeaders() *MockExecutionContext_PrintHeaders_Call {
+	return &MockExecutionContext_PrintHeaders_Call{Call: _e.mock.On("PrintHeaders")}
+}
+
+func (_c *MockExecutionContext_PrintHeaders_Call) Run(run func()) *MockExecutionContext_PrintHeaders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_PrintHeaders_Call) Return(_a0 bool) *MockExecutionContext_PrintHeaders_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_PrintHeaders_Call) RunAndReturn(run func() bool) *MockExecutionContext_PrintHeaders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PrintInfo provides a mock function with given fields: data, attr
+func (_m *MockExecutionContext) PrintInfo(data string, attr ...color.Attribute) error {
+	_va := make([]interface{}, len(attr))
+	for _i := range attr {
+		_va[_i] = attr[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, data)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PrintInfo")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, ...color.Attribute) error); ok {
+		r0 = rf(data, attr...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_PrintInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PrintInfo'
+type MockExecutionContext_PrintInfo_Call struct {
+	*mock.Call
+}
+
+// PrintInfo is a helper method to define mock.On call
+//   - data string
+//   - attr ...color.Attribute
+func (_e *MockExecutionContext_Expecter) PrintInfo(data interface{}, attr ...interface{}) *MockExecutionContext_PrintInfo_Call {
+	return &MockExecutionContext_PrintInfo_Call{Call: _e.mock.On("PrintInfo",
+		append([]interface{}{data}, attr...)...)}
+}
+
+func (_c *MockExecutionContext_PrintInfo_Call) Run(run func(data string, attr ...color.Attribute)) *MockExecutionContext_PrintInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]color.Attribute, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(color.Attribute)
+			}
+		}
+		run(args[0].(string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_PrintInfo_Call) Return(_a0 error) *MockExecutionContext_PrintInfo_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_PrintInfo_Call) RunAndReturn(run func(string, ...color.Attribute) error) *MockExecutionContext_PrintInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // PrintToFile provides a mock function with given fields: data
 func (_m *MockExecutionContext) PrintToFile(data string) error {
 	ret := _m.Called(data)
@@ -459,6 +842,53 @@ func (_c *MockExecutionContext_PrintToFile_Call) RunAndReturn(run func(string) e
 	return _c
 }
 
+// Protocol provides a mock function with no fields
+func (_m *MockExecutionContext) Protocol() Protocol {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Protocol")
+	}
+
+	var r0 Protocol
+	if rf, ok := ret.Get(0).(func() Protocol); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Protocol)
+		}
+	}
+
+	return r0
+}
+
+// MockExecutionContext_Protocol_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Protocol'
+type MockExecutionContext_Protocol_Call struct {
+	*mock.Call
+}
+
+// Protocol is a helper method to define mock.On call
+func (_e *MockExecutionContext_Expecter) Protocol() *MockExecutionContext_Protocol_Call {
+	return &MockExecutionContext_Protocol_Call{Call: _e.mock.On("Protocol")}
+}
+
+func (_c *MockExecutionContext_Protocol_Call) Run(run func()) *MockExecutionContext_Protocol_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_Protocol_Call) Return(_a0 Protocol) *MockExecutionContext_Protocol_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_Protocol_Call) RunAndReturn(run func() Protocol) *MockExecutionContext_Protocol_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SendBinaryRequest provides a mock function with given fields: data
 func (_m *MockExecutionContext) SendBinaryRequest(data []byte) error {
 	ret := _m.Called(data)
@@ -551,6 +981,98 @@ func (_c *MockExecutionContext_SendRequest_Call) RunAndReturn(run func(string) e
 	return _c
 }
 
+// SetFormat provides a mock function with given fields: name
+func (_m *MockExecutionContext) SetFormat(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFormat")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_SetFormat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFormat'
+type MockExecutionContext_SetFormat_Call struct {
+	*mock.Call
+}
+
+// SetFormat is a helper method to define mock.On call
+//   - name string
+func (_e *MockExecutionContext_Expecter) SetFormat(name interface{}) *MockExecutionContext_SetFormat_Call {
+	return &MockExecutionContext_SetFormat_Call{Call: _e.mock.On("SetFormat", name)}
+}
+
+func (_c *MockExecutionContext_SetFormat_Call) Run(run func(name string)) *MockExecutionContext_SetFormat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_SetFormat_Call) Return(_a0 error) *MockExecutionContext_SetFormat_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_SetFormat_Call) RunAndReturn(run func(string) error) *MockExecutionContext_SetFormat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UseConnection provides a mock function with given fields: name
+func (_m *MockExecutionContext) UseConnection(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseConnection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockExecutionContext_UseConnection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseConnection'
+type MockExecutionContext_UseConnection_Call struct {
+	*mock.Call
+}
+
+// UseConnection is a helper method to define mock.On call
+//   - name string
+func (_e *MockExecutionContext_Expecter) UseConnection(name interface{}) *MockExecutionContext_UseConnection_Call {
+	return &MockExecutionContext_UseConnection_Call{Call: _e.mock.On("UseConnection", name)}
+}
+
+func (_c *MockExecutionContext_UseConnection_Call) Run(run func(name string)) *MockExecutionContext_UseConnection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockExecutionContext_UseConnection_Call) Return(_a0 error) *MockExecutionContext_UseConnection_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockExecutionContext_UseConnection_Call) RunAndReturn(run func(string) error) *MockExecutionContext_UseConnection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // WaitForResponse provides a mock function with given fields: timeout
 func (_m *MockExecutionContext) WaitForResponse(timeout time.Duration) (Message, error) {
 	ret := _m.Called(timeout)
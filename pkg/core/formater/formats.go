@@ -0,0 +1,92 @@
+package formater
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+// Names of the supported output formats.
+const (
+	FormatPretty  = "pretty"
+	FormatRaw     = "raw"
+	FormatNDJSON  = "ndjson"
+	FormatYAML    = "yaml"
+	FormatHexdump = "hexdump"
+)
+
+// Formats lists the names of the supported output formats.
+var Formats = []string{FormatPretty, FormatRaw, FormatNDJSON, FormatYAML, FormatHexdump}
+
+// New creates the formater of the output format with the given name, an empty name selects the pretty format.
+// It returns an error if the format is not supported.
+func New(name string) (core.Formater, error) {
+	switch name {
+	case "", FormatPretty:
+		return NewFormat(), nil
+	case FormatRaw:
+		return NewRawFormat(), nil
+	case FormatNDJSON:
+		return NewNDJSONFormat(), nil
+	case FormatYAML:
+		return NewYAMLFormat(), nil
+	case FormatHexdump:
+		return NewHexdumpFormat(), nil
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s, supported formats: %s", name, strings.Join(Formats, ", "))
+	}
+}
+
+// envelope describes a message in the ndjson and yaml formats.
+// Size is the length of the payload in bytes, for binary messages it is the length of the decoded data.
+type envelope struct {
+	Data      any    `json:"data" yaml:"data"`
+	Direction string `json:"direction" yaml:"direction"`
+	Type      string `json:"type" yaml:"type"`
+	Timestamp string `json:"timestamp" yaml:"timestamp"`
+	Size      int    `json:"size" yaml:"size"`
+}
+
+// newEnvelope creates the envelope of the message with the given type and data received or sent at the given time.
+// Binary messages are kept encoded in base64.
+func newEnvelope(msgType, msgData string, now time.Time) (envelope, error) {
+	env := envelope{
+		Direction: "out",
+		Type:      "text",
+		Timestamp: now.UTC().Format(time.RFC3339Nano),
+		Data:      msgData,
+		Size:      len(msgData),
+	}
+
+	switch msgType {
+	case "Request":
+	case "Response":
+		env.Direction = "in"
+	case "RequestBinary":
+		env.Type = "binary"
+	case "ResponseBinary":
+		env.Direction = "in"
+		env.Type = "binary"
+	default:
+		return envelope{}, fmt.Errorf("unexpected message type: %s", msgType)
+	}
+
+	if env.Type == "binary" {
+		data, err := base64.StdEncoding.DecodeString(msgData)
+		if err != nil {
+			return envelope{}, fmt.Errorf("invalid binary message: %w", err)
+		}
+
+		env.Size = len(data)
+	}
+
+	return env, nil
+}
+
+// isBinary reports whether the message type is a binary message.
+func isBinary(msgType string) bool {
+	return msgType == "RequestBinary" || msgType == "ResponseBinary"
+}
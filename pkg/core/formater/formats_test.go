@@ -0,0 +1,245 @@
+package formater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixedTime() time.Time {
+	return time.Date(2024, 5, 1, 10, 30, 0, 500, time.UTC)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		expected any
+		name     string
+	}{
+		{name: "", expected: &Format{}},
+		{name: FormatPretty, expected: &Format{}},
+		{name: FormatRaw, expected: &RawFormat{}},
+		{name: FormatNDJSON, expected: &NDJSONFormat{}},
+		{name: FormatYAML, expected: &YAMLFormat{}},
+		{name: FormatHexdump, expected: &HexdumpFormat{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formater, err := New(tt.name)
+			require.NoError(t, err)
+			assert.IsType(t, tt.expected, formater)
+		})
+	}
+
+	_, err := New("xml")
+	assert.EqualError(t, err, "unsupported output format: xml, supported formats: pretty, raw, ndjson, yaml, hexdump")
+}
+
+func TestRawFormat(t *testing.T) {
+	formater := NewRawFormat()
+
+	output, err := formater.FormatMessage("Response", `{"a": 1}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, output)
+
+	output, err = formater.FormatForFile("ResponseBinary", "AAEC")
+	assert.NoError(t, err)
+	assert.Equal(t, "AAEC", output)
+
+	assert.False(t, formater.Headers())
+}
+
+func TestNDJSONFormat(t *testing.T) {
+	formater := NewNDJSONFormat()
+	formater.now = fixedTime
+
+	tests := []struct {
+		name        string
+		msgType     string
+		msgData     string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "request",
+			msgType:  "Request",
+			msgData:  `{"a": 1}`,
+			expected: `{"data":"{\"a\": 1}","direction":"out","type":"text","timestamp":"2024-05-01T10:30:00.0000005Z","size":8}`,
+		},
+		{
+			name:     "response",
+			msgType:  "Response",
+			msgData:  "hello",
+			expected: `{"data":"hello","direction":"in","type":"text","timestamp":"2024-05-01T10:30:00.0000005Z","size":5}`,
+		},
+		{
+			name:     "binary response",
+			msgType:  "ResponseBinary",
+			msgData:  "AAEC",
+			expected: `{"data":"AAEC","direction":"in","type":"binary","timestamp":"2024-05-01T10:30:00.0000005Z","size":3}`,
+		},
+		{
+			name:        "invalid binary",
+			msgType:     "RequestBinary",
+			msgData:     "not base64",
+			expectedErr: "invalid binary message",
+		},
+		{
+			name:        "unknown type",
+			msgType:     "NotDefined",
+			msgData:     "hello",
+			expectedErr: "unexpected message type: NotDefined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := formater.FormatMessage(tt.msgType, tt.msgData)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			fileOutput, err := formater.FormatForFile(tt.msgType, tt.msgData)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, fileOutput)
+		})
+	}
+
+	assert.False(t, formater.Headers())
+
+	received := time.Date(2024, 4, 30, 8, 0, 0, 0, time.UTC)
+	expected := `{"data":"hello","direction":"in","type":"text","timestamp":"2024-04-30T08:00:00Z","size":5}`
+
+	output, err := formater.FormatMessageAt("Response", "hello", received)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+
+	output, err = formater.FormatForFileAt("Response", "hello", received)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+}
+
+func TestYAMLFormat(t *testing.T) {
+	formater := NewYAMLFormat()
+	formater.now = fixedTime
+
+	tests := []struct {
+		name     string
+		msgType  string
+		msgData  string
+		expected string
+	}{
+		{
+			name:    "json request",
+			msgType: "Request",
+			msgData: `{"b": [1, "x"], "a": true}`,
+			expected: "---\ndata:\n    a: true\n    b:\n        - 1\n        - x\n" +
+				"direction: out\ntype: text\ntimestamp: \"2024-05-01T10:30:00.0000005Z\"\nsize: 26",
+		},
+		{
+			name:     "text response",
+			msgType:  "Response",
+			msgData:  "hello",
+			expected: "---\ndata: hello\ndirection: in\ntype: text\ntimestamp: \"2024-05-01T10:30:00.0000005Z\"\nsize: 5",
+		},
+		{
+			name:     "binary response",
+			msgType:  "ResponseBinary",
+			msgData:  "AAEC",
+			expected: "---\ndata: AAEC\ndirection: in\ntype: binary\ntimestamp: \"2024-05-01T10:30:00.0000005Z\"\nsize: 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := formater.FormatMessage(tt.msgType, tt.msgData)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+
+			fileOutput, err := formater.FormatForFile(tt.msgType, tt.msgData)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, fileOutput)
+		})
+	}
+
+	_, err := formater.FormatMessage("NotDefined", "hello")
+	assert.EqualError(t, err, "unexpected message type: NotDefined")
+
+	assert.False(t, formater.Headers())
+
+	received := time.Date(2024, 4, 30, 8, 0, 0, 0, time.UTC)
+	expected := "---\ndata: hello\ndirection: in\ntype: text\ntimestamp: \"2024-04-30T08:00:00Z\"\nsize: 5"
+
+	output, err := formater.FormatMessageAt("Response", "hello", received)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+
+	output, err = formater.FormatForFileAt("Response", "hello", received)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+}
+
+func TestHexdumpFormat(t *testing.T) {
+	formater := NewHexdumpFormat()
+
+	expected := "00000000  68 65 6c 6c 6f 00 01                              |hello..|"
+
+	output, err := formater.FormatMessage("ResponseBinary", "aGVsbG8AAQ==")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+
+	output, err = formater.FormatForFile("RequestBinary", "aGVsbG8AAQ==")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+
+	_, err = formater.FormatMessage("ResponseBinary", "not base64")
+	assert.ErrorContains(t, err, "invalid binary message")
+
+	output, err = formater.FormatForFile("Response", `{"a": 1}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, output)
+}
+
+func TestSwitch(t *testing.T) {
+	s, err := NewSwitch("")
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatPretty, s.Name())
+	assert.True(t, s.Headers())
+
+	output, err := s.FormatForFile("Response", `{"a": 1}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, output)
+
+	require.NoError(t, s.SetFormat(FormatRaw))
+
+	assert.Equal(t, FormatRaw, s.Name())
+	assert.False(t, s.Headers())
+
+	output, err = s.FormatMessage("Response", `{"a": 1}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, output)
+
+	output, err = s.FormatMessageAt("Response", `{"a": 1}`, fixedTime())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, output)
+
+	require.NoError(t, s.SetFormat(FormatNDJSON))
+
+	output, err = s.FormatForFileAt("Response", "hello", fixedTime())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"data":"hello","direction":"in","type":"text","timestamp":"2024-05-01T10:30:00.0000005Z","size":5}`, output)
+
+	require.NoError(t, s.SetFormat(FormatRaw))
+	assert.Error(t, s.SetFormat("xml"))
+	assert.Equal(t, FormatRaw, s.Name())
+
+	_, err = NewSwitch("xml")
+	assert.Error(t, err)
+}
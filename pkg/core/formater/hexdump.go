@@ -0,0 +1,47 @@
+package formater
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// HexdumpFormat prints binary messages as a hex dump with offsets and printable characters,
+// text messages are printed in the pretty format.
+type HexdumpFormat struct {
+	pretty *Format
+}
+
+// NewHexdumpFormat creates a new instance of HexdumpFormat.
+func NewHexdumpFormat() *HexdumpFormat {
+	return &HexdumpFormat{pretty: NewFormat()}
+}
+
+// FormatMessage formats binary messages as a hex dump and text messages in the pretty format.
+func (f *HexdumpFormat) FormatMessage(msgType, msgData string) (string, error) {
+	if !isBinary(msgType) {
+		return f.pretty.FormatMessage(msgType, msgData)
+	}
+
+	return dump(msgData)
+}
+
+// FormatForFile formats binary messages as a hex dump and text messages in the pretty format without colors.
+func (f *HexdumpFormat) FormatForFile(msgType, msgData string) (string, error) {
+	if !isBinary(msgType) {
+		return f.pretty.FormatForFile(msgType, msgData)
+	}
+
+	return dump(msgData)
+}
+
+// dump decodes the base64 message data and returns its hex dump.
+func dump(msgData string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(msgData)
+	if err != nil {
+		return "", fmt.Errorf("invalid binary message: %w", err)
+	}
+
+	return strings.TrimSuffix(hex.Dump(data), "\n"), nil
+}
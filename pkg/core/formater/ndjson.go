@@ -0,0 +1,51 @@
+package formater
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NDJSONFormat prints every message as a single line JSON envelope with its direction, type, timestamp, size and data.
+type NDJSONFormat struct {
+	now func() time.Time
+}
+
+// NewNDJSONFormat creates a new instance of NDJSONFormat.
+func NewNDJSONFormat() *NDJSONFormat {
+	return &NDJSONFormat{now: time.Now}
+}
+
+// FormatMessage formats the message as a JSON envelope on a single line with the current time.
+func (f *NDJSONFormat) FormatMessage(msgType, msgData string) (string, error) {
+	return f.FormatMessageAt(msgType, msgData, f.now())
+}
+
+// FormatMessageAt formats the message received or sent at the given time as a JSON envelope on a single line.
+func (f *NDJSONFormat) FormatMessageAt(msgType, msgData string, at time.Time) (string, error) {
+	env, err := newEnvelope(msgType, msgData, at)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+
+	return string(output), nil
+}
+
+// FormatForFile formats the message as a JSON envelope on a single line with the current time.
+func (f *NDJSONFormat) FormatForFile(msgType, msgData string) (string, error) {
+	return f.FormatMessage(msgType, msgData)
+}
+
+// FormatForFileAt formats the message received or sent at the given time as a JSON envelope on a single line.
+func (f *NDJSONFormat) FormatForFileAt(msgType, msgData string, at time.Time) (string, error) {
+	return f.FormatMessageAt(msgType, msgData, at)
+}
+
+// Headers reports that the direction headers are not printed with the envelope.
+func (f *NDJSONFormat) Headers() bool {
+	return false
+}
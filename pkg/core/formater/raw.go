@@ -0,0 +1,24 @@
+package formater
+
+// RawFormat prints the payload of messages as is, binary messages are printed encoded in base64.
+type RawFormat struct{}
+
+// NewRawFormat creates a new instance of RawFormat.
+func NewRawFormat() *RawFormat {
+	return &RawFormat{}
+}
+
+// FormatMessage returns the message data unchanged.
+func (f *RawFormat) FormatMessage(_, msgData string) (string, error) {
+	return msgData, nil
+}
+
+// FormatForFile returns the message data unchanged.
+func (f *RawFormat) FormatForFile(_, msgData string) (string, error) {
+	return msgData, nil
+}
+
+// Headers reports that the direction headers are not printed with the payload.
+func (f *RawFormat) Headers() bool {
+	return false
+}
@@ -0,0 +1,105 @@
+package formater
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+// Switch is a formater delegating to the selected output format, the format can be changed while the session runs.
+type Switch struct {
+	current core.Formater
+	name    string
+	l       sync.RWMutex
+}
+
+// NewSwitch creates a new Switch with the output format of the given name selected, an empty name selects the pretty format.
+// It returns an error if the format is not supported.
+func NewSwitch(name string) (*Switch, error) {
+	s := &Switch{}
+
+	if err := s.SetFormat(name); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// SetFormat selects the output format with the given name.
+// It returns an error if the format is not supported, the current format is kept in this case.
+func (s *Switch) SetFormat(name string) error {
+	formater, err := New(name)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = FormatPretty
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.current = formater
+	s.name = name
+
+	return nil
+}
+
+// Name returns the name of the selected output format.
+func (s *Switch) Name() string {
+	s.l.RLock()
+	defer s.l.RUnlock()
+
+	return s.name
+}
+
+// FormatMessage formats the message with the selected output format.
+func (s *Switch) FormatMessage(msgType, msgData string) (string, error) {
+	return s.formater().FormatMessage(msgType, msgData)
+}
+
+// FormatForFile formats the message for a file with the selected output format.
+func (s *Switch) FormatForFile(msgType, msgData string) (string, error) {
+	return s.formater().FormatForFile(msgType, msgData)
+}
+
+// FormatMessageAt formats the message received or sent at the given time with the selected output format,
+// the time is ignored by the formats without timestamps.
+func (s *Switch) FormatMessageAt(msgType, msgData string, at time.Time) (string, error) {
+	f := s.formater()
+	if timed, ok := f.(core.TimedFormater); ok {
+		return timed.FormatMessageAt(msgType, msgData, at)
+	}
+
+	return f.FormatMessage(msgType, msgData)
+}
+
+// FormatForFileAt formats the message received or sent at the given time for a file with the selected output format,
+// the time is ignored by the formats without timestamps.
+func (s *Switch) FormatForFileAt(msgType, msgData string, at time.Time) (string, error) {
+	f := s.formater()
+	if timed, ok := f.(core.TimedFormater); ok {
+		return timed.FormatForFileAt(msgType, msgData, at)
+	}
+
+	return f.FormatForFile(msgType, msgData)
+}
+
+// Headers reports whether the direction headers are printed with messages in the selected output format.
+func (s *Switch) Headers() bool {
+	if f, ok := s.formater().(core.HeaderedFormater); ok {
+		return f.Headers()
+	}
+
+	return true
+}
+
+// formater returns the formater of the selected output format.
+func (s *Switch) formater() core.Formater {
+	s.l.RLock()
+	defer s.l.RUnlock()
+
+	return s.current
+}
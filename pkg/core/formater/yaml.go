@@ -0,0 +1,60 @@
+package formater
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLFormat prints every message as a YAML document with its direction, type, timestamp, size and data.
+// JSON payloads of text messages are converted to YAML.
+type YAMLFormat struct {
+	now func() time.Time
+}
+
+// NewYAMLFormat creates a new instance of YAMLFormat.
+func NewYAMLFormat() *YAMLFormat {
+	return &YAMLFormat{now: time.Now}
+}
+
+// FormatMessage formats the message as a YAML document with the current time.
+func (f *YAMLFormat) FormatMessage(msgType, msgData string) (string, error) {
+	return f.FormatMessageAt(msgType, msgData, f.now())
+}
+
+// FormatMessageAt formats the message received or sent at the given time as a YAML document.
+func (f *YAMLFormat) FormatMessageAt(msgType, msgData string, at time.Time) (string, error) {
+	env, err := newEnvelope(msgType, msgData, at)
+	if err != nil {
+		return "", err
+	}
+
+	var obj any
+	if env.Type == "text" && json.Unmarshal([]byte(msgData), &obj) == nil {
+		env.Data = obj
+	}
+
+	output, err := yaml.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+
+	return "---\n" + strings.TrimSuffix(string(output), "\n"), nil
+}
+
+// FormatForFile formats the message as a YAML document with the current time.
+func (f *YAMLFormat) FormatForFile(msgType, msgData string) (string, error) {
+	return f.FormatMessage(msgType, msgData)
+}
+
+// FormatForFileAt formats the message received or sent at the given time as a YAML document.
+func (f *YAMLFormat) FormatForFileAt(msgType, msgData string, at time.Time) (string, error) {
+	return f.FormatMessageAt(msgType, msgData, at)
+}
+
+// Headers reports that the direction headers are not printed with the document.
+func (f *YAMLFormat) Headers() bool {
+	return false
+}
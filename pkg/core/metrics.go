@@ -0,0 +1,51 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// MessageSizeBounds are the upper bounds in bytes of the message size distribution buckets.
+// Messages larger than the last bound are counted in an additional overflow bucket.
+var MessageSizeBounds = []int64{64, 256, 1024, 4096, 16384, 65536, 262144}
+
+// Metrics contains message statistics of the connection per direction.
+type Metrics struct {
+	Sent     DirectionMetrics
+	Received DirectionMetrics
+	Uptime   time.Duration
+}
+
+// DirectionMetrics contains message statistics for one direction of the connection.
+// SizeBuckets holds the number of messages per size bucket, see MessageSizeBounds.
+// Rates are calculated over sliding windows of the last 10 and 60 seconds.
+type DirectionMetrics struct {
+	LastActivity time.Time
+	SizeBuckets  []uint64
+	Messages     uint64
+	Bytes        uint64
+	MinSize      uint64
+	MaxSize      uint64
+	Rate10s      float64
+	Rate60s      float64
+	ByteRate10s  float64
+	ByteRate60s  float64
+	AvgInterval  time.Duration
+}
+
+// AvgSize returns the average message size in bytes.
+func (m DirectionMetrics) AvgSize() uint64 {
+	if m.Messages == 0 {
+		return 0
+	}
+
+	return m.Bytes / m.Messages
+}
+
+// Summary returns a one line summary of the number of messages and bytes per direction.
+func (m Metrics) Summary() string {
+	return fmt.Sprintf(
+		"sent %d messages (%d bytes), received %d messages (%d bytes) in %v\n",
+		m.Sent.Messages, m.Sent.Bytes, m.Received.Messages, m.Received.Bytes, m.Uptime.Round(time.Second),
+	)
+}
@@ -0,0 +1,23 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectionMetrics_AvgSize(t *testing.T) {
+	assert.Zero(t, DirectionMetrics{}.AvgSize())
+	assert.Equal(t, uint64(25), DirectionMetrics{Messages: 4, Bytes: 100}.AvgSize())
+}
+
+func TestMetrics_Summary(t *testing.T) {
+	metrics := Metrics{
+		Sent:     DirectionMetrics{Messages: 2, Bytes: 20},
+		Received: DirectionMetrics{Messages: 10, Bytes: 1000},
+		Uptime:   90*time.Second + 400*time.Millisecond,
+	}
+
+	assert.Equal(t, "sent 2 messages (20 bytes), received 10 messages (1000 bytes) in 1m30s\n", metrics.Summary())
+}
@@ -0,0 +1,295 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Delimiters of the requests read from the input in pipe mode.
+const (
+	DelimiterLine   = "line"
+	DelimiterNUL    = "nul"
+	DelimiterNDJSON = "ndjson"
+
+	maxPipeRequestSize = 16 * 1024 * 1024
+)
+
+// PipeOptions contains the settings of the pipe mode.
+// Requests are sent before the requests read from the input.
+// IdleTimeout is the time to wait for messages after the input is closed, the pipe stops
+// when no message is received for this time, zero stops it right after the last request is sent.
+// Formater is the output format of received messages, when it is not set messages are written one per line.
+type PipeOptions struct {
+	OutputFile  io.Writer
+	Formater    Formater
+	Delimiter   string
+	Requests    []string
+	IdleTimeout time.Duration
+}
+
+// Pipe is the non-interactive mode for shell pipelines.
+// It sends requests read from the input and writes every received message to the output on a single line,
+// without colors, prompts and request echo.
+type Pipe struct {
+	conn     ConnectionHandler
+	input    io.Reader
+	output   io.Writer
+	received chan struct{}
+	opts     PipeOptions
+	l        sync.Mutex
+}
+
+// NewPipe creates a new Pipe for the connection.
+// It takes conn of type ConnectionHandler, input of type io.Reader with the requests, output of type io.Writer
+// for the received messages and opts of type PipeOptions.
+// It returns a pointer to Pipe and an error if the delimiter is not supported.
+func NewPipe(conn ConnectionHandler, input io.Reader, output io.Writer, opts PipeOptions) (*Pipe, error) {
+	switch opts.Delimiter {
+	case "":
+		opts.Delimiter = DelimiterLine
+	case DelimiterLine, DelimiterNUL, DelimiterNDJSON:
+	default:
+		return nil, fmt.Errorf("invalid delimiter: %s", opts.Delimiter)
+	}
+
+	p := &Pipe{
+		conn:     conn,
+		input:    input,
+		output:   output,
+		received: make(chan struct{}, 1),
+		opts:     opts,
+	}
+
+	conn.SetOnMessage(p.onMessage)
+
+	return p, nil
+}
+
+// Run sends the requests read from the input until the input is closed,
+// then waits until no message is received for the idle timeout.
+// It returns nil when the input is processed or the context is canceled,
+// and an error if the input cannot be read or a request cannot be sent.
+func (p *Pipe) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for _, req := range p.opts.Requests {
+		if err := p.conn.Send(ctx, req); err != nil {
+			return fmt.Errorf("failed to send request: %w", err)
+		}
+	}
+
+	requests := make(chan string)
+	readErr := make(chan error, 1)
+
+	// Reading from the input blocks until data arrives, so the reader is abandoned when the pipe stops.
+	go func() {
+		defer close(requests)
+
+		readErr <- p.read(ctx, requests)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case req, ok := <-requests:
+			if !ok {
+				if err := <-readErr; err != nil {
+					return fmt.Errorf("failed to read input: %w", err)
+				}
+
+				return p.waitIdle(ctx)
+			}
+
+			if err := p.conn.Send(ctx, req); err != nil {
+				return fmt.Errorf("failed to send request: %w", err)
+			}
+		}
+	}
+}
+
+// read splits the input into requests by the configured delimiter and passes them to requests.
+// Empty requests are skipped, NDJSON requests are compacted to a single line.
+// It returns nil when the input is closed or the context is canceled, and an error if reading fails
+// or the input is not valid JSON in NDJSON mode.
+func (p *Pipe) read(ctx context.Context, requests chan<- string) error {
+	next, err := p.splitter()
+	if err != nil {
+		return err
+	}
+
+	for {
+		req, err := next()
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return err
+		case req == "":
+			continue
+		}
+
+		select {
+		case requests <- req:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// splitter returns the function reading the next request from the input.
+// The function returns io.EOF when the input is closed.
+func (p *Pipe) splitter() (func() (string, error), error) {
+	if p.opts.Delimiter == DelimiterNDJSON {
+		decoder := json.NewDecoder(p.input)
+
+		return func() (string, error) {
+			var req json.RawMessage
+			if err := decoder.Decode(&req); err != nil {
+				if errors.Is(err, io.EOF) {
+					return "", io.EOF
+				}
+
+				return "", fmt.Errorf("invalid JSON request: %w", err)
+			}
+
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, req); err != nil {
+				return "", fmt.Errorf("invalid JSON request: %w", err)
+			}
+
+			return buf.String(), nil
+		}, nil
+	}
+
+	scanner := bufio.NewScanner(p.input)
+	scanner.Buffer(nil, maxPipeRequestSize)
+
+	if p.opts.Delimiter == DelimiterNUL {
+		scanner.Split(scanNUL)
+	}
+
+	return func() (string, error) {
+		if scanner.Scan() {
+			return scanner.Text(), nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+
+		return "", io.EOF
+	}, nil
+}
+
+// scanNUL is a bufio.SplitFunc that splits the input into NUL-terminated tokens.
+func scanNUL(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
+
+// waitIdle waits until no message is received for the idle timeout.
+// It returns immediately if the idle timeout is not set.
+func (p *Pipe) waitIdle(ctx context.Context) error {
+	if p.opts.IdleTimeout <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(p.opts.IdleTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			return nil
+		case <-p.received:
+			timer.Reset(p.opts.IdleTimeout)
+		}
+	}
+}
+
+// onMessage writes the received message to the output and the output file.
+// Without the formater the message is written on a single line: JSON messages are compacted,
+// line breaks of text messages are escaped and binary messages are encoded in base64.
+func (p *Pipe) onMessage(_ context.Context, data []byte, isBinary bool) {
+	line := p.format(data, isBinary) + "\n"
+
+	p.l.Lock()
+
+	_, _ = io.WriteString(p.output, line)
+
+	if p.opts.OutputFile != nil {
+		_, _ = io.WriteString(p.opts.OutputFile, line)
+	}
+
+	p.l.Unlock()
+
+	select {
+	case p.received <- struct{}{}:
+	default:
+	}
+}
+
+// format converts the received message into its output representation.
+// It falls back to a single line if the formater fails to format the message.
+func (p *Pipe) format(data []byte, isBinary bool) string {
+	if p.opts.Formater == nil {
+		return formatLine(data, isBinary)
+	}
+
+	msg := Message{Type: Response, Data: string(data), Time: time.Now()}
+	if isBinary {
+		msg = Message{Type: ResponseBinary, Data: base64.StdEncoding.EncodeToString(data), Time: msg.Time}
+	}
+
+	var (
+		output string
+		err    error
+	)
+
+	if timed, ok := p.opts.Formater.(TimedFormater); ok {
+		output, err = timed.FormatForFileAt(msg.Type.String(), msg.Data, msg.Time)
+	} else {
+		output, err = p.opts.Formater.FormatForFile(msg.Type.String(), msg.Data)
+	}
+
+	if err != nil {
+		return formatLine(data, isBinary)
+	}
+
+	return output
+}
+
+// formatLine converts the message into a single line.
+func formatLine(data []byte, isBinary bool) string {
+	if isBinary {
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	var buf bytes.Buffer
+	if json.Compact(&buf, data) == nil {
+		return buf.String()
+	}
+
+	return strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(string(data))
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipe_Run(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter string
+		input     string
+		expected  []string
+	}{
+		{name: "lines", delimiter: "", input: "first\n\nsecond request\r\n", expected: []string{"first", "second request"}},
+		{name: "nul", delimiter: DelimiterNUL, input: "multi\nline\x00\x00last", expected: []string{"multi\nline", "last"}},
+		{name: "ndjson", delimiter: DelimiterNDJSON, input: "{\"a\": 1}\n\n{\n  \"b\": [1, 2]\n}\n", expected: []string{`{"a":1}`, `{"b":[1,2]}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := NewMockConnectionHandler(t)
+			conn.EXPECT().SetOnMessage(mock.Anything)
+
+			var sent []string
+
+			conn.EXPECT().Send(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, msg string) error {
+				sent = append(sent, msg)
+				return nil
+			})
+
+			pipe, err := NewPipe(conn, strings.NewReader(tt.input), &bytes.Buffer{}, PipeOptions{
+				Delimiter: tt.delimiter,
+				Requests:  []string{"initial"},
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, pipe.Run(context.Background()))
+
+			assert.Equal(t, append([]string{"initial"}, tt.expected...), sent)
+		})
+	}
+}
+
+func TestPipe_Output(t *testing.T) {
+	conn := NewMockConnectionHandler(t)
+
+	var onMessage func(context.Context, []byte, bool)
+
+	conn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte, bool)) {
+		onMessage = cb
+	})
+	conn.EXPECT().Send(mock.Anything, "ping").RunAndReturn(func(ctx context.Context, _ string) error {
+		go func() {
+			onMessage(ctx, []byte("{\n  \"pong\": true\n}"), false)
+			time.Sleep(20 * time.Millisecond)
+			onMessage(ctx, []byte("two\nlines"), false)
+			onMessage(ctx, []byte{0x01, 0x02}, true)
+		}()
+
+		return nil
+	})
+
+	var output, file bytes.Buffer
+
+	pipe, err := NewPipe(conn, strings.NewReader("ping\n"), &output, PipeOptions{
+		OutputFile:  &file,
+		IdleTimeout: 100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, pipe.Run(context.Background()))
+
+	expected := "{\"pong\":true}\ntwo\\nlines\nAQI=\n"
+	assert.Equal(t, expected, output.String())
+	assert.Equal(t, expected, file.String())
+}
+
+func TestPipe_Formater(t *testing.T) {
+	conn := NewMockConnectionHandler(t)
+
+	var onMessage func(context.Context, []byte, bool)
+
+	conn.EXPECT().SetOnMessage(mock.Anything).Run(func(cb func(context.Context, []byte, bool)) {
+		onMessage = cb
+	})
+
+	formater := NewMockFormater(t)
+	formater.EXPECT().FormatForFile("Response", "text").Return("formatted text", nil)
+	formater.EXPECT().FormatForFile("ResponseBinary", "AQI=").Return("formatted binary", nil)
+	formater.EXPECT().FormatForFile("Response", "broken").Return("", assert.AnError)
+
+	var output bytes.Buffer
+
+	_, err := NewPipe(conn, strings.NewReader(""), &output, PipeOptions{Formater: formater})
+	require.NoError(t, err)
+
+	onMessage(context.Background(), []byte("text"), false)
+	onMessage(context.Background(), []byte{0x01, 0x02}, true)
+	onMessage(context.Background(), []byte("broken"), false)
+
+	assert.Equal(t, "formatted text\nformatted binary\nbroken\n", output.String())
+}
+
+func TestPipe_RunCanceled(t *testing.T) {
+	conn := NewMockConnectionHandler(t)
+	conn.EXPECT().SetOnMessage(mock.Anything)
+
+	// The input is never closed, like a terminal or a long-lived producer.
+	input, writer := io.Pipe()
+	defer func() { _ = writer.Close() }()
+
+	pipe, err := NewPipe(conn, input, &bytes.Buffer{}, PipeOptions{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- pipe.Run(ctx) }()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("pipe is not stopped when the context is canceled")
+	}
+}
+
+func TestPipe_Errors(t *testing.T) {
+	conn := NewMockConnectionHandler(t)
+
+	_, err := NewPipe(conn, strings.NewReader(""), &bytes.Buffer{}, PipeOptions{Delimiter: "tab"})
+	assert.EqualError(t, err, "invalid delimiter: tab")
+
+	conn.EXPECT().SetOnMessage(mock.Anything)
+	conn.EXPECT().Send(mock.Anything, `{"a":1}`).Return(nil)
+
+	pipe, err := NewPipe(conn, strings.NewReader(`{"a":1} {"b":`), &bytes.Buffer{}, PipeOptions{Delimiter: DelimiterNDJSON})
+	require.NoError(t, err)
+
+	assert.EqualError(t, pipe.Run(context.Background()), "failed to read input: invalid JSON request: unexpected EOF")
+
+	conn = NewMockConnectionHandler(t)
+	conn.EXPECT().SetOnMessage(mock.Anything)
+	conn.EXPECT().Send(mock.Anything, "req").Return(assert.AnError)
+
+	pipe, err = NewPipe(conn, strings.NewReader("req\nnext\n"), &bytes.Buffer{}, PipeOptions{})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, pipe.Run(context.Background()), assert.AnError)
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"errors"
+	"net/url"
+	"time"
+)
+
+var (
+	ErrUnsupportedCommand = errors.New("command is not supported by the protocol")
+
+	// ErrNotAcknowledged is returned by Encode when the server has not acknowledged the protocol session in time,
+	// the command is reported and the session continues, so that it can be retried.
+	ErrNotAcknowledged = errors.New("connection is not acknowledged by the server")
+)
+
+// ProtocolFrame is a WebSocket message of an application protocol.
+// Display is the human readable form of the frame printed instead of the raw data, the data is printed if it is empty.
+// Response is the correlation id of the response the command waits for after sending the frame,
+// it is empty if the frame does not expect a response. Waiting requires the protocol to implement ResponseTracker.
+type ProtocolFrame struct {
+	Display  string
+	Response string
+	Data     []byte
+	Binary   bool
+}
+
+// ProtocolMessage is the result of decoding an incoming frame of an application protocol.
+// Display is the message printed to the user, control frames like pings have an empty Display and are not printed.
+// Replies are sent back to the server automatically, for example pongs or acknowledgements.
+type ProtocolMessage struct {
+	Display string
+	Replies []ProtocolFrame
+}
+
+// Protocol is an application protocol running on top of WebSocket, like Socket.IO.
+// It performs the protocol handshake, answers control frames and translates between
+// protocol frames and messages displayed to the user.
+// Implementations should be safe for concurrent use, frames are decoded and encoded from different goroutines.
+type Protocol interface {
+	// Name returns the name of the protocol.
+	Name() string
+	// PrepareURL adjusts the server address before the handshake, e.g. adds required query parameters.
+	PrepareURL(u *url.URL)
+	// Subprotocols returns the WebSocket subprotocols the protocol requires, in order of preference.
+	Subprotocols() []string
+	// Open returns the frames sent right after the connection is established, it is called on every reconnection.
+	Open() ([]ProtocolFrame, error)
+	// Decode decodes the incoming frame.
+	Decode(data []byte, isBinary bool) (ProtocolMessage, error)
+	// Encode builds the frames for the protocol command, e.g. "emit", with its raw arguments.
+	// It returns ErrUnsupportedCommand if the protocol does not provide the command.
+	Encode(command, args string) ([]ProtocolFrame, error)
+}
+
+// ResponseTracker is implemented by protocols whose commands wait for the response of the server.
+type ResponseTracker interface {
+	// IsResponse reports whether the displayed message is the response with the correlation id.
+	IsResponse(msg, id string) bool
+}
+
+// Heartbeater is implemented by protocols which send heartbeats to keep the session alive.
+type Heartbeater interface {
+	// HeartbeatInterval returns the time until the next heartbeat, heartbeats stop if it is not positive.
+	// It is called before every heartbeat, so the interval may change after it is negotiated with the server.
+	HeartbeatInterval() time.Duration
+	// Heartbeat returns the frames of a heartbeat, no frames are sent if it returns none.
+	Heartbeat() ([]ProtocolFrame, error)
+}
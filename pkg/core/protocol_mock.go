@@ -0,0 +1,336 @@
+// Code generated by mockery v2.53.6. DO NOT EDIT.
+
+//go:build !compile
+
+package core
+
+import (
+	url "net/url"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockProtocol is an autogenerated mock type for the Protocol type
+type MockProtocol struct {
+	mock.Mock
+}
+
+type MockProtocol_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockProtocol) EXPECT() *MockProtocol_Expecter {
+	return &MockProtocol_Expecter{mock: &_m.Mock}
+}
+
+// Decode provides a mock function with given fields: data, isBinary
+func (_m *MockProtocol) Decode(data []byte, isBinary bool) (ProtocolMessage, error) {
+	ret := _m.Called(data, isBinary)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Decode")
+	}
+
+	var r0 ProtocolMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte, bool) (ProtocolMessage, error)); ok {
+		return rf(data, isBinary)
+	}
+	if rf, ok := ret.Get(0).(func([]byte, bool) ProtocolMessage); ok {
+		r0 = rf(data, isBinary)
+	} else {
+		r0 = ret.Get(0).(ProtocolMessage)
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte, bool) error); ok {
+		r1 = rf(data, isBinary)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockProtocol_Decode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Decode'
+type MockProtocol_Decode_Call struct {
+	*mock.Call
+}
+
+// Decode is a helper method to define mock.On call
+//   - data []byte
+//   - isBinary bool
+func (_e *MockProtocol_Expecter) Decode(data interface{}, isBinary interface{}) *MockProtocol_Decode_Call {
+	return &MockProtocol_Decode_Call{Call: _e.mock.On("Decode", data, isBinary)}
+}
+
+func (_c *MockProtocol_Decode_Call) Run(run func(data []byte, isBinary bool)) *MockProtocol_Decode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]byte), args[1].(bool))
+	})
+	return _c
+}
+
+func (_c *MockProtocol_Decode_Call) Return(_a0 ProtocolMessage, _a1 error) *MockProtocol_Decode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockProtocol_Decode_Call) RunAndReturn(run func([]byte, bool) (ProtocolMessage, error)) *MockProtocol_Decode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Encode provides a mock function with given fields: command, args
+func (_m *MockProtocol) Encode(command string, args string) ([]ProtocolFrame, error) {
+	ret := _m.Called(command, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Encode")
+	}
+
+	var r0 []ProtocolFrame
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]ProtocolFrame, error)); ok {
+		return rf(command, args)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []ProtocolFrame); ok {
+		r0 = rf(command, args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]ProtocolFrame)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(command, args)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockProtocol_Encode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Encode'
+type MockProtocol_Encode_Call struct {
+	*mock.Call
+}
+
+// Encode is a helper method to define mock.On call
+//   - command string
+//   - args string
+func (_e *MockProtocol_Expecter) Encode(command interface{}, args interface{}) *MockProtocol_Encode_Call {
+	return &MockProtocol_Encode_Call{Call: _e.mock.On("Encode", command, args)}
+}
+
+func (_c *MockProtocol_Encode_Call) Run(run func(command string, args string)) *MockProtocol_Encode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockProtocol_Encode_Call) Return(_a0 []ProtocolFrame, _a1 error) *MockProtocol_Encode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockProtocol_Encode_Call) RunAndReturn(run func(string, string) ([]ProtocolFrame, error)) *MockProtocol_Encode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Name provides a mock function with no fields
+func (_m *MockProtocol) Name() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Name")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockProtocol_Name_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Name'
+type MockProtocol_Name_Call struct {
+	*mock.Call
+}
+
+// Name is a helper method to define mock.On call
+func (_e *MockProtocol_Expecter) Name() *MockProtocol_Name_Call {
+	return &MockProtocol_Name_Call{Call: _e.mock.On("Name")}
+}
+
+func (_c *MockProtocol_Name_Call) Run(run func()) *MockProtocol_Name_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockProtocol_Name_Call) Return(_a0 string) *MockProtocol_Name_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockProtocol_Name_Call) RunAndReturn(run func() string) *MockProtocol_Name_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Open provides a mock function with no fields
+func (_m *MockProtocol) Open() ([]ProtocolFrame, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Open")
+	}
+
+	var r0 []ProtocolFrame
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]ProtocolFrame, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []ProtocolFrame); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]ProtocolFrame)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockProtocol_Open_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Open'
+type MockProtocol_Open_Call struct {
+	*mock.Call
+}
+
+// Open is a helper method to define mock.On call
+func (_e *MockProtocol_Expecter) Open() *MockProtocol_Open_Call {
+	return &MockProtocol_Open_Call{Call: _e.mock.On("Open")}
+}
+
+func (_c *MockProtocol_Open_Call) Run(run func()) *MockProtocol_Open_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockProtocol_Open_Call) Return(_a0 []ProtocolFrame, _a1 error) *MockProtocol_Open_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockProtocol_Open_Call) RunAndReturn(run func() ([]ProtocolFrame, error)) *MockProtocol_Open_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PrepareURL provides a mock function with given fields: u
+func (_m *MockProtocol) PrepareURL(u *url.URL) {
+	_m.Called(u)
+}
+
+// MockProtocol_PrepareURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PrepareURL'
+type MockProtocol_PrepareURL_Call struct {
+	*mock.Call
+}
+
+// PrepareURL is a helper method to define mock.On call
+//   - u *url.URL
+func (_e *MockProtocol_Expecter) PrepareURL(u interface{}) *MockProtocol_PrepareURL_Call {
+	return &MockProtocol_PrepareURL_Call{Call: _e.mock.On("PrepareURL", u)}
+}
+
+func (_c *MockProtocol_PrepareURL_Call) Run(run func(u *url.URL)) *MockProtocol_PrepareURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*url.URL))
+	})
+	return _c
+}
+
+func (_c *MockProtocol_PrepareURL_Call) Return() *MockProtocol_PrepareURL_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockProtocol_PrepareURL_Call) RunAndReturn(run func(*url.URL)) *MockProtocol_PrepareURL_Call {
+	_c.Run(run)
+	return _c
+}
+
+// Subprotocols provides a mock function with no fields
+func (_m *MockProtocol) Subprotocols() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subprotocols")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// MockProtocol_Subprotocols_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Subprotocols'
+type MockProtocol_Subprotocols_Call struct {
+	*mock.Call
+}
+
+// Subprotocols is a helper method to define mock.On call
+func (_e *MockProtocol_Expecter) Subprotocols() *MockProtocol_Subprotocols_Call {
+	return &MockProtocol_Subprotocols_Call{Call: _e.mock.On("Subprotocols")}
+}
+
+func (_c *MockProtocol_Subprotocols_Call) Run(run func()) *MockProtocol_Subprotocols_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockProtocol_Subprotocols_Call) Return(_a0 []string) *MockProtocol_Subprotocols_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockProtocol_Subprotocols_Call) RunAndReturn(run func() []string) *MockProtocol_Subprotocols_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockProtocol creates a new instance of MockProtocol. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockProtocol(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockProtocol {
+	mock := &MockProtocol{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,311 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+const (
+	graphQLSubprotocol = "graphql-transport-ws"
+	subscribeCommand   = "subscribe"
+	completeCommand    = "complete"
+	operationLabelSize = 40
+
+	// DefaultAckTimeout is the time commands wait for the server to acknowledge the connection.
+	DefaultAckTimeout = 10 * time.Second
+)
+
+// graphql-transport-ws message types.
+const (
+	gqlConnectionInit = "connection_init"
+	gqlConnectionAck  = "connection_ack"
+	gqlPing           = "ping"
+	gqlPong           = "pong"
+	gqlSubscribe      = "subscribe"
+	gqlNext           = "next"
+	gqlError          = "error"
+	gqlComplete       = "complete"
+)
+
+var (
+	ErrNotAcknowledged = core.ErrNotAcknowledged
+
+	operationName = regexp.MustCompile(`^\s*(query|mutation|subscription)\s+(\w+)`)
+)
+
+// GraphQL implements the graphql-transport-ws protocol of GraphQL over WebSocket.
+// It initializes the connection with the init payload, answers pings and assigns ids to the started operations,
+// so that their results are printed per operation.
+type GraphQL struct {
+	acked       chan struct{}
+	operations  map[string]string
+	initPayload json.RawMessage
+	ackTimeout  time.Duration
+	nextID      int
+	l           sync.Mutex
+}
+
+// graphQLMessage is a message of the graphql-transport-ws protocol.
+type graphQLMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// graphQLResult is the decoded form of a message displayed to the user, labeled with the operation it belongs to.
+type graphQLResult struct {
+	ID        string          `json:"id,omitempty"`
+	Operation string          `json:"operation,omitempty"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+// subscribePayload is the payload of the subscribe message.
+type subscribePayload struct {
+	Query     string          `json:"query"`
+	Variables json.RawMessage `json:"variables,omitempty"`
+}
+
+// NewGraphQL creates the graphql-transport-ws protocol.
+// It takes initPayload of type string, the JSON payload of the connection_init message, which may be empty,
+// and ackTimeout of type time.Duration, the time commands wait for the connection acknowledgement,
+// DefaultAckTimeout is used if it is not positive.
+// It returns a pointer to GraphQL and an error if the init payload is not a JSON object.
+func NewGraphQL(initPayload string, ackTimeout time.Duration) (*GraphQL, error) {
+	g := &GraphQL{
+		acked:      make(chan struct{}),
+		operations: make(map[string]string),
+		ackTimeout: ackTimeout,
+	}
+
+	if g.ackTimeout <= 0 {
+		g.ackTimeout = DefaultAckTimeout
+	}
+
+	if initPayload != "" {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(initPayload), &obj); err != nil {
+			return nil, fmt.Errorf("init payload should be a JSON object: %w", err)
+		}
+
+		g.initPayload = json.RawMessage(initPayload)
+	}
+
+	return g, nil
+}
+
+// Name returns the name of the protocol.
+func (g *GraphQL) Name() string {
+	return graphQLSubprotocol
+}
+
+// PrepareURL keeps the address unchanged.
+func (g *GraphQL) PrepareURL(*url.URL) {}
+
+// Subprotocols returns the graphql-transport-ws subprotocol, which servers require to be negotiated.
+func (g *GraphQL) Subprotocols() []string {
+	return []string{graphQLSubprotocol}
+}
+
+// Open resets the operations of the previous session.
+// It returns the connection_init message with the init payload.
+func (g *GraphQL) Open() ([]core.ProtocolFrame, error) {
+	g.l.Lock()
+	g.acked = make(chan struct{})
+	g.operations = make(map[string]string)
+	g.nextID = 0
+	g.l.Unlock()
+
+	frame, err := encodeGraphQL(graphQLMessage{Type: gqlConnectionInit, Payload: g.initPayload}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return []core.ProtocolFrame{frame}, nil
+}
+
+// Decode decodes the incoming message.
+// It answers pings with pongs, which are not displayed, and labels the results with their operations.
+// It returns an error if the message is not a valid graphql-transport-ws message.
+func (g *GraphQL) Decode(data []byte, isBinary bool) (core.ProtocolMessage, error) {
+	if isBinary {
+		return core.ProtocolMessage{}, fmt.Errorf("unexpected binary message")
+	}
+
+	var msg graphQLMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type == "" {
+		return core.ProtocolMessage{}, fmt.Errorf("invalid graphql-transport-ws message: %s", data)
+	}
+
+	result := graphQLResult{ID: msg.ID, Type: msg.Type, Payload: msg.Payload}
+
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	switch msg.Type {
+	case gqlPing:
+		pong, err := encodeGraphQL(graphQLMessage{Type: gqlPong}, "")
+		if err != nil {
+			return core.ProtocolMessage{}, err
+		}
+
+		return core.ProtocolMessage{Replies: []core.ProtocolFrame{pong}}, nil
+	case gqlPong:
+		return core.ProtocolMessage{}, nil
+	case gqlConnectionAck:
+		select {
+		case <-g.acked:
+		default:
+			close(g.acked)
+		}
+	case gqlNext:
+		result.Operation = g.operations[msg.ID]
+	case gqlError, gqlComplete:
+		result.Operation = g.operations[msg.ID]
+		delete(g.operations, msg.ID)
+	}
+
+	shown, err := json.Marshal(result)
+	if err != nil {
+		return core.ProtocolMessage{}, fmt.Errorf("failed to encode %s message: %w", msg.Type, err)
+	}
+
+	return core.ProtocolMessage{Display: string(shown)}, nil
+}
+
+// Encode builds the messages for the subscribe and complete commands.
+// The arguments of subscribe are the GraphQL document followed by optional JSON variables: <query> [variables],
+// the operation gets the next id. The argument of complete is the id of the operation to stop.
+// Commands wait for the connection acknowledgement up to the ack timeout.
+// It returns ErrUnsupportedCommand for other commands, ErrNotAcknowledged if the server does not acknowledge
+// the connection in time and an error if the arguments are invalid.
+func (g *GraphQL) Encode(command, args string) ([]core.ProtocolFrame, error) {
+	if command != subscribeCommand && command != completeCommand {
+		return nil, fmt.Errorf("%w: %s", core.ErrUnsupportedCommand, command)
+	}
+
+	if err := g.waitAck(); err != nil {
+		return nil, err
+	}
+
+	args = strings.TrimSpace(args)
+
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	if command == completeCommand {
+		label, ok := g.operations[args]
+		if !ok {
+			return nil, fmt.Errorf("unknown operation: %s", args)
+		}
+
+		delete(g.operations, args)
+
+		frame, err := encodeGraphQL(graphQLMessage{ID: args, Type: gqlComplete}, label)
+		if err != nil {
+			return nil, err
+		}
+
+		return []core.ProtocolFrame{frame}, nil
+	}
+
+	query, variables := splitVariables(args)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	payload, err := json.Marshal(subscribePayload{Query: query, Variables: variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode subscribe payload: %w", err)
+	}
+
+	g.nextID++
+	id := strconv.Itoa(g.nextID)
+	label := operationLabel(query)
+
+	frame, err := encodeGraphQL(graphQLMessage{ID: id, Type: gqlSubscribe, Payload: payload}, label)
+	if err != nil {
+		return nil, err
+	}
+
+	g.operations[id] = label
+
+	return []core.ProtocolFrame{frame}, nil
+}
+
+// waitAck waits until the server acknowledges the connection.
+// It returns ErrNotAcknowledged if the acknowledgement is not received within the ack timeout.
+func (g *GraphQL) waitAck() error {
+	g.l.Lock()
+	acked := g.acked
+	g.l.Unlock()
+
+	select {
+	case <-acked:
+		return nil
+	case <-time.After(g.ackTimeout):
+		return fmt.Errorf("%w within %s", ErrNotAcknowledged, g.ackTimeout)
+	}
+}
+
+// encodeGraphQL encodes the message into a frame displayed with the label of its operation.
+func encodeGraphQL(msg graphQLMessage, label string) (core.ProtocolFrame, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return core.ProtocolFrame{}, fmt.Errorf("failed to encode %s message: %w", msg.Type, err)
+	}
+
+	shown, err := json.Marshal(graphQLResult{ID: msg.ID, Operation: label, Type: msg.Type, Payload: msg.Payload})
+	if err != nil {
+		return core.ProtocolFrame{}, fmt.Errorf("failed to encode %s message: %w", msg.Type, err)
+	}
+
+	return core.ProtocolFrame{Data: data, Display: string(shown)}, nil
+}
+
+// splitVariables splits the subscribe arguments into the GraphQL document and the trailing JSON variables object.
+// The variables are nil if the arguments do not end with a JSON object.
+func splitVariables(args string) (string, json.RawMessage) {
+	depth := 0
+
+	for i, r := range args {
+		switch r {
+		case '{':
+			// GraphQL selection sets are not valid JSON, so the first balanced position
+			// followed by a JSON object is the start of the variables.
+			if depth == 0 && i > 0 {
+				if rest := strings.TrimSpace(args[i:]); json.Valid([]byte(rest)) && strings.HasPrefix(rest, "{") {
+					return strings.TrimSpace(args[:i]), json.RawMessage(rest)
+				}
+			}
+
+			depth++
+		case '}':
+			depth--
+		}
+	}
+
+	return args, nil
+}
+
+// operationLabel returns the name of the operation, or the beginning of the document for anonymous operations.
+func operationLabel(query string) string {
+	if m := operationName.FindStringSubmatch(query); m != nil {
+		return m[1] + " " + m[2]
+	}
+
+	label := []rune(strings.Join(strings.Fields(query), " "))
+	if len(label) > operationLabelSize {
+		return string(label[:operationLabelSize]) + "..."
+	}
+
+	return string(label)
+}
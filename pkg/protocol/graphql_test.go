@@ -0,0 +1,177 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAckedGraphQL(t *testing.T) *GraphQL {
+	t.Helper()
+
+	g, err := NewGraphQL(`{"token":"abc"}`, time.Second)
+	require.NoError(t, err)
+
+	frames, err := g.Open()
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.JSONEq(t, `{"type":"connection_init","payload":{"token":"abc"}}`, string(frames[0].Data))
+
+	msg, err := g.Decode([]byte(`{"type":"connection_ack"}`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"connection_ack"}`, msg.Display)
+
+	return g
+}
+
+func TestNewGraphQL_InvalidPayload(t *testing.T) {
+	_, err := NewGraphQL(`["token"]`, 0)
+	assert.ErrorContains(t, err, "init payload should be a JSON object")
+}
+
+func TestGraphQL_Open_WithoutPayload(t *testing.T) {
+	g, err := NewGraphQL("", 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"graphql-transport-ws"}, g.Subprotocols())
+
+	frames, err := g.Open()
+	require.NoError(t, err)
+	assert.Equal(t, []core.ProtocolFrame{{Data: []byte(`{"type":"connection_init"}`), Display: `{"type":"connection_init"}`}}, frames)
+}
+
+func TestGraphQL_Subscription(t *testing.T) {
+	g := newAckedGraphQL(t)
+
+	frames, err := g.Encode("subscribe", `subscription OnMessage($room: ID!) { message(room: $room) { text } } {"room": "1"}`)
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+
+	var sent graphQLMessage
+	require.NoError(t, json.Unmarshal(frames[0].Data, &sent))
+	assert.Equal(t, "1", sent.ID)
+	assert.Equal(t, "subscribe", sent.Type)
+	assert.JSONEq(t, `{"query":"subscription OnMessage($room: ID!) { message(room: $room) { text } }","variables":{"room":"1"}}`, string(sent.Payload))
+	assert.Contains(t, frames[0].Display, `"operation":"subscription OnMessage"`)
+
+	frames, err = g.Encode("subscribe", "subscription { ticks }")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"2","type":"subscribe","payload":{"query":"subscription { ticks }"}}`, string(frames[0].Data))
+
+	msg, err := g.Decode([]byte(`{"id":"1","type":"next","payload":{"data":{"message":{"text":"hi"}}}}`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"1","operation":"subscription OnMessage","type":"next","payload":{"data":{"message":{"text":"hi"}}}}`, msg.Display)
+
+	msg, err = g.Decode([]byte(`{"id":"2","type":"error","payload":[{"message":"boom"}]}`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"2","operation":"subscription { ticks }","type":"error","payload":[{"message":"boom"}]}`, msg.Display)
+
+	_, err = g.Encode("complete", "2")
+	assert.EqualError(t, err, "unknown operation: 2", "operation is finished by the error")
+
+	frames, err = g.Encode("complete", "1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"1","type":"complete"}`, string(frames[0].Data))
+
+	_, err = g.Encode("complete", "1")
+	assert.EqualError(t, err, "unknown operation: 1")
+}
+
+func TestGraphQL_Decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr string
+		expected    core.ProtocolMessage
+	}{
+		{
+			name:     "ping",
+			data:     `{"type":"ping"}`,
+			expected: core.ProtocolMessage{Replies: []core.ProtocolFrame{{Data: []byte(`{"type":"pong"}`), Display: `{"type":"pong"}`}}},
+		},
+		{
+			name: "pong",
+			data: `{"type":"pong","payload":{}}`,
+		},
+		{
+			name:     "complete of unknown operation",
+			data:     `{"id":"9","type":"complete"}`,
+			expected: core.ProtocolMessage{Display: `{"id":"9","type":"complete"}`},
+		},
+		{
+			name:        "not json",
+			data:        "hello",
+			expectedErr: "invalid graphql-transport-ws message: hello",
+		},
+		{
+			name:        "no type",
+			data:        `{"id":"1"}`,
+			expectedErr: `invalid graphql-transport-ws message: {"id":"1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGraphQL("", 0)
+			require.NoError(t, err)
+
+			msg, err := g.Decode([]byte(tt.data), false)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestGraphQL_Encode_Errors(t *testing.T) {
+	g := newAckedGraphQL(t)
+
+	_, err := g.Encode("emit", "chat")
+	assert.ErrorIs(t, err, core.ErrUnsupportedCommand)
+
+	_, err = g.Encode("subscribe", " ")
+	assert.EqualError(t, err, "query is required")
+}
+
+func TestGraphQL_Encode_NotAcknowledged(t *testing.T) {
+	g, err := NewGraphQL("", 10*time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = g.Open()
+	require.NoError(t, err)
+
+	_, err = g.Encode("subscribe", "subscription { ticks }")
+	assert.ErrorIs(t, err, ErrNotAcknowledged)
+}
+
+func TestSplitVariables(t *testing.T) {
+	tests := []struct {
+		name              string
+		args              string
+		expectedQuery     string
+		expectedVariables string
+	}{
+		{name: "no variables", args: "subscription { ticks }", expectedQuery: "subscription { ticks }"},
+		{name: "shorthand query", args: "{ ticks }", expectedQuery: "{ ticks }"},
+		{name: "variables", args: `subscription ($id: ID!) { item(id: $id) { name } } {"id": 1}`, expectedQuery: "subscription ($id: ID!) { item(id: $id) { name } }", expectedVariables: `{"id": 1}`},
+		{name: "shorthand query with variables", args: `{ ticks } {}`, expectedQuery: "{ ticks }", expectedVariables: "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, variables := splitVariables(tt.args)
+
+			assert.Equal(t, tt.expectedQuery, query)
+			assert.Equal(t, tt.expectedVariables, string(variables))
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+const (
+	jsonRPCVersion  = "2.0"
+	callCommand     = "call"
+	subscribeSuffix = "subscribe"
+)
+
+// JSONRPC implements JSON-RPC 2.0 over WebSocket.
+// It assigns ids to calls, correlates responses with their calls and labels subscription notifications
+// with the call which created the subscription.
+type JSONRPC struct {
+	pending       map[string]string
+	subscriptions map[string]string
+	nextID        int
+	l             sync.Mutex
+}
+
+// jsonRPCMessage is a JSON-RPC 2.0 request, notification or response.
+type jsonRPCMessage struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// subscriptionParams are the params of a subscription notification, like eth_subscription.
+type subscriptionParams struct {
+	Subscription string          `json:"subscription"`
+	Result       json.RawMessage `json:"result"`
+}
+
+// jsonRPCResult is the decoded form of a message displayed to the user.
+// Type is result or error for responses, notification for notifications and request for requests of the server.
+type jsonRPCResult struct {
+	Type         string          `json:"type"`
+	ID           json.RawMessage `json:"id,omitempty"`
+	Call         string          `json:"call,omitempty"`
+	Subscription string          `json:"subscription,omitempty"`
+	Method       string          `json:"method,omitempty"`
+	Params       json.RawMessage `json:"params,omitempty"`
+	Result       json.RawMessage `json:"result,omitempty"`
+	Error        json.RawMessage `json:"error,omitempty"`
+}
+
+// NewJSONRPC creates the JSON-RPC 2.0 protocol.
+// It returns a pointer to JSONRPC.
+func NewJSONRPC() *JSONRPC {
+	return &JSONRPC{
+		pending:       make(map[string]string),
+		subscriptions: make(map[string]string),
+	}
+}
+
+// Name returns the name of the protocol.
+func (j *JSONRPC) Name() string {
+	return "JSON-RPC"
+}
+
+// PrepareURL keeps the address unchanged.
+func (j *JSONRPC) PrepareURL(*url.URL) {}
+
+// Subprotocols returns nil, JSON-RPC does not use WebSocket subprotocols.
+func (j *JSONRPC) Subprotocols() []string {
+	return nil
+}
+
+// Open forgets the subscriptions of the previous session, which do not survive a reconnection.
+// Pending calls are kept, so that commands waiting for them time out instead of printing unrelated messages.
+// It returns no frames.
+func (j *JSONRPC) Open() ([]core.ProtocolFrame, error) {
+	j.l.Lock()
+	defer j.l.Unlock()
+
+	j.subscriptions = make(map[string]string)
+
+	return nil, nil
+}
+
+// Decode decodes the incoming message.
+// Responses are labeled with their calls and notifications of subscriptions with the calls which created them.
+// It returns an error if the message is not a JSON-RPC 2.0 object.
+func (j *JSONRPC) Decode(data []byte, isBinary bool) (core.ProtocolMessage, error) {
+	if isBinary {
+		return core.ProtocolMessage{}, fmt.Errorf("unexpected binary message")
+	}
+
+	var msg jsonRPCMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Version != jsonRPCVersion {
+		return core.ProtocolMessage{}, fmt.Errorf("invalid JSON-RPC 2.0 message: %s", data)
+	}
+
+	result := jsonRPCResult{ID: msg.ID, Method: msg.Method, Params: msg.Params, Result: msg.Result, Error: msg.Error}
+
+	switch {
+	case msg.Method != "" && msg.ID == nil:
+		result.Type = "notification"
+
+		var params subscriptionParams
+		if json.Unmarshal(msg.Params, &params) == nil && params.Subscription != "" {
+			j.l.Lock()
+			result.Call = j.subscriptions[params.Subscription]
+			j.l.Unlock()
+
+			result.Subscription, result.Result, result.Params = params.Subscription, params.Result, nil
+		}
+	case msg.Method != "":
+		result.Type = "request"
+	case msg.ID != nil && (msg.Result != nil || msg.Error != nil):
+		result.Type = "result"
+		if msg.Error != nil {
+			result.Type = "error"
+		}
+
+		result.Call = j.complete(string(bytes.TrimSpace(msg.ID)), msg.Result)
+	default:
+		return core.ProtocolMessage{}, fmt.Errorf("invalid JSON-RPC 2.0 message: %s", data)
+	}
+
+	shown, err := json.Marshal(result)
+	if err != nil {
+		return core.ProtocolMessage{}, fmt.Errorf("failed to encode %s message: %w", result.Type, err)
+	}
+
+	return core.ProtocolMessage{Display: string(shown)}, nil
+}
+
+// complete removes the call with the id from the pending calls and, if the call created a subscription,
+// remembers the subscription id returned in the result.
+// It returns the label of the call, or an empty string if the call is unknown.
+func (j *JSONRPC) complete(id string, result json.RawMessage) string {
+	j.l.Lock()
+	defer j.l.Unlock()
+
+	call, ok := j.pending[id]
+	if !ok {
+		return ""
+	}
+
+	delete(j.pending, id)
+
+	method, _, _ := strings.Cut(call, " ")
+
+	var subscription string
+	if strings.HasSuffix(strings.ToLower(method), subscribeSuffix) && json.Unmarshal(result, &subscription) == nil {
+		j.subscriptions[subscription] = call
+	}
+
+	return call
+}
+
+// Encode builds the request for the call command.
+// The arguments are the method followed by optional JSON params, an array or an object: <method> [params].
+// The request gets the next id and the command waits for its response.
+// It returns ErrUnsupportedCommand for other commands and an error if the arguments are invalid.
+func (j *JSONRPC) Encode(command, args string) ([]core.ProtocolFrame, error) {
+	if command != callCommand {
+		return nil, fmt.Errorf("%w: %s", core.ErrUnsupportedCommand, command)
+	}
+
+	method, params, _ := strings.Cut(strings.TrimSpace(args), " ")
+	if method == "" {
+		return nil, fmt.Errorf("method is required")
+	}
+
+	req := jsonRPCMessage{Version: jsonRPCVersion, Method: method}
+	call := method
+
+	if params = strings.TrimSpace(params); params != "" {
+		if !json.Valid([]byte(params)) || (params[0] != '[' && params[0] != '{') {
+			return nil, fmt.Errorf("params should be a JSON array or object: %s", params)
+		}
+
+		req.Params = json.RawMessage(params)
+
+		var compact bytes.Buffer
+		if json.Compact(&compact, req.Params) == nil {
+			call += " " + compact.String()
+		}
+	}
+
+	j.l.Lock()
+	defer j.l.Unlock()
+
+	j.nextID++
+	id := strconv.Itoa(j.nextID)
+	req.ID = json.RawMessage(id)
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	j.pending[id] = call
+
+	return []core.ProtocolFrame{{Data: data, Response: id}}, nil
+}
+
+// IsResponse reports whether the displayed message is the result or the error of the call with the id.
+func (j *JSONRPC) IsResponse(msg, id string) bool {
+	var result jsonRPCResult
+	if err := json.Unmarshal([]byte(msg), &result); err != nil {
+		return false
+	}
+
+	return (result.Type == "result" || result.Type == "error") && string(bytes.TrimSpace(result.ID)) == id
+}
@@ -0,0 +1,144 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRPC_Call(t *testing.T) {
+	j := NewJSONRPC()
+
+	frames, err := j.Encode("call", `eth_getBalance ["0xabc", "latest"]`)
+	require.NoError(t, err)
+	assert.Equal(t, []core.ProtocolFrame{{
+		Data:     []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0xabc","latest"]}`),
+		Response: "1",
+	}}, frames)
+
+	frames, err = j.Encode("call", "eth_blockNumber")
+	require.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":2,"method":"eth_blockNumber"}`, string(frames[0].Data))
+
+	msg, err := j.Decode([]byte(`{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"not found"}}`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"error","id":2,"call":"eth_blockNumber","error":{"code":-32601,"message":"not found"}}`, msg.Display)
+	assert.True(t, j.IsResponse(msg.Display, "2"))
+	assert.False(t, j.IsResponse(msg.Display, "1"))
+
+	msg, err = j.Decode([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"result","id":1,"call":"eth_getBalance [\"0xabc\",\"latest\"]","result":null}`, msg.Display)
+	assert.True(t, j.IsResponse(msg.Display, "1"))
+	assert.False(t, j.IsResponse(`{"type":"notification","call":"eth_subscribe"}`, "1"))
+	assert.False(t, j.IsResponse("not json", "1"))
+}
+
+func TestJSONRPC_Subscription(t *testing.T) {
+	j := NewJSONRPC()
+
+	_, err := j.Encode("call", `eth_subscribe ["newHeads"]`)
+	require.NoError(t, err)
+
+	_, err = j.Decode([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x9ce5"}`), false)
+	require.NoError(t, err)
+
+	msg, err := j.Decode([]byte(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0x9ce5","result":{"number":"0x1b4"}}}`), false)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`{"type":"notification","call":"eth_subscribe [\"newHeads\"]","subscription":"0x9ce5","method":"eth_subscription","result":{"number":"0x1b4"}}`,
+		msg.Display,
+	)
+
+	_, err = j.Open()
+	require.NoError(t, err)
+
+	msg, err = j.Decode([]byte(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0x9ce5","result":{}}}`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"notification","subscription":"0x9ce5","method":"eth_subscription","result":{}}`, msg.Display)
+}
+
+func TestJSONRPC_Decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expected    string
+		expectedErr string
+		isBinary    bool
+	}{
+		{
+			name:     "notification",
+			data:     `{"jsonrpc":"2.0","method":"update","params":[1,2]}`,
+			expected: `{"type":"notification","method":"update","params":[1,2]}`,
+		},
+		{
+			name:     "server request",
+			data:     `{"jsonrpc":"2.0","id":"a","method":"confirm"}`,
+			expected: `{"type":"request","id":"a","method":"confirm"}`,
+		},
+		{
+			name:     "response to unknown call",
+			data:     `{"jsonrpc":"2.0","id":7,"result":true}`,
+			expected: `{"type":"result","id":7,"result":true}`,
+		},
+		{
+			name:     "error without id",
+			data:     `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`,
+			expected: `{"type":"error","id":null,"error":{"code":-32700,"message":"parse error"}}`,
+		},
+		{
+			name:        "wrong version",
+			data:        `{"jsonrpc":"1.0","id":1,"result":true}`,
+			expectedErr: `invalid JSON-RPC 2.0 message: {"jsonrpc":"1.0","id":1,"result":true}`,
+		},
+		{
+			name:        "not a message",
+			data:        `{"jsonrpc":"2.0","id":1}`,
+			expectedErr: `invalid JSON-RPC 2.0 message: {"jsonrpc":"2.0","id":1}`,
+		},
+		{
+			name:        "binary",
+			data:        "\x00",
+			isBinary:    true,
+			expectedErr: "unexpected binary message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := NewJSONRPC().Decode([]byte(tt.data), tt.isBinary)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg.Display)
+			assert.Empty(t, msg.Replies)
+		})
+	}
+}
+
+func TestJSONRPC_Encode_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		args        string
+		expectedErr string
+	}{
+		{name: "unsupported command", command: "emit", args: "x", expectedErr: "command is not supported by the protocol: emit"},
+		{name: "missing method", command: "call", args: " ", expectedErr: "method is required"},
+		{name: "scalar params", command: "call", args: "sum 1", expectedErr: "params should be a JSON array or object: 1"},
+		{name: "invalid params", command: "call", args: "sum [1,", expectedErr: "params should be a JSON array or object: [1,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJSONRPC().Encode(tt.command, tt.args)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
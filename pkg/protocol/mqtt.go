@@ -0,0 +1,800 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+// MQTT control packet types.
+const (
+	mqttConnect     byte = 1
+	mqttConnack     byte = 2
+	mqttPublish     byte = 3
+	mqttPuback      byte = 4
+	mqttPubrec      byte = 5
+	mqttPubrel      byte = 6
+	mqttPubcomp     byte = 7
+	mqttSubscribe   byte = 8
+	mqttSuback      byte = 9
+	mqttUnsubscribe byte = 10
+	mqttUnsuback    byte = 11
+	mqttPingreq     byte = 12
+	mqttPingresp    byte = 13
+	mqttDisconnect  byte = 14
+	mqttAuth        byte = 15
+)
+
+// MQTT 5 properties used by the client.
+const (
+	mqttPropServerKeepalive byte = 0x13
+	mqttPropReasonString    byte = 0x1F
+)
+
+const (
+	MQTTVersion311 = "3.1.1"
+	MQTTVersion5   = "5"
+
+	// DefaultMQTTKeepalive is the keepalive interval sent in the CONNECT packet.
+	DefaultMQTTKeepalive = 60 * time.Second
+
+	mqttConnectCommand = "mqtt-connect"
+	mqttSubCommand     = "mqtt-sub"
+	mqttPubCommand     = "mqtt-pub"
+	mqttSubprotocol    = "mqtt"
+	mqttProtocolName   = "MQTT"
+	mqttConnackID      = "connack"
+	mqttLevel311       = 4
+	mqttLevel5         = 5
+	mqttMaxQoS         = 2
+	mqttMaxUint16      = 65535
+	mqttMaxVarint      = 268435455
+	mqttVarintBytes    = 4
+	mqttConnectPoll    = time.Second
+	mqttPubrelFlags    = 0x02
+	mqttSubscribeFlags = 0x02
+)
+
+var (
+	ErrMQTTNotConnected = errors.New("MQTT session is not connected, run mqtt-connect first")
+
+	mqttPacketNames = map[byte]string{
+		mqttConnect:     "connect",
+		mqttConnack:     "connack",
+		mqttPublish:     "publish",
+		mqttPuback:      "puback",
+		mqttPubrec:      "pubrec",
+		mqttPubrel:      "pubrel",
+		mqttPubcomp:     "pubcomp",
+		mqttSubscribe:   "subscribe",
+		mqttSuback:      "suback",
+		mqttUnsubscribe: "unsubscribe",
+		mqttUnsuback:    "unsuback",
+		mqttPingreq:     "pingreq",
+		mqttPingresp:    "pingresp",
+		mqttDisconnect:  "disconnect",
+		mqttAuth:        "auth",
+	}
+
+	// mqttConnackReasons are the return codes of MQTT 3.1.1 CONNACK packets.
+	mqttConnackReasons = map[byte]string{
+		0: "accepted",
+		1: "unacceptable protocol version",
+		2: "identifier rejected",
+		3: "server unavailable",
+		4: "bad user name or password",
+		5: "not authorized",
+	}
+
+	// mqttReasons are the reason codes of MQTT 5, only the ones a client usually receives.
+	mqttReasons = map[byte]string{
+		0x00: "success",
+		0x04: "disconnect with will message",
+		0x80: "unspecified error",
+		0x81: "malformed packet",
+		0x82: "protocol error",
+		0x83: "implementation specific error",
+		0x84: "unsupported protocol version",
+		0x85: "client identifier not valid",
+		0x86: "bad user name or password",
+		0x87: "not authorized",
+		0x88: "server unavailable",
+		0x89: "server busy",
+		0x8A: "banned",
+		0x8B: "server shutting down",
+		0x8C: "bad authentication method",
+		0x8D: "keep alive timeout",
+		0x8E: "session taken over",
+		0x8F: "topic filter invalid",
+		0x90: "topic name invalid",
+		0x93: "receive maximum exceeded",
+		0x95: "packet too large",
+		0x97: "quota exceeded",
+		0x99: "payload format invalid",
+		0x9C: "use another server",
+		0x9D: "server moved",
+		0x9F: "connection rate exceeded",
+	}
+)
+
+// MQTT implements an MQTT 3.1.1 and 5 client over the mqtt WebSocket subprotocol.
+// It connects the session on request, subscribes and publishes, sends PINGREQ packets at the keepalive interval
+// and acknowledges received messages. Packets are displayed as JSON, payloads of PUBLISH packets are kept as JSON
+// if they are valid JSON and shown as text otherwise, binary payloads are base64 encoded.
+type MQTT struct {
+	pending   map[string]string
+	keepalive time.Duration
+	interval  time.Duration
+	nextID    uint16
+	level     byte
+	connected bool
+	l         sync.Mutex
+}
+
+// mqttResult is the decoded form of a packet displayed to the user.
+type mqttResult struct {
+	Type           string          `json:"type"`
+	Topic          string          `json:"topic,omitempty"`
+	ClientID       string          `json:"client_id,omitempty"`
+	Username       string          `json:"username,omitempty"`
+	Version        string          `json:"version,omitempty"`
+	Reason         string          `json:"reason,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	PayloadBase64  []byte          `json:"payload_base64,omitempty"`
+	Granted        []int           `json:"granted,omitempty"`
+	ID             int             `json:"id,omitempty"`
+	QoS            int             `json:"qos,omitempty"`
+	Keepalive      int             `json:"keepalive,omitempty"`
+	Code           int             `json:"code,omitempty"`
+	Retain         bool            `json:"retain,omitempty"`
+	SessionPresent bool            `json:"session_present,omitempty"`
+}
+
+// NewMQTT creates the MQTT protocol.
+// It takes version of type string, 3.1.1 or 5, 3.1.1 is used if it is empty,
+// and keepalive of type time.Duration, the keepalive interval sent to the broker,
+// DefaultMQTTKeepalive is used if it is zero and negative value disables keepalive.
+// It returns a pointer to MQTT and an error if the version is not supported.
+func NewMQTT(version string, keepalive time.Duration) (*MQTT, error) {
+	m := &MQTT{
+		pending:   make(map[string]string),
+		keepalive: keepalive,
+	}
+
+	switch version {
+	case "", MQTTVersion311:
+		m.level = mqttLevel311
+	case MQTTVersion5:
+		m.level = mqttLevel5
+	default:
+		return nil, fmt.Errorf("unsupported MQTT version %q: should be %s or %s", version, MQTTVersion311, MQTTVersion5)
+	}
+
+	if m.keepalive == 0 {
+		m.keepalive = DefaultMQTTKeepalive
+	}
+
+	return m, nil
+}
+
+// Name returns the name of the protocol.
+func (m *MQTT) Name() string {
+	return mqttProtocolName
+}
+
+// PrepareURL keeps the address unchanged.
+func (m *MQTT) PrepareURL(*url.URL) {}
+
+// Subprotocols returns the mqtt subprotocol, which brokers require to be negotiated.
+func (m *MQTT) Subprotocols() []string {
+	return []string{mqttSubprotocol}
+}
+
+// Open forgets the session of the previous connection, mqtt-connect has to be run again after reconnection.
+// It returns no frames.
+func (m *MQTT) Open() ([]core.ProtocolFrame, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.connected = false
+	m.interval = 0
+	m.nextID = 0
+
+	return nil, nil
+}
+
+// Decode decodes the packets of the incoming message.
+// It acknowledges PUBLISH packets with QoS 1 and 2 and completes the QoS 2 flows, these replies and PINGRESP packets
+// are not displayed. If the message carries several packets, their decoded forms are separated by new lines.
+// It returns an error if the message is a text message or is not a sequence of complete MQTT packets.
+func (m *MQTT) Decode(data []byte, isBinary bool) (core.ProtocolMessage, error) {
+	if !isBinary {
+		return core.ProtocolMessage{}, fmt.Errorf("unexpected text message")
+	}
+
+	var (
+		msg      core.ProtocolMessage
+		displays []string
+	)
+
+	for len(data) > 0 {
+		header, body, rest, err := splitMQTTPacket(data)
+		if err != nil {
+			return core.ProtocolMessage{}, err
+		}
+
+		shown, replies, err := m.decodePacket(header, body)
+		if err != nil {
+			return core.ProtocolMessage{}, err
+		}
+
+		if shown != "" {
+			displays = append(displays, shown)
+		}
+
+		msg.Replies = append(msg.Replies, replies...)
+		data = rest
+	}
+
+	msg.Display = strings.Join(displays, "\n")
+
+	return msg, nil
+}
+
+// decodePacket decodes the packet with the fixed header byte and the body following the remaining length.
+// It returns the decoded form of the packet, which is empty for hidden packets, the replies and an error
+// if the packet is malformed.
+func (m *MQTT) decodePacket(header byte, body []byte) (string, []core.ProtocolFrame, error) {
+	packetType, flags := header>>4, header&0x0F
+	r := &mqttReader{data: body}
+	result := mqttResult{Type: mqttPacketNames[packetType]}
+
+	var replies []core.ProtocolFrame
+
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	switch packetType {
+	case mqttConnack:
+		result.SessionPresent = r.byte()&1 == 1
+		code := r.byte()
+		result.Code, result.Reason = int(code), m.reason(code)
+		props := m.properties(r)
+
+		if value, ok := props[mqttPropReasonString]; ok {
+			result.Reason = string(value)
+		}
+
+		if code == 0 && r.err == nil {
+			m.connected = true
+			m.interval = m.keepalive
+
+			if value, ok := props[mqttPropServerKeepalive]; ok && len(value) == 2 {
+				m.interval = time.Duration(binary.BigEndian.Uint16(value)) * time.Second
+			}
+		}
+	case mqttPublish:
+		result.QoS, result.Retain = int(flags>>1&0x03), flags&1 == 1
+		result.Topic = r.string()
+
+		var id uint16
+		if result.QoS > 0 {
+			id = r.uint16()
+			result.ID = int(id)
+		}
+
+		m.properties(r)
+		result.Payload, result.PayloadBase64 = mqttPayload(r.rest())
+
+		switch result.QoS {
+		case 1:
+			replies = append(replies, mqttAck(mqttPuback, 0, id))
+		case mqttMaxQoS:
+			replies = append(replies, mqttAck(mqttPubrec, 0, id))
+		}
+	case mqttPubrec, mqttPubrel:
+		id := r.uint16()
+		if err := r.failed(result.Type); err != nil {
+			return "", nil, err
+		}
+
+		// The QoS 2 flow of received messages ends with PUBCOMP, the one of published messages with PUBREL.
+		ack := mqttAck(mqttPubcomp, 0, id)
+		if packetType == mqttPubrec {
+			ack = mqttAck(mqttPubrel, mqttPubrelFlags, id)
+		}
+
+		return "", []core.ProtocolFrame{ack}, nil
+	case mqttPingresp:
+		return "", nil, nil
+	case mqttPuback, mqttPubcomp, mqttUnsuback:
+		result.ID = int(r.uint16())
+	case mqttSuback:
+		result.ID = int(r.uint16())
+		m.properties(r)
+
+		for _, code := range r.rest() {
+			result.Granted = append(result.Granted, int(code))
+		}
+
+		id := strconv.Itoa(result.ID)
+		result.Topic = m.pending[id]
+		delete(m.pending, id)
+	case mqttDisconnect:
+		m.connected = false
+
+		// Only MQTT 5 brokers send DISCONNECT, with an optional reason code and properties.
+		if m.level == mqttLevel5 && len(body) > 0 {
+			code := r.byte()
+			result.Code, result.Reason = int(code), m.reason(code)
+		}
+
+		if m.level == mqttLevel5 && len(body) > 1 {
+			if value, ok := r.properties()[mqttPropReasonString]; ok {
+				result.Reason = string(value)
+			}
+		}
+	default:
+		if result.Type == "" {
+			return "", nil, fmt.Errorf("unknown MQTT packet type: %d", packetType)
+		}
+	}
+
+	if err := r.failed(result.Type); err != nil {
+		return "", nil, err
+	}
+
+	shown, err := json.Marshal(result)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode %s packet: %w", result.Type, err)
+	}
+
+	return string(shown), replies, nil
+}
+
+// reason returns the description of the CONNACK return code or the MQTT 5 reason code.
+func (m *MQTT) reason(code byte) string {
+	if m.level == mqttLevel5 {
+		return mqttReasons[code]
+	}
+
+	return mqttConnackReasons[code]
+}
+
+// properties reads the MQTT 5 properties, packets of MQTT 3.1.1 have no properties.
+func (m *MQTT) properties(r *mqttReader) map[byte][]byte {
+	if m.level != mqttLevel5 {
+		return nil
+	}
+
+	return r.properties()
+}
+
+// Encode builds the packets for the mqtt-connect, mqtt-sub and mqtt-pub commands.
+// The optional arguments of mqtt-connect are the client id, the user name and the password: [client-id] [username] [password],
+// the broker assigns the client id if it is empty. The command waits for the CONNACK packet.
+// The arguments of mqtt-sub are the topic filter followed by the optional QoS: <topic> [qos], the command waits for the SUBACK packet.
+// The arguments of mqtt-pub are the topic followed by the payload: <topic> <payload>, messages are published with QoS 0.
+// It returns ErrUnsupportedCommand for other commands, ErrMQTTNotConnected if mqtt-sub or mqtt-pub is run
+// before the session is connected and an error if the arguments are invalid.
+func (m *MQTT) Encode(command, args string) ([]core.ProtocolFrame, error) {
+	args = strings.TrimSpace(args)
+
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	switch command {
+	case mqttConnectCommand:
+		return m.encodeConnect(strings.Fields(args))
+	case mqttSubCommand, mqttPubCommand:
+		if !m.connected {
+			return nil, ErrMQTTNotConnected
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", core.ErrUnsupportedCommand, command)
+	}
+
+	topic, rest, _ := strings.Cut(args, " ")
+	if topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+
+	if command == mqttSubCommand {
+		return m.encodeSubscribe(topic, strings.TrimSpace(rest))
+	}
+
+	if strings.ContainsAny(topic, "+#") {
+		return nil, fmt.Errorf("topic name should not contain wildcards: %s", topic)
+	}
+
+	payload := []byte(strings.TrimSpace(rest))
+
+	body := appendMQTTString(nil, topic)
+	body = m.appendProperties(body)
+	body = append(body, payload...)
+
+	result := mqttResult{Type: mqttPacketNames[mqttPublish], Topic: topic}
+	result.Payload, result.PayloadBase64 = mqttPayload(payload)
+
+	return encodeMQTT(mqttPublish<<4, body, result, "")
+}
+
+// encodeConnect builds the CONNECT packet with the client id, the user name and the password from the arguments.
+// It returns an error if there are too many arguments or the session is already connected.
+func (m *MQTT) encodeConnect(args []string) ([]core.ProtocolFrame, error) {
+	const (
+		maxArgs      = 3
+		cleanSession = 0x02
+		passwordFlag = 0x40
+		usernameFlag = 0x80
+	)
+
+	if len(args) > maxArgs {
+		return nil, fmt.Errorf("too many arguments, expected [client-id] [username] [password]")
+	}
+
+	if m.connected {
+		return nil, fmt.Errorf("MQTT session is already connected")
+	}
+
+	args = append(args, make([]string, maxArgs-len(args))...)
+	clientID, username, password := args[0], args[1], args[2]
+
+	var keepalive uint16
+	if m.keepalive > 0 {
+		keepalive = uint16(min(max(m.keepalive.Round(time.Second)/time.Second, 1), mqttMaxUint16)) //nolint:gosec // clamped to the uint16 range
+	}
+
+	flags := byte(cleanSession)
+	if username != "" {
+		flags |= usernameFlag
+	}
+
+	if password != "" {
+		flags |= passwordFlag
+	}
+
+	body := appendMQTTString(nil, mqttProtocolName)
+	body = append(body, m.level, flags)
+	body = binary.BigEndian.AppendUint16(body, keepalive)
+	body = m.appendProperties(body)
+	body = appendMQTTString(body, clientID)
+
+	if username != "" {
+		body = appendMQTTString(body, username)
+	}
+
+	if password != "" {
+		body = appendMQTTString(body, password)
+	}
+
+	version := MQTTVersion311
+	if m.level == mqttLevel5 {
+		version = MQTTVersion5
+	}
+
+	result := mqttResult{
+		Type:      mqttPacketNames[mqttConnect],
+		ClientID:  clientID,
+		Username:  username,
+		Version:   version,
+		Keepalive: int(keepalive),
+	}
+
+	return encodeMQTT(mqttConnect<<4, body, result, mqttConnackID)
+}
+
+// encodeSubscribe builds the SUBSCRIBE packet for the topic filter with the next packet id.
+// It returns an error if the QoS is not 0, 1 or 2.
+func (m *MQTT) encodeSubscribe(topic, qosArg string) ([]core.ProtocolFrame, error) {
+	qos := 0
+
+	if qosArg != "" {
+		var err error
+		if qos, err = strconv.Atoi(qosArg); err != nil || qos < 0 || qos > mqttMaxQoS {
+			return nil, fmt.Errorf("QoS should be 0, 1 or 2: %s", qosArg)
+		}
+	}
+
+	m.nextID++
+	if m.nextID == 0 {
+		m.nextID = 1
+	}
+
+	body := binary.BigEndian.AppendUint16(nil, m.nextID)
+	body = m.appendProperties(body)
+	body = appendMQTTString(body, topic)
+	body = append(body, byte(qos))
+
+	id := strconv.Itoa(int(m.nextID))
+	m.pending[id] = topic
+
+	result := mqttResult{Type: mqttPacketNames[mqttSubscribe], Topic: topic, ID: int(m.nextID), QoS: qos}
+
+	return encodeMQTT(mqttSubscribe<<4|mqttSubscribeFlags, body, result, id)
+}
+
+// appendProperties appends the empty property list of MQTT 5 packets.
+func (m *MQTT) appendProperties(body []byte) []byte {
+	if m.level != mqttLevel5 {
+		return body
+	}
+
+	return append(body, 0)
+}
+
+// IsResponse reports whether the displayed message is the CONNACK or the SUBACK packet with the correlation id.
+func (m *MQTT) IsResponse(msg, id string) bool {
+	var result mqttResult
+	if err := json.Unmarshal([]byte(msg), &result); err != nil {
+		return false
+	}
+
+	if id == mqttConnackID {
+		return result.Type == mqttPacketNames[mqttConnack]
+	}
+
+	return result.Type == mqttPacketNames[mqttSuback] && strconv.Itoa(result.ID) == id
+}
+
+// HeartbeatInterval returns the keepalive interval of the connected session, the broker may override it in MQTT 5.
+// Until the session is connected the interval is short, so that pings start soon after mqtt-connect.
+func (m *MQTT) HeartbeatInterval() time.Duration {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.keepalive <= 0 {
+		return 0
+	}
+
+	if !m.connected {
+		return mqttConnectPoll
+	}
+
+	return m.interval
+}
+
+// Heartbeat returns the PINGREQ packet, or no frames if the session is not connected.
+func (m *MQTT) Heartbeat() ([]core.ProtocolFrame, error) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if !m.connected || m.interval <= 0 {
+		return nil, nil
+	}
+
+	return []core.ProtocolFrame{{Data: []byte{mqttPingreq << 4, 0}, Binary: true}}, nil
+}
+
+// splitMQTTPacket splits the first packet off the data.
+// It returns the fixed header byte, the body of the packet, the rest of the data and an error if the packet is incomplete.
+func splitMQTTPacket(data []byte) (byte, []byte, []byte, error) {
+	r := &mqttReader{data: data}
+	header := r.byte()
+	length := r.varint()
+
+	if r.err != nil || length > len(r.data) {
+		return 0, nil, nil, fmt.Errorf("incomplete MQTT packet")
+	}
+
+	return header, r.data[:length], r.data[length:], nil
+}
+
+// encodeMQTT builds the binary frame of the packet with the fixed header byte and the body,
+// displayed as the result and waiting for the response with the correlation id if it is not empty.
+func encodeMQTT(header byte, body []byte, result mqttResult, response string) ([]core.ProtocolFrame, error) {
+	if len(body) > mqttMaxVarint {
+		return nil, fmt.Errorf("%s packet is too large", result.Type)
+	}
+
+	shown, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s packet: %w", result.Type, err)
+	}
+
+	data := appendMQTTVarint([]byte{header}, len(body))
+	data = append(data, body...)
+
+	return []core.ProtocolFrame{{Data: data, Display: string(shown), Response: response, Binary: true}}, nil
+}
+
+// mqttAck returns the acknowledgement packet of the type with the flags for the packet id, it is not displayed.
+func mqttAck(packetType, flags byte, id uint16) core.ProtocolFrame {
+	const ackLength = 2
+
+	data := binary.BigEndian.AppendUint16([]byte{packetType<<4 | flags, ackLength}, id)
+
+	return core.ProtocolFrame{Data: data, Binary: true}
+}
+
+// mqttPayload returns the payload as JSON if it is valid JSON, as a JSON string if it is text,
+// or as raw bytes to be base64 encoded otherwise.
+func mqttPayload(payload []byte) (json.RawMessage, []byte) {
+	switch {
+	case len(payload) == 0:
+		return nil, nil
+	case json.Valid(payload):
+		return json.RawMessage(payload), nil
+	case utf8.Valid(payload):
+		text, err := json.Marshal(string(payload))
+		if err != nil {
+			return nil, payload
+		}
+
+		return text, nil
+	default:
+		return nil, payload
+	}
+}
+
+// appendMQTTString appends the string prefixed with its length.
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s))) //nolint:gosec // strings of the CLI are far shorter than 64 KiB
+	return append(b, s...)
+}
+
+// appendMQTTVarint appends the variable byte integer.
+func appendMQTTVarint(b []byte, n int) []byte {
+	const (
+		continuation = 0x80
+		digit        = 0x7F
+		shift        = 7
+	)
+
+	for {
+		encoded := byte(n & digit)
+		n >>= shift
+
+		if n == 0 {
+			return append(b, encoded)
+		}
+
+		b = append(b, encoded|continuation)
+	}
+}
+
+// mqttReader reads the fields of a packet, the first error stops reading and is kept in err.
+type mqttReader struct {
+	err  error
+	data []byte
+}
+
+// bytes reads n bytes.
+func (r *mqttReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if n > len(r.data) {
+		r.err = fmt.Errorf("unexpected end of packet")
+		return nil
+	}
+
+	b := r.data[:n]
+	r.data = r.data[n:]
+
+	return b
+}
+
+// byte reads a single byte.
+func (r *mqttReader) byte() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+
+	return 0
+}
+
+// uint16 reads a two byte integer.
+func (r *mqttReader) uint16() uint16 {
+	const size = 2
+
+	if b := r.bytes(size); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+
+	return 0
+}
+
+// string reads a string prefixed with its length.
+func (r *mqttReader) string() string {
+	return string(r.bytes(int(r.uint16())))
+}
+
+// varint reads a variable byte integer.
+func (r *mqttReader) varint() int {
+	const (
+		continuation = 0x80
+		digit        = 0x7F
+		shift        = 7
+	)
+
+	n := 0
+
+	for i := range mqttVarintBytes {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+
+		n |= int(b&digit) << (shift * i)
+
+		if b&continuation == 0 {
+			return n
+		}
+	}
+
+	r.err = fmt.Errorf("invalid variable byte integer")
+
+	return 0
+}
+
+// rest reads the remaining bytes.
+func (r *mqttReader) rest() []byte {
+	return r.bytes(len(r.data))
+}
+
+// properties reads the MQTT 5 property list.
+// It returns the values of the properties by their identifiers, strings and binary data without their length.
+func (r *mqttReader) properties() map[byte][]byte {
+	const (
+		uint16Size = 2
+		uint32Size = 4
+		userProp   = 0x26
+	)
+
+	list := &mqttReader{data: r.bytes(r.varint())}
+	props := make(map[byte][]byte)
+
+	for r.err == nil && list.err == nil && len(list.data) > 0 {
+		id := list.byte()
+
+		var value []byte
+
+		switch id {
+		case 0x01, 0x17, 0x19, 0x24, 0x25, 0x28, 0x29, 0x2A:
+			value = list.bytes(1)
+		case 0x13, 0x21, 0x22, 0x23:
+			value = list.bytes(uint16Size)
+		case 0x02, 0x11, 0x18, 0x27:
+			value = list.bytes(uint32Size)
+		case 0x0B:
+			list.varint()
+		case 0x03, 0x08, 0x09, 0x12, 0x15, 0x16, 0x1A, 0x1C, 0x1F:
+			value = list.bytes(int(list.uint16()))
+		case userProp:
+			list.string()
+			list.string()
+		default:
+			list.err = fmt.Errorf("unknown property: %#x", id)
+		}
+
+		if _, ok := props[id]; !ok && value != nil {
+			props[id] = value
+		}
+	}
+
+	if r.err == nil {
+		r.err = list.err
+	}
+
+	return props
+}
+
+// failed returns an error describing the malformed packet of the type if reading failed.
+func (r *mqttReader) failed(packetType string) error {
+	if r.err != nil {
+		return fmt.Errorf("malformed MQTT %s packet: %w", packetType, r.err)
+	}
+
+	return nil
+}
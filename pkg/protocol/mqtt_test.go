@@ -0,0 +1,382 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConnectedMQTT(t *testing.T, version string) *MQTT {
+	t.Helper()
+
+	m, err := NewMQTT(version, 0)
+	require.NoError(t, err)
+
+	_, err = m.Encode("mqtt-connect", "")
+	require.NoError(t, err)
+
+	connack := []byte{0x20, 0x02, 0x00, 0x00}
+	if version == MQTTVersion5 {
+		connack = []byte{0x20, 0x03, 0x00, 0x00, 0x00}
+	}
+
+	_, err = m.Decode(connack, true)
+	require.NoError(t, err)
+
+	return m
+}
+
+func TestNewMQTT_InvalidVersion(t *testing.T) {
+	proto, err := New(NameMQTT, Options{MQTTVersion: "3.1"})
+
+	assert.EqualError(t, err, `unsupported MQTT version "3.1": should be 3.1.1 or 5`)
+	assert.Nil(t, proto)
+}
+
+func TestMQTT_Connect(t *testing.T) {
+	tests := []struct {
+		name          string
+		version       string
+		args          string
+		expectedShown string
+		expectedData  []byte
+	}{
+		{
+			name:          "3.1.1 without credentials",
+			version:       MQTTVersion311,
+			expectedData:  []byte{0x10, 0x0C, 0x00, 0x04, 'M', 'Q', 'T', 'T', 0x04, 0x02, 0x00, 0x3C, 0x00, 0x00},
+			expectedShown: `{"type":"connect","version":"3.1.1","keepalive":60}`,
+		},
+		{
+			name:    "3.1.1 with credentials",
+			version: MQTTVersion311,
+			args:    "dev u p",
+			expectedData: []byte{
+				0x10, 0x15, 0x00, 0x04, 'M', 'Q', 'T', 'T', 0x04, 0xC2, 0x00, 0x3C,
+				0x00, 0x03, 'd', 'e', 'v', 0x00, 0x01, 'u', 0x00, 0x01, 'p',
+			},
+			expectedShown: `{"type":"connect","client_id":"dev","username":"u","version":"3.1.1","keepalive":60}`,
+		},
+		{
+			name:          "5 with client id",
+			version:       MQTTVersion5,
+			args:          "dev",
+			expectedData:  []byte{0x10, 0x10, 0x00, 0x04, 'M', 'Q', 'T', 'T', 0x05, 0x02, 0x00, 0x3C, 0x00, 0x00, 0x03, 'd', 'e', 'v'},
+			expectedShown: `{"type":"connect","client_id":"dev","version":"5","keepalive":60}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMQTT(tt.version, 0)
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"mqtt"}, m.Subprotocols())
+
+			frames, err := m.Open()
+			require.NoError(t, err)
+			assert.Empty(t, frames)
+
+			frames, err = m.Encode("mqtt-connect", tt.args)
+			require.NoError(t, err)
+			require.Len(t, frames, 1)
+			assert.Equal(t, tt.expectedData, frames[0].Data)
+			assert.Equal(t, tt.expectedShown, frames[0].Display)
+			assert.Equal(t, "connack", frames[0].Response)
+			assert.True(t, frames[0].Binary)
+			assert.False(t, m.IsResponse(frames[0].Display, "connack"))
+		})
+	}
+}
+
+func TestMQTT_Connack(t *testing.T) {
+	tests := []struct {
+		name             string
+		version          string
+		expectedShown    string
+		packet           []byte
+		expectedInterval time.Duration
+	}{
+		{
+			name:             "3.1.1 accepted",
+			version:          MQTTVersion311,
+			packet:           []byte{0x20, 0x02, 0x01, 0x00},
+			expectedShown:    `{"type":"connack","reason":"accepted","session_present":true}`,
+			expectedInterval: 60 * time.Second,
+		},
+		{
+			name:             "3.1.1 refused",
+			version:          MQTTVersion311,
+			packet:           []byte{0x20, 0x02, 0x00, 0x05},
+			expectedShown:    `{"type":"connack","reason":"not authorized","code":5}`,
+			expectedInterval: time.Second,
+		},
+		{
+			name:             "5 with server keepalive",
+			version:          MQTTVersion5,
+			packet:           []byte{0x20, 0x06, 0x00, 0x00, 0x03, 0x13, 0x00, 0x0A},
+			expectedShown:    `{"type":"connack","reason":"success"}`,
+			expectedInterval: 10 * time.Second,
+		},
+		{
+			name:             "5 refused with reason string",
+			version:          MQTTVersion5,
+			packet:           []byte{0x20, 0x09, 0x00, 0x86, 0x06, 0x1F, 0x00, 0x03, 'b', 'a', 'd'},
+			expectedShown:    `{"type":"connack","reason":"bad","code":134}`,
+			expectedInterval: time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMQTT(tt.version, 0)
+			require.NoError(t, err)
+
+			_, err = m.Encode("mqtt-connect", "")
+			require.NoError(t, err)
+
+			msg, err := m.Decode(tt.packet, true)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedShown, msg.Display)
+			assert.True(t, m.IsResponse(msg.Display, "connack"))
+			assert.Equal(t, tt.expectedInterval, m.HeartbeatInterval())
+		})
+	}
+}
+
+func TestMQTT_Keepalive(t *testing.T) {
+	disabled, err := NewMQTT("", -1)
+	require.NoError(t, err)
+	assert.Zero(t, disabled.HeartbeatInterval())
+
+	frames, err := disabled.Encode("mqtt-connect", "")
+	require.NoError(t, err)
+	assert.Contains(t, frames[0].Display, `"type":"connect"`)
+	assert.NotContains(t, frames[0].Display, "keepalive")
+
+	m, err := NewMQTT("", 0)
+	require.NoError(t, err)
+
+	frames, err = m.Heartbeat()
+	require.NoError(t, err)
+	assert.Empty(t, frames, "pings are not sent before the session is connected")
+
+	m = newConnectedMQTT(t, MQTTVersion311)
+
+	frames, err = m.Heartbeat()
+	require.NoError(t, err)
+	assert.Equal(t, []core.ProtocolFrame{{Data: []byte{0xC0, 0x00}, Binary: true}}, frames)
+
+	msg, err := m.Decode([]byte{0xD0, 0x00}, true)
+	require.NoError(t, err)
+	assert.Equal(t, core.ProtocolMessage{}, msg, "PINGRESP is not displayed")
+
+	_, err = m.Open()
+	require.NoError(t, err)
+
+	frames, err = m.Heartbeat()
+	require.NoError(t, err)
+	assert.Empty(t, frames, "session does not survive reconnection")
+}
+
+func TestMQTT_Subscribe(t *testing.T) {
+	m := newConnectedMQTT(t, MQTTVersion311)
+
+	frames, err := m.Encode("mqtt-sub", "sensors/+/temp 1")
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, []byte{0x82, 0x13, 0x00, 0x01, 0x00, 0x0E, 's', 'e', 'n', 's', 'o', 'r', 's', '/', '+', '/', 't', 'e', 'm', 'p', 0x01}, frames[0].Data)
+	assert.Equal(t, `{"type":"subscribe","topic":"sensors/+/temp","id":1,"qos":1}`, frames[0].Display)
+	assert.Equal(t, "1", frames[0].Response)
+
+	msg, err := m.Decode([]byte{0x90, 0x03, 0x00, 0x01, 0x01}, true)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"suback","topic":"sensors/+/temp","granted":[1],"id":1}`, msg.Display)
+	assert.True(t, m.IsResponse(msg.Display, "1"))
+	assert.False(t, m.IsResponse(msg.Display, "2"))
+	assert.False(t, m.IsResponse(msg.Display, "connack"))
+
+	m5 := newConnectedMQTT(t, MQTTVersion5)
+
+	frames, err = m5.Encode("mqtt-sub", "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x82, 0x07, 0x00, 0x01, 0x00, 0x00, 0x01, 'a', 0x00}, frames[0].Data)
+
+	msg, err = m5.Decode([]byte{0x90, 0x04, 0x00, 0x01, 0x00, 0x80}, true)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"suback","topic":"a","granted":[128],"id":1}`, msg.Display)
+}
+
+func TestMQTT_Publish(t *testing.T) {
+	m := newConnectedMQTT(t, MQTTVersion311)
+
+	frames, err := m.Encode("mqtt-pub", `a/b {"t": 21}`)
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, append([]byte{0x30, 0x0E, 0x00, 0x03, 'a', '/', 'b'}, `{"t": 21}`...), frames[0].Data)
+	assert.Equal(t, `{"type":"publish","topic":"a/b","payload":{"t":21}}`, frames[0].Display)
+	assert.Empty(t, frames[0].Response)
+
+	m5 := newConnectedMQTT(t, MQTTVersion5)
+
+	frames, err = m5.Encode("mqtt-pub", "a hi")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x30, 0x06, 0x00, 0x01, 'a', 0x00, 'h', 'i'}, frames[0].Data)
+	assert.Equal(t, `{"type":"publish","topic":"a","payload":"hi"}`, frames[0].Display)
+}
+
+func TestMQTT_Decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		expectedErr string
+		packet      []byte
+		expected    core.ProtocolMessage
+		isText      bool
+	}{
+		{
+			name:     "publish QoS 0 with JSON payload",
+			packet:   append([]byte{0x30, 0x0C, 0x00, 0x03, 'a', '/', 'b'}, `{"t":1}`...),
+			expected: core.ProtocolMessage{Display: `{"type":"publish","topic":"a/b","payload":{"t":1}}`},
+		},
+		{
+			name:   "publish QoS 1 retained",
+			packet: []byte{0x33, 0x07, 0x00, 0x01, 'a', 0x00, 0x07, 'h', 'i'},
+			expected: core.ProtocolMessage{
+				Display: `{"type":"publish","topic":"a","payload":"hi","id":7,"qos":1,"retain":true}`,
+				Replies: []core.ProtocolFrame{{Data: []byte{0x40, 0x02, 0x00, 0x07}, Binary: true}},
+			},
+		},
+		{
+			name:   "publish QoS 2 with binary payload",
+			packet: []byte{0x34, 0x07, 0x00, 0x01, 'a', 0x00, 0x08, 0xFF, 0xFE},
+			expected: core.ProtocolMessage{
+				Display: `{"type":"publish","topic":"a","payload_base64":"//4=","id":8,"qos":2}`,
+				Replies: []core.ProtocolFrame{{Data: []byte{0x50, 0x02, 0x00, 0x08}, Binary: true}},
+			},
+		},
+		{
+			name:     "publish of MQTT 5 with properties",
+			version:  MQTTVersion5,
+			packet:   []byte{0x30, 0x0D, 0x00, 0x01, 'a', 0x07, 0x03, 0x00, 0x04, 't', 'e', 'x', 't', 'h', 'i'},
+			expected: core.ProtocolMessage{Display: `{"type":"publish","topic":"a","payload":"hi"}`},
+		},
+		{
+			name:     "pubrel completes QoS 2",
+			packet:   []byte{0x62, 0x02, 0x00, 0x08},
+			expected: core.ProtocolMessage{Replies: []core.ProtocolFrame{{Data: []byte{0x70, 0x02, 0x00, 0x08}, Binary: true}}},
+		},
+		{
+			name:     "pubrec is released",
+			packet:   []byte{0x50, 0x02, 0x00, 0x03},
+			expected: core.ProtocolMessage{Replies: []core.ProtocolFrame{{Data: []byte{0x62, 0x02, 0x00, 0x03}, Binary: true}}},
+		},
+		{
+			name:     "several packets",
+			packet:   []byte{0xD0, 0x00, 0x30, 0x04, 0x00, 0x01, 'a', '1', 0x30, 0x04, 0x00, 0x01, 'b', '2'},
+			expected: core.ProtocolMessage{Display: "{\"type\":\"publish\",\"topic\":\"a\",\"payload\":1}\n{\"type\":\"publish\",\"topic\":\"b\",\"payload\":2}"},
+		},
+		{
+			name:     "disconnect of MQTT 5",
+			version:  MQTTVersion5,
+			packet:   []byte{0xE0, 0x01, 0x8E},
+			expected: core.ProtocolMessage{Display: `{"type":"disconnect","reason":"session taken over","code":142}`},
+		},
+		{
+			name:        "text message",
+			packet:      []byte("hello"),
+			isText:      true,
+			expectedErr: "unexpected text message",
+		},
+		{
+			name:        "incomplete packet",
+			packet:      []byte{0x30, 0x05, 0x00},
+			expectedErr: "incomplete MQTT packet",
+		},
+		{
+			name:        "malformed packet",
+			packet:      []byte{0x30, 0x02, 0x00, 0x05},
+			expectedErr: "malformed MQTT publish packet: unexpected end of packet",
+		},
+		{
+			name:        "reserved packet type",
+			packet:      []byte{0x00, 0x00},
+			expectedErr: "unknown MQTT packet type: 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMQTT(tt.version, 0)
+			require.NoError(t, err)
+
+			msg, err := m.Decode(tt.packet, !tt.isText)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestMQTT_Encode_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		args        string
+		expectedErr string
+		connected   bool
+	}{
+		{name: "unsupported command", command: "emit", args: "chat", expectedErr: "command is not supported by the protocol: emit"},
+		{name: "subscribe before connect", command: "mqtt-sub", args: "a", expectedErr: ErrMQTTNotConnected.Error()},
+		{name: "publish before connect", command: "mqtt-pub", args: "a hi", expectedErr: ErrMQTTNotConnected.Error()},
+		{name: "too many connect arguments", command: "mqtt-connect", args: "a b c d", expectedErr: "too many arguments, expected [client-id] [username] [password]"},
+		{name: "already connected", command: "mqtt-connect", connected: true, expectedErr: "MQTT session is already connected"},
+		{name: "invalid QoS", command: "mqtt-sub", args: "a 3", connected: true, expectedErr: "QoS should be 0, 1 or 2: 3"},
+		{name: "wildcard topic", command: "mqtt-pub", args: "a/# hi", connected: true, expectedErr: "topic name should not contain wildcards: a/#"},
+		{name: "missing topic", command: "mqtt-pub", args: " ", connected: true, expectedErr: "topic is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMQTT("", 0)
+			require.NoError(t, err)
+
+			if tt.connected {
+				m = newConnectedMQTT(t, MQTTVersion311)
+			}
+
+			_, err = m.Encode(tt.command, tt.args)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestAppendMQTTVarint(t *testing.T) {
+	tests := []struct {
+		expected []byte
+		n        int
+	}{
+		{n: 0, expected: []byte{0x00}},
+		{n: 127, expected: []byte{0x7F}},
+		{n: 128, expected: []byte{0x80, 0x01}},
+		{n: 16383, expected: []byte{0xFF, 0x7F}},
+		{n: 2097152, expected: []byte{0x80, 0x80, 0x80, 0x01}},
+	}
+
+	for _, tt := range tests {
+		encoded := appendMQTTVarint(nil, tt.n)
+		assert.Equal(t, tt.expected, encoded)
+
+		r := &mqttReader{data: encoded}
+		assert.Equal(t, tt.n, r.varint())
+		assert.NoError(t, r.err)
+	}
+}
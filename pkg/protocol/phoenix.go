@@ -0,0 +1,348 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+// Phoenix Channels events.
+const (
+	phxJoin      = "phx_join"
+	phxLeave     = "phx_leave"
+	phxReply     = "phx_reply"
+	phxError     = "phx_error"
+	phxClose     = "phx_close"
+	phxHeartbeat = "heartbeat"
+	phxTopic     = "phoenix"
+)
+
+const (
+	joinCommand       = "join"
+	leaveCommand      = "leave"
+	pushCommand       = "push"
+	phoenixVersion    = "2.0.0"
+	phoenixPathSuffix = "/websocket"
+
+	// DefaultPhoenixHeartbeat is the interval of heartbeats Phoenix servers expect by default.
+	DefaultPhoenixHeartbeat = 30 * time.Second
+)
+
+// Phoenix implements the Phoenix Channels protocol with the JSON serializer version 2.
+// It joins and leaves channels, sends heartbeats and correlates replies with the pushes they answer.
+type Phoenix struct {
+	pending   map[string]phoenixPush
+	joins     map[string]string
+	heartbeat time.Duration
+	nextRef   int
+	l         sync.Mutex
+}
+
+// phoenixPush is a message sent to the server which waits for its reply.
+type phoenixPush struct {
+	topic string
+	event string
+}
+
+// phoenixMessage is a message of the Phoenix Channels protocol, refs are nil for messages not bound to a push.
+type phoenixMessage struct {
+	joinRef *string
+	ref     *string
+	topic   string
+	event   string
+	payload json.RawMessage
+}
+
+// phoenixReply is the payload of the phx_reply event.
+type phoenixReply struct {
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+// phoenixResult is the decoded form of a message displayed to the user.
+// Type is push for sent messages, reply for replies, which carry the event of their push, and event for broadcasts.
+type phoenixResult struct {
+	Type    string          `json:"type"`
+	Topic   string          `json:"topic"`
+	Event   string          `json:"event"`
+	Ref     string          `json:"ref,omitempty"`
+	Status  string          `json:"status,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// NewPhoenix creates the Phoenix Channels protocol.
+// It takes heartbeat of type time.Duration, the interval of heartbeats,
+// DefaultPhoenixHeartbeat is used if it is zero and negative value disables heartbeats.
+// It returns a pointer to Phoenix.
+func NewPhoenix(heartbeat time.Duration) *Phoenix {
+	if heartbeat == 0 {
+		heartbeat = DefaultPhoenixHeartbeat
+	}
+
+	return &Phoenix{
+		pending:   make(map[string]phoenixPush),
+		joins:     make(map[string]string),
+		heartbeat: heartbeat,
+	}
+}
+
+// Name returns the name of the protocol.
+func (p *Phoenix) Name() string {
+	return "Phoenix"
+}
+
+// PrepareURL appends the websocket transport to the socket path and requests the version 2 serializer.
+func (p *Phoenix) PrepareURL(u *url.URL) {
+	if !strings.HasSuffix(u.Path, phoenixPathSuffix) {
+		u.Path = strings.TrimSuffix(u.Path, "/") + phoenixPathSuffix
+	}
+
+	query := u.Query()
+	query.Set("vsn", phoenixVersion)
+	u.RawQuery = query.Encode()
+}
+
+// Subprotocols returns nil, Phoenix does not use WebSocket subprotocols.
+func (p *Phoenix) Subprotocols() []string {
+	return nil
+}
+
+// Open forgets the channels joined in the previous session, they have to be joined again after reconnection,
+// and the heartbeats left without reply. Other pending pushes are kept, so that commands waiting for them
+// time out instead of printing unrelated messages.
+// It returns no frames.
+func (p *Phoenix) Open() ([]core.ProtocolFrame, error) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	p.joins = make(map[string]string)
+
+	for ref, push := range p.pending {
+		if push.event == phxHeartbeat {
+			delete(p.pending, ref)
+		}
+	}
+
+	return nil, nil
+}
+
+// Decode decodes the incoming message.
+// Replies are labeled with the event of their push, replies to heartbeats are not displayed.
+// Channels are forgotten when their join fails or the server closes them.
+// It returns an error if the message is not a Phoenix Channels message.
+func (p *Phoenix) Decode(data []byte, isBinary bool) (core.ProtocolMessage, error) {
+	if isBinary {
+		return core.ProtocolMessage{}, fmt.Errorf("unexpected binary message")
+	}
+
+	msg, err := parsePhoenix(data)
+	if err != nil {
+		return core.ProtocolMessage{}, err
+	}
+
+	result := phoenixResult{Type: "event", Topic: msg.topic, Event: msg.event, Payload: msg.payload}
+	if msg.ref != nil {
+		result.Ref = *msg.ref
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	switch msg.event {
+	case phxReply:
+		var reply phoenixReply
+		if err := json.Unmarshal(msg.payload, &reply); err != nil {
+			return core.ProtocolMessage{}, fmt.Errorf("invalid Phoenix reply: %s", msg.payload)
+		}
+
+		push, ok := p.pending[result.Ref]
+		delete(p.pending, result.Ref)
+
+		if ok && push.event == phxHeartbeat {
+			return core.ProtocolMessage{}, nil
+		}
+
+		if ok {
+			result.Event = push.event
+		}
+
+		if ok && push.event == phxJoin && reply.Status != "ok" {
+			delete(p.joins, push.topic)
+		}
+
+		result.Type, result.Status, result.Payload = "reply", reply.Status, reply.Response
+	case phxError, phxClose:
+		if msg.joinRef != nil && p.joins[msg.topic] == *msg.joinRef {
+			delete(p.joins, msg.topic)
+		}
+	}
+
+	shown, err := json.Marshal(result)
+	if err != nil {
+		return core.ProtocolMessage{}, fmt.Errorf("failed to encode %s message: %w", msg.event, err)
+	}
+
+	return core.ProtocolMessage{Display: string(shown)}, nil
+}
+
+// Encode builds the messages for the join, leave and push commands.
+// The arguments of join are the topic followed by an optional JSON payload: <topic> [payload].
+// The argument of leave is the topic of a joined channel.
+// The arguments of push are the topic of a joined channel, the event and an optional JSON payload: <topic> <event> [payload].
+// Every message gets the next ref and the command waits for its reply.
+// It returns ErrUnsupportedCommand for other commands and an error if the arguments are invalid.
+func (p *Phoenix) Encode(command, args string) ([]core.ProtocolFrame, error) {
+	if command != joinCommand && command != leaveCommand && command != pushCommand {
+		return nil, fmt.Errorf("%w: %s", core.ErrUnsupportedCommand, command)
+	}
+
+	topic, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	rest = strings.TrimSpace(rest)
+
+	if topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	joinRef, joined := p.joins[topic]
+
+	var event, payload string
+
+	switch command {
+	case joinCommand:
+		if joined {
+			return nil, fmt.Errorf("channel %s is already joined", topic)
+		}
+
+		event, payload = phxJoin, rest
+	case leaveCommand:
+		if !joined {
+			return nil, fmt.Errorf("channel %s is not joined", topic)
+		}
+
+		event = phxLeave
+
+		delete(p.joins, topic)
+	case pushCommand:
+		if !joined {
+			return nil, fmt.Errorf("channel %s is not joined", topic)
+		}
+
+		event, payload, _ = strings.Cut(rest, " ")
+		if event == "" {
+			return nil, fmt.Errorf("event name is required")
+		}
+	}
+
+	if payload = strings.TrimSpace(payload); payload == "" {
+		payload = "{}"
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(payload), &obj); err != nil {
+		return nil, fmt.Errorf("payload should be a JSON object: %s", payload)
+	}
+
+	p.nextRef++
+	ref := strconv.Itoa(p.nextRef)
+
+	if command == joinCommand {
+		joinRef = ref
+		p.joins[topic] = ref
+	}
+
+	frame, err := encodePhoenix(&joinRef, ref, topic, event, json.RawMessage(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	p.pending[ref] = phoenixPush{topic: topic, event: event}
+	frame.Response = ref
+
+	return []core.ProtocolFrame{frame}, nil
+}
+
+// IsResponse reports whether the displayed message is the reply to the push with the ref.
+func (p *Phoenix) IsResponse(msg, ref string) bool {
+	var result phoenixResult
+	if err := json.Unmarshal([]byte(msg), &result); err != nil {
+		return false
+	}
+
+	return result.Type == "reply" && result.Ref == ref
+}
+
+// HeartbeatInterval returns the interval of heartbeats.
+func (p *Phoenix) HeartbeatInterval() time.Duration {
+	return p.heartbeat
+}
+
+// Heartbeat returns the heartbeat message of the phoenix topic, its reply is not displayed.
+func (p *Phoenix) Heartbeat() ([]core.ProtocolFrame, error) {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	p.nextRef++
+	ref := strconv.Itoa(p.nextRef)
+
+	frame, err := encodePhoenix(nil, ref, phxTopic, phxHeartbeat, json.RawMessage("{}"))
+	if err != nil {
+		return nil, err
+	}
+
+	p.pending[ref] = phoenixPush{topic: phxTopic, event: phxHeartbeat}
+
+	return []core.ProtocolFrame{frame}, nil
+}
+
+// parsePhoenix parses the [join_ref, ref, topic, event, payload] array.
+// It returns an error if the message is not such an array or its topic or event is empty.
+func parsePhoenix(data []byte) (phoenixMessage, error) {
+	var (
+		fields []json.RawMessage
+		msg    phoenixMessage
+	)
+
+	targets := []any{&msg.joinRef, &msg.ref, &msg.topic, &msg.event}
+
+	if err := json.Unmarshal(data, &fields); err != nil || len(fields) != len(targets)+1 {
+		return phoenixMessage{}, fmt.Errorf("invalid Phoenix message: %s", data)
+	}
+
+	for i, target := range targets {
+		if err := json.Unmarshal(fields[i], target); err != nil {
+			return phoenixMessage{}, fmt.Errorf("invalid Phoenix message: %s", data)
+		}
+	}
+
+	if msg.topic == "" || msg.event == "" {
+		return phoenixMessage{}, fmt.Errorf("invalid Phoenix message: %s", data)
+	}
+
+	msg.payload = fields[len(targets)]
+
+	return msg, nil
+}
+
+// encodePhoenix encodes the message into the [join_ref, ref, topic, event, payload] array.
+func encodePhoenix(joinRef *string, ref, topic, event string, payload json.RawMessage) (core.ProtocolFrame, error) {
+	data, err := json.Marshal([]any{joinRef, ref, topic, event, payload})
+	if err != nil {
+		return core.ProtocolFrame{}, fmt.Errorf("failed to encode %s message: %w", event, err)
+	}
+
+	shown, err := json.Marshal(phoenixResult{Type: "push", Topic: topic, Event: event, Ref: ref, Payload: payload})
+	if err != nil {
+		return core.ProtocolFrame{}, fmt.Errorf("failed to encode %s message: %w", event, err)
+	}
+
+	return core.ProtocolFrame{Data: data, Display: string(shown)}, nil
+}
@@ -0,0 +1,192 @@
+package protocol
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhoenix_PrepareURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{name: "socket path", url: "ws://example.com/socket", expected: "ws://example.com/socket/websocket?vsn=2.0.0"},
+		{name: "full path", url: "wss://example.com/live/websocket?token=abc", expected: "wss://example.com/live/websocket?token=abc&vsn=2.0.0"},
+		{name: "trailing slash", url: "ws://example.com/socket/?vsn=1.0.0", expected: "ws://example.com/socket/websocket?vsn=2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+
+			NewPhoenix(0).PrepareURL(u)
+
+			assert.Equal(t, tt.expected, u.String())
+		})
+	}
+}
+
+func TestPhoenix_Channel(t *testing.T) {
+	p := NewPhoenix(0)
+
+	frames, err := p.Open()
+	require.NoError(t, err)
+	assert.Empty(t, frames)
+
+	frames, err = p.Encode("join", `room:lobby {"token": "abc"}`)
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, `["1","1","room:lobby","phx_join",{"token":"abc"}]`, string(frames[0].Data))
+	assert.Equal(t, `{"type":"push","topic":"room:lobby","event":"phx_join","ref":"1","payload":{"token":"abc"}}`, frames[0].Display)
+	assert.Equal(t, "1", frames[0].Response)
+	assert.False(t, p.IsResponse(frames[0].Display, "1"))
+
+	msg, err := p.Decode([]byte(`["1","1","room:lobby","phx_reply",{"status":"ok","response":{}}]`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"reply","topic":"room:lobby","event":"phx_join","ref":"1","status":"ok","payload":{}}`, msg.Display)
+	assert.True(t, p.IsResponse(msg.Display, "1"))
+	assert.False(t, p.IsResponse(msg.Display, "2"))
+
+	frames, err = p.Encode("push", `room:lobby new_msg {"body": "hi"}`)
+	require.NoError(t, err)
+	assert.Equal(t, `["1","2","room:lobby","new_msg",{"body":"hi"}]`, string(frames[0].Data))
+
+	msg, err = p.Decode([]byte(`[null,null,"room:lobby","new_msg",{"body":"hi"}]`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"event","topic":"room:lobby","event":"new_msg","payload":{"body":"hi"}}`, msg.Display)
+	assert.False(t, p.IsResponse(msg.Display, "2"), "broadcasts do not answer pushes")
+
+	msg, err = p.Decode([]byte(`["1","2","room:lobby","phx_reply",{"status":"error","response":{"reason":"forbidden"}}]`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"reply","topic":"room:lobby","event":"new_msg","ref":"2","status":"error","payload":{"reason":"forbidden"}}`, msg.Display)
+	assert.True(t, p.IsResponse(msg.Display, "2"))
+	assert.False(t, p.IsResponse("not json", "2"))
+
+	frames, err = p.Encode("leave", "room:lobby")
+	require.NoError(t, err)
+	assert.Equal(t, `["1","3","room:lobby","phx_leave",{}]`, string(frames[0].Data))
+
+	_, err = p.Encode("push", "room:lobby new_msg")
+	assert.EqualError(t, err, "channel room:lobby is not joined")
+}
+
+func TestPhoenix_ChannelClosed(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{name: "join rejected", message: `["1","1","room:1","phx_reply",{"status":"error","response":{"reason":"unauthorized"}}]`},
+		{name: "channel crashed", message: `["1",null,"room:1","phx_error",{}]`},
+		{name: "channel closed", message: `["1",null,"room:1","phx_close",{}]`},
+		{name: "reconnected", message: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPhoenix(0)
+
+			_, err := p.Encode("join", "room:1")
+			require.NoError(t, err)
+
+			if tt.message == "" {
+				_, err = p.Open()
+			} else {
+				_, err = p.Decode([]byte(tt.message), false)
+			}
+
+			require.NoError(t, err)
+
+			_, err = p.Encode("push", "room:1 ping")
+			assert.EqualError(t, err, "channel room:1 is not joined")
+
+			_, err = p.Encode("join", "room:1")
+			assert.NoError(t, err, "channel can be joined again")
+		})
+	}
+}
+
+func TestPhoenix_Heartbeat(t *testing.T) {
+	assert.Equal(t, 30*time.Second, NewPhoenix(0).HeartbeatInterval())
+	assert.Equal(t, -time.Second, NewPhoenix(-time.Second).HeartbeatInterval())
+
+	p := NewPhoenix(time.Second)
+	assert.Equal(t, time.Second, p.HeartbeatInterval())
+
+	frames, err := p.Heartbeat()
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, `[null,"1","phoenix","heartbeat",{}]`, string(frames[0].Data))
+	assert.Empty(t, frames[0].Response)
+
+	msg, err := p.Decode([]byte(`[null,"1","phoenix","phx_reply",{"status":"ok","response":{}}]`), false)
+	require.NoError(t, err)
+	assert.Empty(t, msg.Display, "heartbeat reply is not displayed")
+
+	_, err = p.Heartbeat()
+	require.NoError(t, err)
+
+	_, err = p.Open()
+	require.NoError(t, err)
+
+	msg, err = p.Decode([]byte(`[null,"2","phoenix","phx_reply",{"status":"ok","response":{}}]`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"reply","topic":"phoenix","event":"phx_reply","ref":"2","status":"ok","payload":{}}`, msg.Display,
+		"heartbeats are forgotten on reconnection")
+}
+
+func TestPhoenix_Decode_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr string
+		isBinary    bool
+	}{
+		{name: "binary", data: "\x01", isBinary: true, expectedErr: "unexpected binary message"},
+		{name: "not an array", data: `{"event":"x"}`, expectedErr: `invalid Phoenix message: {"event":"x"}`},
+		{name: "short array", data: `["1","1","room"]`, expectedErr: `invalid Phoenix message: ["1","1","room"]`},
+		{name: "numeric ref", data: `[1,1,"room","x",{}]`, expectedErr: `invalid Phoenix message: [1,1,"room","x",{}]`},
+		{name: "empty event", data: `[null,null,"room","",{}]`, expectedErr: `invalid Phoenix message: [null,null,"room","",{}]`},
+		{name: "invalid reply", data: `[null,"1","room","phx_reply",[]]`, expectedErr: "invalid Phoenix reply: []"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPhoenix(0).Decode([]byte(tt.data), tt.isBinary)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestPhoenix_Encode_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		args        string
+		expectedErr string
+	}{
+		{name: "unsupported command", command: "emit", args: "chat", expectedErr: "command is not supported by the protocol: emit"},
+		{name: "missing topic", command: "join", args: " ", expectedErr: "topic is required"},
+		{name: "already joined", command: "join", args: "room:1", expectedErr: "channel room:1 is already joined"},
+		{name: "leave not joined", command: "leave", args: "room:2", expectedErr: "channel room:2 is not joined"},
+		{name: "missing event", command: "push", args: "room:1", expectedErr: "event name is required"},
+		{name: "invalid payload", command: "push", args: "room:1 new_msg [1]", expectedErr: "payload should be a JSON object: [1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPhoenix(0)
+
+			_, err := p.Encode("join", "room:1")
+			require.NoError(t, err)
+
+			_, err = p.Encode(tt.command, tt.args)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package protocol
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+const (
+	NameRaw      = "raw"
+	NameSocketIO = "socketio"
+	NameGraphQL  = "graphql"
+	NameJSONRPC  = "jsonrpc"
+	NameSTOMP    = "stomp"
+	NamePhoenix  = "phoenix"
+	NameMQTT     = "mqtt"
+)
+
+// Options configures the application protocol.
+type Options struct {
+	// InitPayload is the JSON payload of the GraphQL connection_init message.
+	InitPayload string
+	// MQTTVersion is the MQTT protocol version, 3.1.1 or 5, 3.1.1 is used if empty.
+	MQTTVersion string
+	// Namespaces are the Socket.IO namespaces connected after the handshake, the main namespace is used if empty.
+	Namespaces []string
+	// Headers are extra STOMP CONNECT headers in key:value format, e.g. login and passcode.
+	Headers []string
+	// Heartbeat is the interval of protocol heartbeats, the protocol default is used if it is zero.
+	Heartbeat time.Duration
+}
+
+// New creates the application protocol with the provided name.
+// It takes name of type string and opts of type Options.
+// It returns nil for the raw protocol, which exchanges messages unchanged,
+// and an error if the protocol is not supported or the options are invalid.
+func New(name string, opts Options) (core.Protocol, error) {
+	switch name {
+	case "", NameRaw:
+		return nil, nil
+	case NameSocketIO:
+		proto, err := NewSocketIO(opts.Namespaces)
+		if err != nil {
+			return nil, err
+		}
+
+		return proto, nil
+	case NameGraphQL:
+		proto, err := NewGraphQL(opts.InitPayload, DefaultAckTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		return proto, nil
+	case NameJSONRPC:
+		return NewJSONRPC(), nil
+	case NameSTOMP:
+		proto, err := NewSTOMP(opts.Headers, opts.Heartbeat)
+		if err != nil {
+			return nil, err
+		}
+
+		return proto, nil
+	case NamePhoenix:
+		return NewPhoenix(opts.Heartbeat), nil
+	case NameMQTT:
+		proto, err := NewMQTT(opts.MQTTVersion, opts.Heartbeat)
+		if err != nil {
+			return nil, err
+		}
+
+		return proto, nil
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", name)
+	}
+}
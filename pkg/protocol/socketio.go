@@ -0,0 +1,356 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+// Engine.IO packet types.
+const (
+	eioOpen    = '0'
+	eioClose   = '1'
+	eioPing    = '2'
+	eioPong    = '3'
+	eioMessage = '4'
+	eioNoop    = '6'
+)
+
+// Socket.IO packet types.
+const (
+	sioConnect      = '0'
+	sioDisconnect   = '1'
+	sioEvent        = '2'
+	sioAck          = '3'
+	sioConnectError = '4'
+	sioBinaryEvent  = '5'
+	sioBinaryAck    = '6'
+)
+
+const (
+	mainNamespace  = "/"
+	socketIOPath   = "/socket.io/"
+	engineIOVer    = "4"
+	emitCommand    = "emit"
+	socketIOPrefix = string(eioMessage)
+)
+
+var ErrBinaryAttachments = errors.New("binary attachments are not supported")
+
+// SocketIO implements the Socket.IO v5 protocol over the Engine.IO v4 WebSocket transport.
+// It connects the namespaces after the Engine.IO handshake, answers pings, acknowledges events
+// which request an acknowledgement and tracks acknowledgements of emitted events.
+type SocketIO struct {
+	acks       map[int]string
+	namespaces []string
+	nextID     int
+	l          sync.Mutex
+}
+
+// socketIOMessage is the decoded form of a Socket.IO packet displayed to the user.
+type socketIOMessage struct {
+	Type      string          `json:"type"`
+	Namespace string          `json:"namespace,omitempty"`
+	Event     string          `json:"event,omitempty"`
+	ID        *int            `json:"id,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// NewSocketIO creates the Socket.IO protocol.
+// It takes namespaces of type []string to connect after the handshake, the main namespace "/" is used if empty.
+// The first namespace is the default one for emitted events.
+// It returns a pointer to SocketIO and an error if a namespace does not start with a slash.
+func NewSocketIO(namespaces []string) (*SocketIO, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{mainNamespace}
+	}
+
+	for _, ns := range namespaces {
+		if !strings.HasPrefix(ns, "/") || strings.ContainsAny(ns, ", ") {
+			return nil, fmt.Errorf("invalid namespace %q: should start with a slash and contain no commas or spaces", ns)
+		}
+	}
+
+	return &SocketIO{namespaces: namespaces, acks: make(map[int]string)}, nil
+}
+
+// Name returns the name of the protocol.
+func (s *SocketIO) Name() string {
+	return "Socket.IO"
+}
+
+// PrepareURL points the address to the Engine.IO WebSocket transport, the default /socket.io/ path is used if none is set.
+func (s *SocketIO) PrepareURL(u *url.URL) {
+	if u.Path == "" || u.Path == "/" {
+		u.Path = socketIOPath
+	}
+
+	query := u.Query()
+	query.Set("EIO", engineIOVer)
+	query.Set("transport", "websocket")
+	u.RawQuery = query.Encode()
+}
+
+// Subprotocols returns nil, Socket.IO does not use WebSocket subprotocols.
+func (s *SocketIO) Subprotocols() []string {
+	return nil
+}
+
+// Open resets the acknowledgements of the previous session.
+// It returns no frames, namespaces are connected after the server sends the Engine.IO open packet.
+func (s *SocketIO) Open() ([]core.ProtocolFrame, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.acks = make(map[int]string)
+	s.nextID = 0
+
+	return nil, nil
+}
+
+// Decode decodes the incoming Engine.IO packet.
+// It answers the open packet with namespace connect packets and pings with pongs, which are not displayed.
+// It returns the packet decoded to JSON and an error if the packet is malformed or carries binary attachments.
+func (s *SocketIO) Decode(data []byte, isBinary bool) (core.ProtocolMessage, error) {
+	if isBinary {
+		return core.ProtocolMessage{}, ErrBinaryAttachments
+	}
+
+	packet := string(data)
+	if packet == "" {
+		return core.ProtocolMessage{}, fmt.Errorf("empty Engine.IO packet")
+	}
+
+	switch payload := packet[1:]; packet[0] {
+	case eioOpen:
+		if !json.Valid([]byte(payload)) {
+			return core.ProtocolMessage{}, fmt.Errorf("invalid Engine.IO handshake: %s", payload)
+		}
+
+		replies := make([]core.ProtocolFrame, 0, len(s.namespaces))
+		for _, ns := range s.namespaces {
+			replies = append(replies, core.ProtocolFrame{Data: []byte(socketIOPrefix + string(sioConnect) + namespacePrefix(ns))})
+		}
+
+		return socketIOMessage{Type: "open", Data: json.RawMessage(payload)}.result(replies)
+	case eioClose:
+		return socketIOMessage{Type: "close"}.result(nil)
+	case eioPing:
+		return core.ProtocolMessage{Replies: []core.ProtocolFrame{{Data: []byte(string(eioPong) + payload)}}}, nil
+	case eioPong, eioNoop:
+		return core.ProtocolMessage{}, nil
+	case eioMessage:
+		return s.decodePacket(payload)
+	default:
+		return core.ProtocolMessage{}, fmt.Errorf("unknown Engine.IO packet type: %c", packet[0])
+	}
+}
+
+// decodePacket decodes the Socket.IO packet carried by an Engine.IO message.
+// Events requesting an acknowledgement are acknowledged with an empty ack.
+func (s *SocketIO) decodePacket(packet string) (core.ProtocolMessage, error) {
+	if packet == "" {
+		return core.ProtocolMessage{}, fmt.Errorf("empty Socket.IO packet")
+	}
+
+	ns, id, payload := parsePacket(packet[1:])
+	msg := socketIOMessage{Namespace: ns, ID: id}
+
+	switch packet[0] {
+	case sioConnect, sioConnectError:
+		msg.Type = "connect"
+		if packet[0] == sioConnectError {
+			msg.Type = "connect_error"
+		}
+
+		if payload != "" {
+			msg.Data = json.RawMessage(payload)
+		}
+
+		return msg.result(nil)
+	case sioDisconnect:
+		msg.Type = "disconnect"
+		return msg.result(nil)
+	case sioEvent:
+		event, args, err := parseEvent(payload)
+		if err != nil {
+			return core.ProtocolMessage{}, err
+		}
+
+		msg.Type, msg.Event, msg.Data = "event", event, args
+
+		var replies []core.ProtocolFrame
+		if id != nil {
+			ack := socketIOPrefix + string(sioAck) + namespacePrefix(ns) + strconv.Itoa(*id) + "[]"
+			replies = append(replies, core.ProtocolFrame{Data: []byte(ack)})
+		}
+
+		return msg.result(replies)
+	case sioAck:
+		if id == nil {
+			return core.ProtocolMessage{}, fmt.Errorf("ack packet without id: %s", packet)
+		}
+
+		if !json.Valid([]byte(payload)) {
+			return core.ProtocolMessage{}, fmt.Errorf("invalid Socket.IO ack data: %s", payload)
+		}
+
+		s.l.Lock()
+		msg.Event = s.acks[*id]
+		delete(s.acks, *id)
+		s.l.Unlock()
+
+		msg.Type, msg.Data = "ack", json.RawMessage(payload)
+
+		return msg.result(nil)
+	case sioBinaryEvent, sioBinaryAck:
+		return core.ProtocolMessage{}, ErrBinaryAttachments
+	default:
+		return core.ProtocolMessage{}, fmt.Errorf("unknown Socket.IO packet type: %c", packet[0])
+	}
+}
+
+// Encode builds the event packet for the emit command.
+// The arguments are an optional namespace, the event name and optional JSON data: [/namespace] event [data].
+// The event requests an acknowledgement, which is displayed with the event name when the server sends it.
+// It returns ErrUnsupportedCommand for other commands and an error if the arguments are invalid.
+func (s *SocketIO) Encode(command, args string) ([]core.ProtocolFrame, error) {
+	if command != emitCommand {
+		return nil, fmt.Errorf("%w: %s", core.ErrUnsupportedCommand, command)
+	}
+
+	ns := s.namespaces[0]
+
+	args = strings.TrimSpace(args)
+	if strings.HasPrefix(args, "/") {
+		ns, args, _ = strings.Cut(args, " ")
+		args = strings.TrimSpace(args)
+	}
+
+	if !slices.Contains(s.namespaces, ns) {
+		return nil, fmt.Errorf("namespace %s is not connected", ns)
+	}
+
+	event, data, _ := strings.Cut(args, " ")
+	if event == "" {
+		return nil, fmt.Errorf("event name is required")
+	}
+
+	eventName, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event name: %w", err)
+	}
+
+	payload := []json.RawMessage{eventName}
+
+	if data = strings.TrimSpace(data); data != "" {
+		if !json.Valid([]byte(data)) {
+			return nil, fmt.Errorf("event data should be valid JSON: %s", data)
+		}
+
+		payload = append(payload, json.RawMessage(data))
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	s.l.Lock()
+	id := s.nextID
+	s.nextID++
+	s.acks[id] = event
+	s.l.Unlock()
+
+	msg := socketIOMessage{Type: "emit", Namespace: ns, Event: event, ID: &id}
+
+	if data != "" {
+		msg.Data = json.RawMessage("[" + data + "]")
+	}
+
+	shown, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	packet := socketIOPrefix + string(sioEvent) + namespacePrefix(ns) + strconv.Itoa(id) + string(encoded)
+
+	return []core.ProtocolFrame{{Data: []byte(packet), Display: string(shown)}}, nil
+}
+
+// parsePacket splits the Socket.IO packet after its type into the namespace, the ack id, which is nil if absent,
+// and the JSON payload.
+func parsePacket(packet string) (ns string, id *int, payload string) {
+	ns = mainNamespace
+
+	if strings.HasPrefix(packet, "/") {
+		var found bool
+		if ns, packet, found = strings.Cut(packet, ","); !found {
+			packet = ""
+		}
+	}
+
+	digits := 0
+	for digits < len(packet) && packet[digits] >= '0' && packet[digits] <= '9' {
+		digits++
+	}
+
+	if digits > 0 {
+		if n, err := strconv.Atoi(packet[:digits]); err == nil {
+			id = &n
+		}
+	}
+
+	return ns, id, packet[digits:]
+}
+
+// parseEvent splits the event payload, a JSON array, into the event name and the array of its arguments.
+// The arguments are nil if the event has none.
+func parseEvent(payload string) (string, json.RawMessage, error) {
+	var parts []json.RawMessage
+	if err := json.Unmarshal([]byte(payload), &parts); err != nil || len(parts) == 0 {
+		return "", nil, fmt.Errorf("invalid Socket.IO event: %s", payload)
+	}
+
+	var event string
+	if err := json.Unmarshal(parts[0], &event); err != nil {
+		return "", nil, fmt.Errorf("invalid Socket.IO event name: %s", parts[0])
+	}
+
+	if len(parts) == 1 {
+		return event, nil, nil
+	}
+
+	args, err := json.Marshal(parts[1:])
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid Socket.IO event arguments: %w", err)
+	}
+
+	return event, args, nil
+}
+
+// namespacePrefix returns the namespace part of a packet, which is omitted for the main namespace.
+func namespacePrefix(ns string) string {
+	if ns == mainNamespace {
+		return ""
+	}
+
+	return ns + ","
+}
+
+// result encodes the decoded packet for printing and attaches the replies to it.
+func (msg socketIOMessage) result(replies []core.ProtocolFrame) (core.ProtocolMessage, error) {
+	shown, err := json.Marshal(msg)
+	if err != nil {
+		return core.ProtocolMessage{}, fmt.Errorf("failed to encode %s packet: %w", msg.Type, err)
+	}
+
+	return core.ProtocolMessage{Display: string(shown), Replies: replies}, nil
+}
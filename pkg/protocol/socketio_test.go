@@ -0,0 +1,301 @@
+package protocol
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		protocol    string
+		expectedErr string
+		expectNil   bool
+	}{
+		{name: "default", protocol: "", expectNil: true},
+		{name: "raw", protocol: NameRaw, expectNil: true},
+		{name: "socketio", protocol: NameSocketIO},
+		{name: "unknown", protocol: "amqp", expectedErr: "unsupported protocol: amqp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proto, err := New(tt.protocol, Options{})
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, proto)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectNil, proto == nil)
+		})
+	}
+}
+
+func TestNew_InvalidNamespace(t *testing.T) {
+	proto, err := New(NameSocketIO, Options{Namespaces: []string{"admin"}})
+
+	assert.Error(t, err)
+	assert.Nil(t, proto)
+}
+
+func TestSocketIO_PrepareURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{name: "no path", url: "ws://example.com", expected: "ws://example.com/socket.io/?EIO=4&transport=websocket"},
+		{name: "custom path", url: "wss://example.com/ws/?token=abc", expected: "wss://example.com/ws/?EIO=4&token=abc&transport=websocket"},
+		{name: "existing engine.io version", url: "ws://example.com/socket.io/?EIO=3", expected: "ws://example.com/socket.io/?EIO=4&transport=websocket"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSocketIO(nil)
+			require.NoError(t, err)
+
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+
+			s.PrepareURL(u)
+
+			assert.Equal(t, tt.expected, u.String())
+		})
+	}
+}
+
+func TestSocketIO_Decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		packet      string
+		expectedErr string
+		expected    core.ProtocolMessage
+		namespaces  []string
+		isBinary    bool
+	}{
+		{
+			name:   "open",
+			packet: `0{"sid":"abc","pingInterval":25000}`,
+			expected: core.ProtocolMessage{
+				Display: `{"type":"open","data":{"sid":"abc","pingInterval":25000}}`,
+				Replies: []core.ProtocolFrame{{Data: []byte("40")}},
+			},
+		},
+		{
+			name:       "open with namespaces",
+			packet:     `0{"sid":"abc"}`,
+			namespaces: []string{"/", "/admin"},
+			expected: core.ProtocolMessage{
+				Display: `{"type":"open","data":{"sid":"abc"}}`,
+				Replies: []core.ProtocolFrame{{Data: []byte("40")}, {Data: []byte("40/admin,")}},
+			},
+		},
+		{
+			name:     "ping",
+			packet:   "2",
+			expected: core.ProtocolMessage{Replies: []core.ProtocolFrame{{Data: []byte("3")}}},
+		},
+		{
+			name:     "ping probe",
+			packet:   "2probe",
+			expected: core.ProtocolMessage{Replies: []core.ProtocolFrame{{Data: []byte("3probe")}}},
+		},
+		{
+			name:   "pong",
+			packet: "3",
+		},
+		{
+			name:     "close",
+			packet:   "1",
+			expected: core.ProtocolMessage{Display: `{"type":"close"}`},
+		},
+		{
+			name:     "namespace connected",
+			packet:   `40/admin,{"sid":"xyz"}`,
+			expected: core.ProtocolMessage{Display: `{"type":"connect","namespace":"/admin","data":{"sid":"xyz"}}`},
+		},
+		{
+			name:     "connect error",
+			packet:   `44{"message":"not authorized"}`,
+			expected: core.ProtocolMessage{Display: `{"type":"connect_error","namespace":"/","data":{"message":"not authorized"}}`},
+		},
+		{
+			name:     "disconnect",
+			packet:   `41/admin,`,
+			expected: core.ProtocolMessage{Display: `{"type":"disconnect","namespace":"/admin"}`},
+		},
+		{
+			name:     "event",
+			packet:   `42["chat",{"text":"hi"},2]`,
+			expected: core.ProtocolMessage{Display: `{"type":"event","namespace":"/","event":"chat","data":[{"text":"hi"},2]}`},
+		},
+		{
+			name:     "event without arguments",
+			packet:   `42/admin,["tick"]`,
+			expected: core.ProtocolMessage{Display: `{"type":"event","namespace":"/admin","event":"tick"}`},
+		},
+		{
+			name:   "event requesting ack",
+			packet: `42/admin,7["question"]`,
+			expected: core.ProtocolMessage{
+				Display: `{"type":"event","namespace":"/admin","event":"question","id":7}`,
+				Replies: []core.ProtocolFrame{{Data: []byte("43/admin,7[]")}},
+			},
+		},
+		{
+			name:        "invalid event",
+			packet:      `42{"chat":1}`,
+			expectedErr: `invalid Socket.IO event: {"chat":1}`,
+		},
+		{
+			name:        "binary event",
+			packet:      `451-["upload",{"_placeholder":true,"num":0}]`,
+			expectedErr: ErrBinaryAttachments.Error(),
+		},
+		{
+			name:        "binary frame",
+			packet:      "\x01\x02",
+			isBinary:    true,
+			expectedErr: ErrBinaryAttachments.Error(),
+		},
+		{
+			name:        "unknown packet",
+			packet:      "9",
+			expectedErr: "unknown Engine.IO packet type: 9",
+		},
+		{
+			name:        "empty packet",
+			packet:      "",
+			expectedErr: "empty Engine.IO packet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSocketIO(tt.namespaces)
+			require.NoError(t, err)
+
+			msg, err := s.Decode([]byte(tt.packet), tt.isBinary)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestSocketIO_Encode(t *testing.T) {
+	tests := []struct {
+		name          string
+		command       string
+		args          string
+		expectedErr   string
+		expectedData  string
+		expectedShown string
+	}{
+		{
+			name:          "event with data",
+			command:       "emit",
+			args:          `chat {"text":"hi"}`,
+			expectedData:  `420["chat",{"text":"hi"}]`,
+			expectedShown: `{"type":"emit","namespace":"/","event":"chat","id":0,"data":[{"text":"hi"}]}`,
+		},
+		{
+			name:          "event without data",
+			command:       "emit",
+			args:          "ping",
+			expectedData:  `420["ping"]`,
+			expectedShown: `{"type":"emit","namespace":"/","event":"ping","id":0}`,
+		},
+		{
+			name:          "event to namespace",
+			command:       "emit",
+			args:          `/admin kick "bob"`,
+			expectedData:  `42/admin,0["kick","bob"]`,
+			expectedShown: `{"type":"emit","namespace":"/admin","event":"kick","id":0,"data":["bob"]}`,
+		},
+		{
+			name:        "not connected namespace",
+			command:     "emit",
+			args:        "/other kick",
+			expectedErr: "namespace /other is not connected",
+		},
+		{
+			name:        "missing event",
+			command:     "emit",
+			args:        "/admin",
+			expectedErr: "event name is required",
+		},
+		{
+			name:        "invalid data",
+			command:     "emit",
+			args:        "chat {text}",
+			expectedErr: "event data should be valid JSON: {text}",
+		},
+		{
+			name:        "unsupported command",
+			command:     "subscribe",
+			args:        "chat",
+			expectedErr: "command is not supported by the protocol: subscribe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSocketIO([]string{"/", "/admin"})
+			require.NoError(t, err)
+
+			frames, err := s.Encode(tt.command, tt.args)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, frames, 1)
+			assert.Equal(t, tt.expectedData, string(frames[0].Data))
+			assert.Equal(t, tt.expectedShown, frames[0].Display)
+			assert.False(t, frames[0].Binary)
+		})
+	}
+}
+
+func TestSocketIO_Ack(t *testing.T) {
+	s, err := NewSocketIO(nil)
+	require.NoError(t, err)
+
+	_, err = s.Encode("emit", "first")
+	require.NoError(t, err)
+
+	frames, err := s.Encode("emit", "second")
+	require.NoError(t, err)
+	assert.Equal(t, `421["second"]`, string(frames[0].Data))
+
+	msg, err := s.Decode([]byte(`431[{"ok":true}]`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"ack","namespace":"/","event":"second","id":1,"data":[{"ok":true}]}`, msg.Display)
+
+	msg, err = s.Decode([]byte(`431[]`), false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"ack","namespace":"/","id":1,"data":[]}`, msg.Display, "ack is matched only once")
+
+	_, err = s.Open()
+	require.NoError(t, err)
+
+	frames, err = s.Encode("emit", "third")
+	require.NoError(t, err)
+	assert.Equal(t, `420["third"]`, string(frames[0].Data), "ack ids restart after reconnection")
+}
@@ -0,0 +1,438 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+// STOMP frame commands.
+const (
+	stompConnect     = "CONNECT"
+	stompConnected   = "CONNECTED"
+	stompSend        = "SEND"
+	stompSubscribe   = "SUBSCRIBE"
+	stompUnsubscribe = "UNSUBSCRIBE"
+)
+
+const (
+	unsubscribeCommand = "unsubscribe"
+	stompSendCommand   = "stomp-send"
+	stompVersions      = "1.2,1.1,1.0"
+	stompEOL           = "\n"
+	stompTerminator    = 0
+
+	// DefaultSTOMPHeartbeat is the interval of client heartbeats offered to the server.
+	DefaultSTOMPHeartbeat = 10 * time.Second
+)
+
+var (
+	stompEscaper   = strings.NewReplacer(`\`, `\\`, "\r", `\r`, "\n", `\n`, ":", `\c`)
+	stompUnescaper = strings.NewReplacer(`\\`, `\`, `\r`, "\r", `\n`, "\n", `\c`, ":")
+)
+
+// STOMP implements the STOMP 1.0-1.2 protocol over WebSocket.
+// It connects to the broker, sends heartbeats at the negotiated interval and manages subscriptions.
+// Frames are displayed as JSON with their command, headers and body, JSON bodies are kept as JSON.
+type STOMP struct {
+	subscriptions map[string]string
+	host          string
+	headers       [][2]string
+	heartbeat     time.Duration
+	interval      time.Duration
+	nextID        int
+	connected     bool
+	l             sync.Mutex
+}
+
+// stompFrame is a frame of the STOMP protocol.
+type stompFrame struct {
+	command string
+	headers [][2]string
+	body    []byte
+}
+
+// stompResult is the form of a frame displayed to the user.
+type stompResult struct {
+	Headers map[string]string `json:"headers,omitempty"`
+	Command string            `json:"command"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// NewSTOMP creates the STOMP protocol.
+// It takes headers of type []string, extra CONNECT headers in key:value format like login and passcode,
+// and heartbeat of type time.Duration, the interval of client heartbeats offered to the server.
+// DefaultSTOMPHeartbeat is used if heartbeat is zero, negative value disables heartbeats.
+// It returns a pointer to STOMP and an error if a header is not in key:value format.
+func NewSTOMP(headers []string, heartbeat time.Duration) (*STOMP, error) {
+	s := &STOMP{
+		subscriptions: make(map[string]string),
+		heartbeat:     heartbeat,
+	}
+
+	if s.heartbeat == 0 {
+		s.heartbeat = DefaultSTOMPHeartbeat
+	}
+
+	for _, header := range headers {
+		key, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid STOMP header %q: should be in key:value format", header)
+		}
+
+		s.headers = append(s.headers, [2]string{strings.TrimSpace(key), strings.TrimSpace(value)})
+	}
+
+	return s, nil
+}
+
+// Name returns the name of the protocol.
+func (s *STOMP) Name() string {
+	return "STOMP"
+}
+
+// PrepareURL keeps the address unchanged and remembers its host for the CONNECT frame.
+func (s *STOMP) PrepareURL(u *url.URL) {
+	s.host = u.Hostname()
+}
+
+// Subprotocols returns the STOMP subprotocols, the newest version first.
+func (s *STOMP) Subprotocols() []string {
+	return []string{"v12.stomp", "v11.stomp", "v10.stomp"}
+}
+
+// Open forgets the subscriptions and the heartbeat interval of the previous session.
+// It returns the CONNECT frame offering client heartbeats.
+func (s *STOMP) Open() ([]core.ProtocolFrame, error) {
+	s.l.Lock()
+	s.subscriptions = make(map[string]string)
+	s.nextID = 0
+	s.interval = 0
+	s.connected = false
+	s.l.Unlock()
+
+	frame := stompFrame{
+		command: stompConnect,
+		headers: [][2]string{
+			{"accept-version", stompVersions},
+			{"host", s.host},
+			{"heart-beat", strconv.FormatInt(max(s.heartbeat, 0).Milliseconds(), 10) + ",0"},
+		},
+	}
+
+	for _, header := range s.headers {
+		frame.set(header[0], header[1])
+	}
+
+	shown, err := frame.display()
+	if err != nil {
+		return nil, err
+	}
+
+	return []core.ProtocolFrame{{Data: frame.encode(), Display: shown}}, nil
+}
+
+// Decode decodes the incoming frame into its command, headers and body.
+// Heartbeats are not displayed, the CONNECTED frame sets the negotiated heartbeat interval.
+// It returns an error if the message is not a valid STOMP frame.
+func (s *STOMP) Decode(data []byte, _ bool) (core.ProtocolMessage, error) {
+	if len(bytes.TrimLeft(data, "\r\n")) == 0 {
+		return core.ProtocolMessage{}, nil
+	}
+
+	frame, err := parseStompFrame(data)
+	if err != nil {
+		return core.ProtocolMessage{}, err
+	}
+
+	if frame.command == stompConnected {
+		s.negotiate(frame)
+	}
+
+	shown, err := frame.display()
+	if err != nil {
+		return core.ProtocolMessage{}, err
+	}
+
+	return core.ProtocolMessage{Display: shown}, nil
+}
+
+// negotiate sets the interval of client heartbeats from the heart-beat header of the CONNECTED frame.
+// The client sends heartbeats only if both sides agree, at the larger of the two intervals.
+func (s *STOMP) negotiate(frame stompFrame) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.connected = true
+	s.interval = 0
+
+	value, _ := frame.header("heart-beat")
+	_, serverWants, _ := strings.Cut(value, ",")
+
+	ms, err := strconv.ParseInt(strings.TrimSpace(serverWants), 10, 64)
+	if err != nil || ms <= 0 || s.heartbeat <= 0 {
+		return
+	}
+
+	s.interval = max(s.heartbeat, time.Duration(ms)*time.Millisecond)
+}
+
+// Encode builds the frames for the subscribe, unsubscribe and stomp-send commands.
+// The argument of subscribe is the destination, the subscription gets the next id.
+// The argument of unsubscribe is the id or the destination of the subscription.
+// The arguments of stomp-send are the destination followed by the body: <destination> <body>.
+// It returns ErrUnsupportedCommand for other commands and an error if the arguments are invalid.
+func (s *STOMP) Encode(command, args string) ([]core.ProtocolFrame, error) {
+	args = strings.TrimSpace(args)
+
+	var frame stompFrame
+
+	switch command {
+	case subscribeCommand:
+		if args == "" {
+			return nil, fmt.Errorf("destination is required")
+		}
+
+		s.l.Lock()
+		id := "sub-" + strconv.Itoa(s.nextID)
+		s.nextID++
+		s.subscriptions[id] = args
+		s.l.Unlock()
+
+		frame = stompFrame{command: stompSubscribe, headers: [][2]string{{"id", id}, {"destination", args}, {"ack", "auto"}}}
+	case unsubscribeCommand:
+		id, err := s.unsubscribe(args)
+		if err != nil {
+			return nil, err
+		}
+
+		frame = stompFrame{command: stompUnsubscribe, headers: [][2]string{{"id", id}}}
+	case stompSendCommand:
+		destination, body, _ := strings.Cut(args, " ")
+		if destination == "" {
+			return nil, fmt.Errorf("destination is required")
+		}
+
+		body = strings.TrimSpace(body)
+
+		contentType := "text/plain"
+		if json.Valid([]byte(body)) {
+			contentType = "application/json"
+		}
+
+		frame = stompFrame{
+			command: stompSend,
+			headers: [][2]string{
+				{"destination", destination},
+				{"content-type", contentType},
+				{"content-length", strconv.Itoa(len(body))},
+			},
+			body: []byte(body),
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", core.ErrUnsupportedCommand, command)
+	}
+
+	shown, err := frame.display()
+	if err != nil {
+		return nil, err
+	}
+
+	return []core.ProtocolFrame{{Data: frame.encode(), Display: shown}}, nil
+}
+
+// unsubscribe removes the subscription with the id or, if there is no such id, the oldest subscription to the destination.
+// It returns the id of the removed subscription and an error if there is no matching subscription.
+func (s *STOMP) unsubscribe(target string) (string, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if _, ok := s.subscriptions[target]; ok {
+		delete(s.subscriptions, target)
+		return target, nil
+	}
+
+	for n := range s.nextID {
+		id := "sub-" + strconv.Itoa(n)
+		if dest, ok := s.subscriptions[id]; ok && dest == target {
+			delete(s.subscriptions, id)
+			return id, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown subscription: %s", target)
+}
+
+// HeartbeatInterval returns the negotiated interval of client heartbeats.
+// Until the server responds with CONNECTED the offered interval is returned, so that heartbeats start after negotiation.
+func (s *STOMP) HeartbeatInterval() time.Duration {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if !s.connected {
+		return max(s.heartbeat, 0)
+	}
+
+	return s.interval
+}
+
+// Heartbeat returns the heartbeat, an end of line, or no frames if the session is not connected yet.
+func (s *STOMP) Heartbeat() ([]core.ProtocolFrame, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if !s.connected || s.interval <= 0 {
+		return nil, nil
+	}
+
+	return []core.ProtocolFrame{{Data: []byte(stompEOL)}}, nil
+}
+
+// parseStompFrame parses the frame, leading end of lines are heartbeats and skipped.
+// The body is read up to the content-length header or up to the NUL terminator.
+// It returns an error if the frame is malformed.
+func parseStompFrame(data []byte) (stompFrame, error) {
+	data = bytes.TrimLeft(data, "\r\n")
+
+	head, body, ok := bytes.Cut(data, []byte("\n\n"))
+	if crlf, crlfBody, found := bytes.Cut(data, []byte("\r\n\r\n")); found && (!ok || len(crlf) < len(head)) {
+		head, body, ok = crlf, crlfBody, true
+	}
+
+	if !ok {
+		return stompFrame{}, fmt.Errorf("invalid STOMP frame: missing end of headers")
+	}
+
+	lines := strings.Split(string(head), "\n")
+	frame := stompFrame{command: strings.TrimSuffix(lines[0], "\r")}
+
+	for _, line := range lines[1:] {
+		key, value, found := strings.Cut(strings.TrimSuffix(line, "\r"), ":")
+		if !found {
+			return stompFrame{}, fmt.Errorf("invalid STOMP header: %q", line)
+		}
+
+		if frame.command != stompConnected {
+			key, value = unescapeStompHeader(key), unescapeStompHeader(value)
+		}
+
+		frame.headers = append(frame.headers, [2]string{key, value})
+	}
+
+	if length, found := frame.header("content-length"); found {
+		n, err := strconv.Atoi(length)
+		if err != nil || n < 0 || n > len(body) {
+			return stompFrame{}, fmt.Errorf("invalid STOMP content-length: %s", length)
+		}
+
+		frame.body = body[:n]
+
+		return frame, nil
+	}
+
+	end := bytes.IndexByte(body, stompTerminator)
+	if end < 0 {
+		return stompFrame{}, fmt.Errorf("invalid STOMP frame: missing NUL terminator")
+	}
+
+	frame.body = body[:end]
+
+	return frame, nil
+}
+
+// header returns the value of the first header with the name, repeated headers are ignored as the specification requires.
+func (f *stompFrame) header(name string) (string, bool) {
+	for _, header := range f.headers {
+		if header[0] == name {
+			return header[1], true
+		}
+	}
+
+	return "", false
+}
+
+// set replaces the value of the header with the name or appends the header if the frame has no such header.
+func (f *stompFrame) set(name, value string) {
+	for i, header := range f.headers {
+		if header[0] == name {
+			f.headers[i][1] = value
+			return
+		}
+	}
+
+	f.headers = append(f.headers, [2]string{name, value})
+}
+
+// encode returns the wire form of the frame, header values are escaped in all frames except CONNECT.
+func (f *stompFrame) encode() []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(f.command + stompEOL)
+
+	for _, header := range f.headers {
+		key, value := header[0], header[1]
+		if f.command != stompConnect {
+			key, value = escapeStompHeader(key), escapeStompHeader(value)
+		}
+
+		buf.WriteString(key + ":" + value + stompEOL)
+	}
+
+	buf.WriteString(stompEOL)
+	buf.Write(f.body)
+	buf.WriteByte(stompTerminator)
+
+	return buf.Bytes()
+}
+
+// display returns the frame as JSON, the body is kept as JSON if it is valid JSON and is a string otherwise.
+func (f *stompFrame) display() (string, error) {
+	result := stompResult{Command: f.command}
+
+	if len(f.headers) > 0 {
+		result.Headers = make(map[string]string, len(f.headers))
+
+		for _, header := range f.headers {
+			if _, ok := result.Headers[header[0]]; !ok {
+				result.Headers[header[0]] = header[1]
+			}
+		}
+	}
+
+	switch {
+	case len(bytes.TrimSpace(f.body)) == 0:
+	case json.Valid(f.body):
+		result.Body = json.RawMessage(f.body)
+	default:
+		body, err := json.Marshal(string(f.body))
+		if err != nil {
+			return "", fmt.Errorf("failed to encode %s body: %w", f.command, err)
+		}
+
+		result.Body = body
+	}
+
+	shown, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s frame: %w", f.command, err)
+	}
+
+	return string(shown), nil
+}
+
+// escapeStompHeader escapes the special characters of a header as STOMP 1.2 requires.
+func escapeStompHeader(s string) string {
+	return stompEscaper.Replace(s)
+}
+
+// unescapeStompHeader reverts escapeStompHeader.
+func unescapeStompHeader(s string) string {
+	return stompUnescaper.Replace(s)
+}
@@ -0,0 +1,258 @@
+package protocol
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSTOMP_InvalidHeader(t *testing.T) {
+	proto, err := New(NameSTOMP, Options{Headers: []string{"login"}})
+
+	assert.EqualError(t, err, `invalid STOMP header "login": should be in key:value format`)
+	assert.Nil(t, proto)
+}
+
+func TestSTOMP_Open(t *testing.T) {
+	s, err := NewSTOMP([]string{"login: guest", "host:vhost"}, 0)
+	require.NoError(t, err)
+
+	u, err := url.Parse("wss://broker.example.com:15674/ws")
+	require.NoError(t, err)
+
+	s.PrepareURL(u)
+
+	assert.Equal(t, "wss://broker.example.com:15674/ws", u.String())
+	assert.Equal(t, []string{"v12.stomp", "v11.stomp", "v10.stomp"}, s.Subprotocols())
+
+	frames, err := s.Open()
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, "CONNECT\naccept-version:1.2,1.1,1.0\nhost:vhost\nheart-beat:10000,0\nlogin:guest\n\n\x00", string(frames[0].Data))
+	assert.Equal(t, `{"headers":{"accept-version":"1.2,1.1,1.0","heart-beat":"10000,0","host":"vhost","login":"guest"},"command":"CONNECT"}`, frames[0].Display)
+}
+
+func TestSTOMP_Heartbeat(t *testing.T) {
+	tests := []struct {
+		name        string
+		connected   string
+		heartbeat   time.Duration
+		expected    time.Duration
+		expectFrame bool
+	}{
+		{name: "server interval is larger", heartbeat: time.Second, connected: "heart-beat:0,5000", expected: 5 * time.Second, expectFrame: true},
+		{name: "client interval is larger", heartbeat: 10 * time.Second, connected: "heart-beat:0,5000", expected: 10 * time.Second, expectFrame: true},
+		{name: "server does not want heartbeats", heartbeat: time.Second, connected: "heart-beat:0,0", expected: 0},
+		{name: "no heart-beat header", heartbeat: time.Second, connected: "version:1.2", expected: 0},
+		{name: "client disabled heartbeats", heartbeat: -1, connected: "heart-beat:0,5000", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSTOMP(nil, tt.heartbeat)
+			require.NoError(t, err)
+
+			_, err = s.Open()
+			require.NoError(t, err)
+
+			assert.Equal(t, max(tt.heartbeat, 0), s.HeartbeatInterval(), "offered interval is used until negotiation")
+
+			frames, err := s.Heartbeat()
+			require.NoError(t, err)
+			assert.Empty(t, frames, "heartbeats are not sent before CONNECTED")
+
+			msg, err := s.Decode([]byte("CONNECTED\n"+tt.connected+"\n\n\x00"), false)
+			require.NoError(t, err)
+			assert.Contains(t, msg.Display, `"command":"CONNECTED"`)
+
+			assert.Equal(t, tt.expected, s.HeartbeatInterval())
+
+			frames, err = s.Heartbeat()
+			require.NoError(t, err)
+
+			if tt.expectFrame {
+				assert.Equal(t, []core.ProtocolFrame{{Data: []byte("\n")}}, frames)
+			} else {
+				assert.Empty(t, frames)
+			}
+		})
+	}
+}
+
+func TestSTOMP_Decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr string
+		expected    string
+	}{
+		{
+			name: "heartbeat",
+			data: "\r\n",
+		},
+		{
+			name:     "message with JSON body",
+			data:     "MESSAGE\nsubscription:sub-0\ndestination:/topic/prices\nmessage-id:7\ncontent-type:application/json\n\n{\"price\": 10}\x00\n",
+			expected: `{"headers":{"content-type":"application/json","destination":"/topic/prices","message-id":"7","subscription":"sub-0"},"command":"MESSAGE","body":{"price":10}}`,
+		},
+		{
+			name:     "message with text body",
+			data:     "MESSAGE\ndestination:/queue/a\\cb\n\nhello\x00",
+			expected: `{"headers":{"destination":"/queue/a:b"},"command":"MESSAGE","body":"hello"}`,
+		},
+		{
+			name:     "content length with NUL in body",
+			data:     "MESSAGE\r\ncontent-length:3\r\n\r\na\x00b\x00",
+			expected: `{"headers":{"content-length":"3"},"command":"MESSAGE","body":"a\u0000b"}`,
+		},
+		{
+			name:     "repeated header",
+			data:     "MESSAGE\nfoo:first\nfoo:second\n\n\x00",
+			expected: `{"headers":{"foo":"first"},"command":"MESSAGE"}`,
+		},
+		{
+			name:     "leading heartbeats",
+			data:     "\n\nRECEIPT\nreceipt-id:1\n\n\x00",
+			expected: `{"headers":{"receipt-id":"1"},"command":"RECEIPT"}`,
+		},
+		{
+			name:        "missing terminator",
+			data:        "MESSAGE\n\nhello",
+			expectedErr: "invalid STOMP frame: missing NUL terminator",
+		},
+		{
+			name:        "missing end of headers",
+			data:        "MESSAGE\nfoo:bar",
+			expectedErr: "invalid STOMP frame: missing end of headers",
+		},
+		{
+			name:        "invalid header",
+			data:        "MESSAGE\nfoo\n\n\x00",
+			expectedErr: `invalid STOMP header: "foo"`,
+		},
+		{
+			name:        "invalid content length",
+			data:        "MESSAGE\ncontent-length:10\n\nabc\x00",
+			expectedErr: "invalid STOMP content-length: 10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSTOMP(nil, 0)
+			require.NoError(t, err)
+
+			msg, err := s.Decode([]byte(tt.data), false)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg.Display)
+			assert.Empty(t, msg.Replies)
+		})
+	}
+}
+
+func TestSTOMP_Encode(t *testing.T) {
+	tests := []struct {
+		name         string
+		command      string
+		args         string
+		expectedErr  string
+		expectedData string
+	}{
+		{
+			name:         "send JSON",
+			command:      "stomp-send",
+			args:         `/queue/orders {"id": 1}`,
+			expectedData: "SEND\ndestination:/queue/orders\ncontent-type:application/json\ncontent-length:9\n\n{\"id\": 1}\x00",
+		},
+		{
+			name:         "send text",
+			command:      "stomp-send",
+			args:         "/queue/a:b hello world",
+			expectedData: "SEND\ndestination:/queue/a\\cb\ncontent-type:text/plain\ncontent-length:11\n\nhello world\x00",
+		},
+		{
+			name:        "send without destination",
+			command:     "stomp-send",
+			args:        " ",
+			expectedErr: "destination is required",
+		},
+		{
+			name:        "subscribe without destination",
+			command:     "subscribe",
+			expectedErr: "destination is required",
+		},
+		{
+			name:        "unknown subscription",
+			command:     "unsubscribe",
+			args:        "sub-5",
+			expectedErr: "unknown subscription: sub-5",
+		},
+		{
+			name:        "unsupported command",
+			command:     "emit",
+			args:        "chat",
+			expectedErr: "command is not supported by the protocol: emit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSTOMP(nil, 0)
+			require.NoError(t, err)
+
+			frames, err := s.Encode(tt.command, tt.args)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, frames, 1)
+			assert.Equal(t, tt.expectedData, string(frames[0].Data))
+		})
+	}
+}
+
+func TestSTOMP_Subscriptions(t *testing.T) {
+	s, err := NewSTOMP(nil, 0)
+	require.NoError(t, err)
+
+	frames, err := s.Encode("subscribe", "/topic/prices")
+	require.NoError(t, err)
+	assert.Equal(t, "SUBSCRIBE\nid:sub-0\ndestination:/topic/prices\nack:auto\n\n\x00", string(frames[0].Data))
+	assert.Equal(t, `{"headers":{"ack":"auto","destination":"/topic/prices","id":"sub-0"},"command":"SUBSCRIBE"}`, frames[0].Display)
+
+	_, err = s.Encode("subscribe", "/topic/news")
+	require.NoError(t, err)
+
+	frames, err = s.Encode("unsubscribe", "/topic/news")
+	require.NoError(t, err)
+	assert.Equal(t, "UNSUBSCRIBE\nid:sub-1\n\n\x00", string(frames[0].Data), "subscription is found by destination")
+
+	frames, err = s.Encode("unsubscribe", "sub-0")
+	require.NoError(t, err)
+	assert.Equal(t, "UNSUBSCRIBE\nid:sub-0\n\n\x00", string(frames[0].Data))
+
+	_, err = s.Encode("unsubscribe", "sub-0")
+	assert.EqualError(t, err, "unknown subscription: sub-0")
+
+	_, err = s.Encode("subscribe", "/topic/prices")
+	require.NoError(t, err)
+
+	_, err = s.Open()
+	require.NoError(t, err)
+
+	_, err = s.Encode("unsubscribe", "sub-2")
+	assert.EqualError(t, err, "unknown subscription: sub-2", "subscriptions do not survive reconnection")
+}
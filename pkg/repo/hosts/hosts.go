@@ -0,0 +1,101 @@
+package hosts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Host contains connection settings applied to every connection to a matching host.
+// Relative file paths are resolved against the directory of the hosts file.
+type Host struct {
+	Cert          string `yaml:"cert,omitempty"`
+	Key           string `yaml:"key,omitempty"`
+	CACert        string `yaml:"cacert,omitempty"`
+	TLSMinVersion string `yaml:"tls-min-version,omitempty"`
+	ServerName    string `yaml:"server-name,omitempty"`
+}
+
+// Config represents the hosts configuration file.
+// Hosts are keyed by domain, a domain matches the host itself and all its subdomains.
+type Config struct {
+	Hosts   map[string]Host `yaml:"hosts"`
+	Version string          `yaml:"version"`
+}
+
+// LoadFromFile reads the hosts configuration from the provided file path.
+// It takes path of type string.
+// It returns a pointer to Config, or nil if the file does not exist.
+// It returns an error if the file cannot be read, decoded or has an unsupported version.
+func LoadFromFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse hosts file: %w", err)
+	}
+
+	if cfg.Version != "1" {
+		return nil, fmt.Errorf("unsupported hosts version: %s", cfg.Version)
+	}
+
+	dir := filepath.Dir(path)
+
+	for domain, host := range cfg.Hosts {
+		host.Cert = resolvePath(dir, host.Cert)
+		host.Key = resolvePath(dir, host.Key)
+		host.CACert = resolvePath(dir, host.CACert)
+		cfg.Hosts[domain] = host
+	}
+
+	return &cfg, nil
+}
+
+// Lookup finds the settings for the provided host name.
+// It takes hostname of type string.
+// It returns the settings of the most specific matching domain and true, or an empty Host and false if no domain matches.
+func (c *Config) Lookup(hostname string) (Host, bool) {
+	if c == nil {
+		return Host{}, false
+	}
+
+	var (
+		match Host
+		found bool
+		best  int
+	)
+
+	hostname = strings.ToLower(hostname)
+
+	for domain, host := range c.Hosts {
+		domain = strings.ToLower(domain)
+
+		if hostname != domain && !strings.HasSuffix(hostname, "."+domain) {
+			continue
+		}
+
+		if !found || len(domain) > best {
+			match, found, best = host, true, len(domain)
+		}
+	}
+
+	return match, found
+}
+
+// resolvePath returns path joined with dir if it is relative, empty and absolute paths are returned unchanged.
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,95 @@
+package hosts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts.yaml")
+
+	content := `version: "1"
+hosts:
+  internal.example.com:
+    cert: certs/client.crt
+    key: /etc/ssl/client.key
+    cacert: certs/ca.pem
+    tls-min-version: "1.3"
+    server-name: gateway.internal
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	cfg, err := LoadFromFile(path)
+	require.NoError(t, err)
+
+	host, ok := cfg.Lookup("internal.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, Host{
+		Cert:          filepath.Join(dir, "certs/client.crt"),
+		Key:           "/etc/ssl/client.key",
+		CACert:        filepath.Join(dir, "certs/ca.pem"),
+		TLSMinVersion: "1.3",
+		ServerName:    "gateway.internal",
+	}, host)
+}
+
+func TestLoadFromFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := LoadFromFile(filepath.Join(dir, "missing.yaml"))
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("hosts: ["), 0o600))
+
+	_, err = LoadFromFile(invalid)
+	assert.ErrorContains(t, err, "failed to parse hosts file")
+
+	unsupported := filepath.Join(dir, "unsupported.yaml")
+	require.NoError(t, os.WriteFile(unsupported, []byte("version: \"2\"\n"), 0o600))
+
+	_, err = LoadFromFile(unsupported)
+	assert.ErrorContains(t, err, "unsupported hosts version: 2")
+}
+
+func TestConfig_Lookup(t *testing.T) {
+	cfg := &Config{
+		Version: "1",
+		Hosts: map[string]Host{
+			"example.com":          {ServerName: "generic"},
+			"internal.example.com": {ServerName: "internal"},
+		},
+	}
+
+	tests := []struct {
+		hostname string
+		expected string
+		found    bool
+	}{
+		{hostname: "example.com", expected: "generic", found: true},
+		{hostname: "api.example.com", expected: "generic", found: true},
+		{hostname: "internal.example.com", expected: "internal", found: true},
+		{hostname: "ws.Internal.Example.com", expected: "internal", found: true},
+		{hostname: "notexample.com", found: false},
+		{hostname: "example.org", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hostname, func(t *testing.T) {
+			host, ok := cfg.Lookup(tt.hostname)
+			assert.Equal(t, tt.found, ok)
+			assert.Equal(t, tt.expected, host.ServerName)
+		})
+	}
+
+	var empty *Config
+
+	_, ok := empty.Lookup("example.com")
+	assert.False(t, ok)
+}
@@ -8,12 +8,13 @@ import (
 )
 
 // config represents the configuration structure used for YAML parsing and validation.
-// It contains fields for the version, source file, macros, and associated domains.
+// It contains fields for the version, source file, default subprotocol, macros, and associated domains.
 type config struct {
-	Version string              `yaml:"version"`
-	Source  string              `yaml:"source,omitempty"`
-	Macro   map[string][]string `yaml:"macro"`
-	Domains []string            `yaml:"domains"`
+	Version     string              `yaml:"version"`
+	Source      string              `yaml:"source,omitempty"`
+	Subprotocol string              `yaml:"subprotocol,omitempty"`
+	Macro       map[string][]string `yaml:"macro"`
+	Domains     []string            `yaml:"domains"`
 }
 
 // newConfig creates and initializes a new config object from the provided YAML input.
@@ -46,6 +47,7 @@ func (c *config) SetSource(source string) {
 // It returns an error if adding any macro commands to the Repo fails.
 func (c *config) CreateRepo() (*Repo, error) {
 	repo := New(c.Domains)
+	repo.subprotocol = c.Subprotocol
 
 	for name, rawCommands := range c.Macro {
 		err := repo.AddCommands(name, rawCommands)
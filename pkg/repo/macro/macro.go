@@ -10,8 +10,9 @@ import (
 )
 
 type Repo struct {
-	macro   map[string]*command.Templates
-	domains []string
+	macro       map[string]*command.Templates
+	subprotocol string
+	domains     []string
 }
 
 // New creates a new Repo instance with the specified domains.
@@ -49,8 +50,16 @@ func (m *Repo) AddCommands(name string, rawCommands []string) error {
 }
 
 // merge merges the given macro into the current macro.
-// If a macro with the same name already exists, an error is returned.
+// If a macro with the same name already exists or the default subprotocols conflict, an error is returned.
 func (m *Repo) merge(macro *Repo) error {
+	if m.subprotocol != "" && macro.subprotocol != "" && m.subprotocol != macro.subprotocol {
+		return fmt.Errorf("conflicting subprotocols %q and %q during merge", m.subprotocol, macro.subprotocol)
+	}
+
+	if m.subprotocol == "" {
+		m.subprotocol = macro.subprotocol
+	}
+
 	for name, cmd := range macro.macro {
 		if _, ok := m.macro[name]; ok {
 			return fmt.Errorf("duplicate macro %q during merge", name)
@@ -78,6 +87,13 @@ func (m *Repo) Get(name, argString string) (core.Executer, error) {
 	return nil, fmt.Errorf("unknown command: %s", name)
 }
 
+// Has reports whether a macro with the given name exists.
+func (m *Repo) Has(name string) bool {
+	_, ok := m.macro[name]
+
+	return ok
+}
+
 // GetNames returns a list of all macro names stored in the Repo instance.
 // It does not take any parameters.
 // It returns a slice of strings containing the names of the macros.
@@ -91,6 +107,12 @@ func (m *Repo) GetNames() []string {
 	return names
 }
 
+// Subprotocol returns the default WebSocket subprotocol declared for the macro domains.
+// It returns an empty string if no subprotocol is declared.
+func (m *Repo) Subprotocol() string {
+	return m.subprotocol
+}
+
 // LoadFromFile loads a macro configuration from a file at the given path.
 // It returns a Repo instance and an error if the file cannot be read or parsed.
 func LoadFromFile(path string) (r *Repo, err error) {
@@ -185,6 +185,19 @@ func TestMacro_Merge(t *testing.T) {
 			wantErr:     true,
 			expectedLen: 1,
 		},
+		{
+			name: "merge macro with conflicting subprotocols",
+			macro: &Repo{
+				macro:       make(map[string]*command.Templates),
+				subprotocol: "v1.json",
+			},
+			otherMacro: &Repo{
+				macro:       make(map[string]*command.Templates),
+				subprotocol: "v2.json",
+			},
+			wantErr:     true,
+			expectedLen: 0,
+		},
 	}
 
 	for _, tt := range tests {
@@ -259,6 +272,19 @@ func TestMacro_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestMacro_MergeSubprotocol(t *testing.T) {
+	macro := New(nil)
+
+	err := macro.merge(&Repo{macro: make(map[string]*command.Templates), subprotocol: "graphql-transport-ws"})
+	assert.NoError(t, err)
+	assert.Equal(t, "graphql-transport-ws", macro.Subprotocol())
+
+	err = macro.merge(&Repo{macro: make(map[string]*command.Templates)})
+	assert.NoError(t, err)
+	assert.Equal(t, "graphql-transport-ws", macro.Subprotocol())
+}
+
 func TestLoadFromFile(t *testing.T) {
 	macroDir := os.TempDir()
 	domain := "example.com"
@@ -274,6 +300,7 @@ func TestLoadFromFile(t *testing.T) {
 version: 1
 domains:
   - example.com
+subprotocol: v2.json
 macro:
   test:
     - send hello
@@ -302,6 +329,10 @@ macro:
 		t.Errorf("LoadFromFile() domain = %s, want %s", macro.domains[0], domain)
 	}
 
+	if macro.Subprotocol() != "v2.json" {
+		t.Errorf("LoadFromFile() subprotocol = %s, want %s", macro.Subprotocol(), "v2.json")
+	}
+
 	cmd, err := macro.Get("test", "")
 	if err != nil {
 		t.Errorf("LoadFromFile() error = %v, want nil", err)
@@ -410,6 +441,14 @@ func TestMacro_GetNames(t *testing.T) {
 	}
 }
 
+func TestMacro_Has(t *testing.T) {
+	macro := New(nil)
+	assert.NoError(t, macro.AddCommands("subscribe", []string{"send subscribe"}))
+
+	assert.True(t, macro.Has("subscribe"))
+	assert.False(t, macro.Has("unsubscribe"))
+}
+
 func TestMacro_LoadMacroForDomain(t *testing.T) {
 	tests := []struct {
 		name        string
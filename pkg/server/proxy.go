@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/coder/websocket"
+	"github.com/fatih/color"
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/ksysoev/wsget/pkg/ws"
+)
+
+// Upstream is the connection to the upstream server the proxy relays a client to.
+type Upstream interface {
+	SetOnMessage(func(context.Context, []byte, bool))
+	Connect(ctx context.Context) error
+	Ready() <-chan struct{}
+	Subprotocol() string
+	Send(ctx context.Context, msg string) error
+	SendBinary(ctx context.Context, data []byte) error
+	CloseWithStatus(code int, reason string) error
+	CloseStatus() ws.CloseStatus
+}
+
+// UpstreamDialer creates a connection to the upstream server requesting the subprotocols offered by the client.
+type UpstreamDialer func(subprotocols []string) (Upstream, error)
+
+// Proxy is a logging relay between WebSocket clients and an upstream server.
+// Every client gets its own upstream connection, frames are relayed in both directions and printed.
+type Proxy struct {
+	dial     UpstreamDialer
+	formater core.Formater
+	file     io.Writer
+	log      *logger
+	clients  atomic.Int64
+}
+
+// upstreamMessage is a message received from the upstream server.
+type upstreamMessage struct {
+	data     []byte
+	isBinary bool
+}
+
+// NewProxy creates a new logging relay.
+// It takes dial of type UpstreamDialer, formater of type core.Formater for printing relayed messages,
+// output of type io.Writer for the traffic log and file of type io.Writer for recording messages, which may be nil.
+// It returns a pointer to the Proxy.
+func NewProxy(dial UpstreamDialer, formater core.Formater, output, file io.Writer) *Proxy {
+	return &Proxy{
+		dial:     dial,
+		formater: formater,
+		file:     file,
+		log:      newLogger(output),
+	}
+}
+
+// Serve accepts client connections on the listener and relays them until the context is canceled.
+// It takes ctx of type context.Context and listener of type net.Listener.
+// It returns an error if serving fails, and nil after a graceful shutdown.
+func (p *Proxy) Serve(ctx context.Context, listener net.Listener) error {
+	return serve(ctx, listener, p)
+}
+
+// ServeHTTP connects to the upstream server, accepts the client connection with the subprotocol
+// negotiated upstream and relays messages until either side closes the connection.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := p.clients.Add(1)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	upstream, err := p.dial(clientSubprotocols(r))
+	if err != nil {
+		p.log.logf(id, "failed to create upstream connection: %s", err)
+		http.Error(w, "failed to connect to upstream", http.StatusBadGateway)
+
+		return
+	}
+
+	messages := make(chan upstreamMessage)
+
+	upstream.SetOnMessage(func(ctx context.Context, data []byte, isBinary bool) {
+		select {
+		case messages <- upstreamMessage{data: data, isBinary: isBinary}:
+		case <-ctx.Done():
+		}
+	})
+
+	upstreamDone := make(chan error, 1)
+
+	go func() { upstreamDone <- upstream.Connect(ctx) }()
+
+	select {
+	case <-upstream.Ready():
+	case err := <-upstreamDone:
+		p.log.logf(id, "failed to connect to upstream: %v", err)
+		http.Error(w, "failed to connect to upstream", http.StatusBadGateway)
+
+		return
+	case <-ctx.Done():
+		return
+	}
+
+	opts := &websocket.AcceptOptions{InsecureSkipVerify: true}
+	if subprotocol := upstream.Subprotocol(); subprotocol != "" {
+		opts.Subprotocols = []string{subprotocol}
+	}
+
+	client, err := websocket.Accept(w, r, opts)
+	if err != nil {
+		p.log.logf(id, "failed to accept connection from %s: %s", r.RemoteAddr, err)
+		_ = upstream.CloseWithStatus(int(websocket.StatusGoingAway), "client handshake failed")
+
+		return
+	}
+
+	client.SetReadLimit(-1)
+
+	p.log.logf(id, "connected from %s", r.RemoteAddr)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		p.relayUpstream(ctx, client, upstream, id, messages, upstreamDone)
+		cancel()
+	}()
+
+	p.relayClient(ctx, client, upstream, id)
+	cancel()
+	wg.Wait()
+
+	_ = client.CloseNow()
+
+	p.log.logf(id, "disconnected")
+}
+
+// relayClient sends messages of the client to the upstream server until the client connection fails.
+// The close status of the client is passed to the upstream server.
+func (p *Proxy) relayClient(ctx context.Context, client *websocket.Conn, upstream Upstream, id int64) {
+	for {
+		typ, data, err := client.Read(ctx)
+		if err != nil {
+			code, reason := int(websocket.StatusGoingAway), "client disconnected"
+
+			var closeErr websocket.CloseError
+			if errors.As(err, &closeErr) {
+				p.log.logf(id, "client closed connection: %d %s", closeErr.Code, closeErr.Reason)
+
+				if isSendableCloseCode(int(closeErr.Code)) {
+					code, reason = int(closeErr.Code), closeErr.Reason
+				}
+			}
+
+			_ = upstream.CloseWithStatus(code, reason)
+
+			return
+		}
+
+		if typ == websocket.MessageBinary {
+			p.print(id, core.Message{Type: core.RequestBinary, Data: base64.StdEncoding.EncodeToString(data)})
+			err = upstream.SendBinary(ctx, data)
+		} else {
+			p.print(id, core.Message{Type: core.Request, Data: string(data)})
+			err = upstream.Send(ctx, string(data))
+		}
+
+		if err != nil {
+			p.log.logf(id, "failed to relay message to upstream: %s", err)
+			_ = client.Close(websocket.StatusInternalError, "upstream failure")
+
+			return
+		}
+	}
+}
+
+// relayUpstream sends messages of the upstream server to the client until the upstream connection terminates.
+// The close status of the upstream server is passed to the client.
+func (p *Proxy) relayUpstream(
+	ctx context.Context,
+	client *websocket.Conn,
+	upstream Upstream,
+	id int64,
+	messages <-chan upstreamMessage,
+	upstreamDone <-chan error,
+) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-upstreamDone:
+			code, reason := websocket.StatusGoingAway, "upstream connection closed"
+
+			if status := upstream.CloseStatus(); status.Remote {
+				p.log.logf(id, "upstream closed connection: %s", status)
+
+				if isSendableCloseCode(status.Code) {
+					code, reason = websocket.StatusCode(status.Code), status.Reason
+				}
+			}
+
+			_ = client.Close(code, reason)
+
+			return
+		case msg := <-messages:
+			typ := websocket.MessageText
+
+			if msg.isBinary {
+				typ = websocket.MessageBinary
+				p.print(id, core.Message{Type: core.ResponseBinary, Data: base64.StdEncoding.EncodeToString(msg.data)})
+			} else {
+				p.print(id, core.Message{Type: core.Response, Data: string(msg.data)})
+			}
+
+			if err := client.Write(ctx, typ, msg.data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// isSendableCloseCode reports whether the close code may be sent in a close frame.
+// Codes 1005, 1006 and 1015 are reserved for reporting and must not be sent.
+func isSendableCloseCode(code int) bool {
+	switch code {
+	case int(websocket.StatusNoStatusRcvd), int(websocket.StatusAbnormalClosure), int(websocket.StatusTLSHandshake):
+		return false
+	default:
+		return code >= int(websocket.StatusNormalClosure) && code < 5000
+	}
+}
+
+// print writes the relayed message to the traffic log with a direction marker and records it to the file.
+func (p *Proxy) print(id int64, msg core.Message) {
+	output, err := p.formater.FormatMessage(msg.Type.String(), msg.Data)
+	if err != nil {
+		output = msg.Data
+	}
+
+	var (
+		marker string
+		attr   color.Attribute
+	)
+
+	switch msg.Type {
+	case core.Response:
+		marker, attr = "<-", color.FgRed
+	case core.ResponseBinary:
+		marker, attr = "0101 <-", color.FgRed
+	case core.RequestBinary:
+		marker, attr = "0101 ->", color.FgGreen
+	default:
+		marker, attr = "->", color.FgGreen
+	}
+
+	p.log.write(func(w io.Writer) {
+		_, _ = color.New(attr).Fprintf(w, "[client %d] %s\n", id, marker)
+		_, _ = fmt.Fprintln(w, output)
+	})
+
+	if p.file == nil {
+		return
+	}
+
+	fileOutput, err := p.formater.FormatForFile(msg.Type.String(), msg.Data)
+	if err != nil {
+		fileOutput = msg.Data
+	}
+
+	p.log.write(func(io.Writer) {
+		_, _ = fmt.Fprintln(p.file, fileOutput)
+	})
+}
+
+// clientSubprotocols returns the subprotocols requested by the client in order of preference.
+func clientSubprotocols(r *http.Request) []string {
+	var subprotocols []string
+
+	for _, value := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, subprotocol := range strings.Split(value, ",") {
+			if subprotocol = strings.TrimSpace(subprotocol); subprotocol != "" {
+				subprotocols = append(subprotocols, subprotocol)
+			}
+		}
+	}
+
+	return subprotocols
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/ksysoev/wsget/pkg/core/formater"
+	"github.com/ksysoev/wsget/pkg/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	buf bytes.Buffer
+	l   sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	return b.buf.String()
+}
+
+func startProxy(t *testing.T, upstreamURL string, output *syncBuffer, file io.Writer) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	dial := func(subprotocols []string) (Upstream, error) {
+		return ws.New(upstreamURL, &ws.Options{Subprotocols: subprotocols})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- NewProxy(dial, formater.NewFormat(), output, file).Serve(ctx, listener) }()
+
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	return "ws://" + listener.Addr().String()
+}
+
+func TestProxy_Relay(t *testing.T) {
+	output, file := &syncBuffer{}, &syncBuffer{}
+	proxyURL := startProxy(t, startServer(t, nil), output, file)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, proxyURL, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(`{"ping":1}`)))
+	assert.Equal(t, `{"ping":1}`, readText(ctx, t, conn))
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageBinary, []byte{0x01, 0x02}))
+
+	typ, data, err := conn.Read(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, websocket.MessageBinary, typ)
+	assert.Equal(t, []byte{0x01, 0x02}, data)
+
+	require.NoError(t, conn.Close(websocket.StatusNormalClosure, ""))
+
+	assert.Eventually(t, func() bool { return bytes.Contains([]byte(output.String()), []byte("disconnected")) }, time.Second, 10*time.Millisecond)
+
+	log := output.String()
+	assert.Contains(t, log, "[client 1] ->")
+	assert.Contains(t, log, "[client 1] <-")
+	assert.Contains(t, log, "[client 1] 0101 ->")
+	assert.Contains(t, log, "[client 1] 0101 <-")
+	assert.Equal(t, "{\"ping\":1}\n{\"ping\":1}\nAQI=\nAQI=\n", file.String())
+}
+
+func TestProxy_UpstreamClose(t *testing.T) {
+	rules, err := NewRules(&Config{
+		Version: "1",
+		Rules:   []Rule{{Match: Match{Text: "bye"}, Close: &Close{Code: 4001, Reason: "bye"}}},
+	})
+	require.NoError(t, err)
+
+	proxyURL := startProxy(t, startServer(t, rules), &syncBuffer{}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, proxyURL, nil)
+	require.NoError(t, err)
+
+	defer func() { _ = conn.CloseNow() }()
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("bye")))
+
+	_, _, err = conn.Read(ctx)
+	assert.Equal(t, websocket.StatusCode(4001), websocket.CloseStatus(err))
+}
+
+func TestProxy_UpstreamUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	upstreamURL := "ws://" + listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	proxyURL := startProxy(t, upstreamURL, &syncBuffer{}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, resp, err := websocket.Dial(ctx, proxyURL, nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
+
+func TestClientSubprotocols(t *testing.T) {
+	r := &http.Request{Header: http.Header{}}
+	r.Header.Add("Sec-WebSocket-Protocol", "graphql-ws, graphql-transport-ws")
+	r.Header.Add("Sec-WebSocket-Protocol", "mqtt")
+
+	assert.Equal(t, []string{"graphql-ws", "graphql-transport-ws", "mqtt"}, clientSubprotocols(r))
+}
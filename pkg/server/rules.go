@@ -0,0 +1,256 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core/command"
+	"gopkg.in/yaml.v3"
+)
+
+// Match describes the conditions an incoming message should satisfy, all non-empty conditions must hold.
+// An empty match matches every message.
+type Match struct {
+	JSON  map[string]string `yaml:"json,omitempty"`
+	Text  string            `yaml:"text,omitempty"`
+	Regex string            `yaml:"regex,omitempty"`
+}
+
+// Close describes the close frame the server sends after the replies of a rule.
+type Close struct {
+	Reason string `yaml:"reason,omitempty"`
+	Code   int    `yaml:"code"`
+}
+
+// Rule describes how the server answers a matching incoming message.
+// Replies are templates rendered with the incoming message, see TemplateData.
+type Rule struct {
+	Close *Close        `yaml:"close,omitempty"`
+	Match Match         `yaml:"match"`
+	Reply []string      `yaml:"reply,omitempty"`
+	Delay time.Duration `yaml:"delay,omitempty"`
+}
+
+// Push describes a message the server sends to every client periodically.
+type Push struct {
+	Message  string        `yaml:"message"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Config represents the rules file of the mock server.
+type Config struct {
+	Version   string   `yaml:"version"`
+	OnConnect []string `yaml:"on_connect,omitempty"`
+	Push      []Push   `yaml:"push,omitempty"`
+	Rules     []Rule   `yaml:"rules"`
+}
+
+// TemplateData is the data replies and pushes are rendered with.
+// Message is the incoming message, JSON is the message decoded from JSON or nil if it is not valid JSON,
+// Args are the capture groups of the rule regex and Seq is the number of the incoming message or push tick.
+type TemplateData struct {
+	JSON    any
+	Message string
+	Args    []string
+	Seq     int
+}
+
+// Rules is the compiled form of the rules file.
+type Rules struct {
+	onConnect *command.Templates
+	push      []push
+	rules     []rule
+}
+
+type rule struct {
+	close *Close
+	regex *regexp.Regexp
+	reply *command.Templates
+	json  map[string]string
+	text  string
+	delay time.Duration
+}
+
+type push struct {
+	message  *command.Templates
+	interval time.Duration
+}
+
+// response is the answer of the server to an incoming message.
+type response struct {
+	close   *Close
+	replies []string
+	delay   time.Duration
+}
+
+// LoadRules reads and compiles the rules file at the provided path.
+// It takes path of type string.
+// It returns a pointer to Rules and an error if the file cannot be read, parsed or compiled.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	return NewRules(&cfg)
+}
+
+// NewRules compiles the provided rules configuration.
+// It takes cfg of type *Config.
+// It returns a pointer to Rules and an error if the version is not supported,
+// a regex or template cannot be compiled, or a push interval or close code is invalid.
+func NewRules(cfg *Config) (*Rules, error) {
+	if cfg.Version != "1" {
+		return nil, fmt.Errorf("unsupported rules version: %s", cfg.Version)
+	}
+
+	onConnect, err := command.NewMacro(cfg.OnConnect)
+	if err != nil {
+		return nil, fmt.Errorf("invalid on_connect template: %w", err)
+	}
+
+	r := &Rules{onConnect: onConnect}
+
+	for i, p := range cfg.Push {
+		if p.Interval <= 0 {
+			return nil, fmt.Errorf("push %d: interval should be positive", i+1)
+		}
+
+		tmpl, err := command.NewMacro([]string{p.Message})
+		if err != nil {
+			return nil, fmt.Errorf("push %d: invalid message template: %w", i+1, err)
+		}
+
+		r.push = append(r.push, push{message: tmpl, interval: p.Interval})
+	}
+
+	for i := range cfg.Rules {
+		compiled, err := compileRule(&cfg.Rules[i])
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+
+		r.rules = append(r.rules, compiled)
+	}
+
+	return r, nil
+}
+
+// compileRule compiles the regex and the reply templates of the rule.
+// It returns an error if the regex or a template is invalid, or the close code cannot be sent by the server.
+func compileRule(cfg *Rule) (rule, error) {
+	r := rule{
+		text:  cfg.Match.Text,
+		json:  cfg.Match.JSON,
+		delay: cfg.Delay,
+		close: cfg.Close,
+	}
+
+	if cfg.Match.Regex != "" {
+		re, err := regexp.Compile(cfg.Match.Regex)
+		if err != nil {
+			return rule{}, fmt.Errorf("invalid regex: %w", err)
+		}
+
+		r.regex = re
+	}
+
+	reply, err := command.NewMacro(cfg.Reply)
+	if err != nil {
+		return rule{}, fmt.Errorf("invalid reply template: %w", err)
+	}
+
+	r.reply = reply
+
+	if cfg.Close != nil && (cfg.Close.Code < 1000 || cfg.Close.Code > 4999) {
+		return rule{}, fmt.Errorf("invalid close code: %d", cfg.Close.Code)
+	}
+
+	return r, nil
+}
+
+// respond finds the first rule matching the message and renders its replies.
+// It takes msg of type string and seq of type int, the number of the message on the connection.
+// It returns the response and true, or false if no rule matches the message.
+// It returns an error if rendering the replies fails.
+func (r *Rules) respond(msg string, seq int) (response, bool, error) {
+	data := TemplateData{Message: msg, Seq: seq}
+
+	var decoded any
+	if json.Unmarshal([]byte(msg), &decoded) == nil {
+		data.JSON = decoded
+	}
+
+	for _, rl := range r.rules {
+		args, ok := rl.match(msg, decoded)
+		if !ok {
+			continue
+		}
+
+		data.Args = args
+
+		replies, err := rl.reply.Render(data)
+		if err != nil {
+			return response{}, true, fmt.Errorf("failed to render reply: %w", err)
+		}
+
+		return response{replies: replies, delay: rl.delay, close: rl.close}, true, nil
+	}
+
+	return response{}, false, nil
+}
+
+// match reports whether the message satisfies all conditions of the rule.
+// It takes msg of type string and decoded, the message decoded from JSON or nil.
+// It returns the capture groups of the rule regex and true if the message matches.
+func (rl *rule) match(msg string, decoded any) ([]string, bool) {
+	if rl.text != "" && rl.text != msg {
+		return nil, false
+	}
+
+	for path, expected := range rl.json {
+		value, ok := lookupField(decoded, path)
+		if !ok || fmt.Sprint(value) != expected {
+			return nil, false
+		}
+	}
+
+	if rl.regex == nil {
+		return nil, true
+	}
+
+	groups := rl.regex.FindStringSubmatch(msg)
+	if groups == nil {
+		return nil, false
+	}
+
+	return groups[1:], true
+}
+
+// lookupField returns the value of the field at the dot-separated path in the decoded JSON document.
+// It returns false if the document has no such field.
+func lookupField(doc any, path string) (any, bool) {
+	value := doc
+
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
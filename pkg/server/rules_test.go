@@ -0,0 +1,181 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRules(t *testing.T) {
+	tests := []struct {
+		cfg     *Config
+		name    string
+		wantErr string
+	}{
+		{
+			name: "valid rules",
+			cfg: &Config{
+				Version:   "1",
+				OnConnect: []string{"hello"},
+				Push:      []Push{{Message: "tick {{.Seq}}", Interval: time.Second}},
+				Rules: []Rule{
+					{Match: Match{Regex: `^sub (\w+)$`}, Reply: []string{"subscribed {{index .Args 0}}"}},
+					{Match: Match{Text: "bye"}, Close: &Close{Code: 4000, Reason: "bye"}},
+				},
+			},
+		},
+		{
+			name:    "unsupported version",
+			cfg:     &Config{Version: "2"},
+			wantErr: "unsupported rules version: 2",
+		},
+		{
+			name:    "invalid regex",
+			cfg:     &Config{Version: "1", Rules: []Rule{{Match: Match{Regex: "("}}}},
+			wantErr: "rule 1: invalid regex",
+		},
+		{
+			name:    "invalid reply template",
+			cfg:     &Config{Version: "1", Rules: []Rule{{Reply: []string{"{{.Message"}}}},
+			wantErr: "rule 1: invalid reply template",
+		},
+		{
+			name:    "invalid close code",
+			cfg:     &Config{Version: "1", Rules: []Rule{{Close: &Close{Code: 999}}}},
+			wantErr: "rule 1: invalid close code: 999",
+		},
+		{
+			name:    "invalid push interval",
+			cfg:     &Config{Version: "1", Push: []Push{{Message: "tick"}}},
+			wantErr: "push 1: interval should be positive",
+		},
+		{
+			name:    "invalid on_connect template",
+			cfg:     &Config{Version: "1", OnConnect: []string{"{{"}},
+			wantErr: "invalid on_connect template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewRules(tt.cfg)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, rules)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, rules)
+		})
+	}
+}
+
+func TestRules_Respond(t *testing.T) {
+	rules, err := NewRules(&Config{
+		Version: "1",
+		Rules: []Rule{
+			{Match: Match{Text: "ping"}, Reply: []string{"pong"}},
+			{Match: Match{Regex: `^sub (\w+)$`}, Reply: []string{"subscribed to {{index .Args 0}}"}},
+			{
+				Match: Match{JSON: map[string]string{"type": "order", "order.side": "buy"}},
+				Reply: []string{`{"id":{{.JSON.id}},"seq":{{.Seq}}}`},
+				Delay: time.Millisecond,
+			},
+			{Match: Match{Text: "bye"}, Close: &Close{Code: 4000, Reason: "bye"}},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		msg     string
+		want    response
+		matched bool
+	}{
+		{
+			name:    "exact text",
+			msg:     "ping",
+			want:    response{replies: []string{"pong"}},
+			matched: true,
+		},
+		{
+			name:    "regex with capture group",
+			msg:     "sub trades",
+			want:    response{replies: []string{"subscribed to trades"}},
+			matched: true,
+		},
+		{
+			name:    "json fields",
+			msg:     `{"type":"order","id":7,"order":{"side":"buy"}}`,
+			want:    response{replies: []string{`{"id":7,"seq":3}`}, delay: time.Millisecond},
+			matched: true,
+		},
+		{
+			name: "json field mismatch",
+			msg:  `{"type":"order","id":7,"order":{"side":"sell"}}`,
+		},
+		{
+			name:    "close",
+			msg:     "bye",
+			want:    response{replies: []string{}, close: &Close{Code: 4000, Reason: "bye"}},
+			matched: true,
+		},
+		{
+			name: "no match",
+			msg:  "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, ok, err := rules.respond(tt.msg, 3)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.matched, ok)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rules.yaml")
+
+	content := `version: "1"
+on_connect:
+  - '{"type":"welcome"}'
+push:
+  - interval: 5s
+    message: '{"type":"tick","seq":{{.Seq}}}'
+rules:
+  - match:
+      json:
+        type: ping
+    reply:
+      - '{"type":"pong"}'
+    delay: 100ms
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	rules, err := LoadRules(path)
+	require.NoError(t, err)
+	require.Len(t, rules.push, 1)
+	assert.Equal(t, 5*time.Second, rules.push[0].interval)
+	require.Len(t, rules.rules, 1)
+	assert.Equal(t, 100*time.Millisecond, rules.rules[0].delay)
+
+	_, err = LoadRules(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read rules file")
+
+	require.NoError(t, os.WriteFile(path, []byte("version: [\n"), 0o600))
+
+	_, err = LoadRules(path)
+	assert.ErrorContains(t, err, "failed to parse rules file")
+}
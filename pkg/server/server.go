@@ -0,0 +1,268 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+// Server is a WebSocket mock server answering clients according to the rules.
+// Without rules it echoes every incoming message back.
+type Server struct {
+	rules   *Rules
+	log     *logger
+	clients atomic.Int64
+}
+
+// New creates a new mock server.
+// It takes rules of type *Rules, which may be nil for an echo server, and output of type io.Writer for the traffic log.
+// It returns a pointer to the Server.
+func New(rules *Rules, output io.Writer) *Server {
+	return &Server{rules: rules, log: newLogger(output)}
+}
+
+// Serve accepts WebSocket connections on the listener until the context is canceled.
+// It takes ctx of type context.Context and listener of type net.Listener.
+// It returns an error if serving fails, and nil after a graceful shutdown.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	return serve(ctx, listener, s)
+}
+
+// serve runs an HTTP server with the handler on the listener until the context is canceled.
+// Requests are served with the context, so that open WebSocket connections are terminated on shutdown.
+// It returns an error if serving fails, and nil after a graceful shutdown.
+func serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	return nil
+}
+
+// ServeHTTP upgrades the request to a WebSocket connection and serves the client.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{InsecureSkipVerify: true})
+	if err != nil {
+		s.logf(0, "failed to accept connection from %s: %s", r.RemoteAddr, err)
+		return
+	}
+
+	conn.SetReadLimit(-1)
+
+	id := s.clients.Add(1)
+
+	s.logf(id, "connected from %s", r.RemoteAddr)
+
+	err = s.handle(r.Context(), conn, id)
+
+	switch status := websocket.CloseStatus(err); {
+	case status != -1:
+		s.logf(id, "disconnected: %d", status)
+	case err != nil && !errors.Is(err, context.Canceled):
+		s.logf(id, "disconnected: %s", err)
+	default:
+		s.logf(id, "disconnected")
+	}
+
+	_ = conn.CloseNow()
+}
+
+// handle serves a single client: sends the on connect messages, starts the periodic pushes
+// and answers incoming messages until the connection is closed.
+// It returns the error which terminated the connection.
+func (s *Server) handle(ctx context.Context, conn *websocket.Conn, id int64) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if s.rules == nil {
+		return s.echo(ctx, conn, id)
+	}
+
+	greetings, err := s.rules.onConnect.Render(TemplateData{})
+	if err != nil {
+		return fmt.Errorf("failed to render on_connect messages: %w", err)
+	}
+
+	for _, msg := range greetings {
+		if err := s.write(ctx, conn, id, msg); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range s.rules.push {
+		go s.push(ctx, conn, id, p)
+	}
+
+	for seq := 1; ; seq++ {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.logf(id, "<- %s", data)
+
+		resp, ok, err := s.rules.respond(string(data), seq)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			s.logf(id, "no rule matched")
+			continue
+		}
+
+		if err := s.reply(ctx, conn, id, resp); err != nil {
+			return err
+		}
+
+		if resp.close != nil {
+			s.logf(id, "-> close %d %s", resp.close.Code, resp.close.Reason)
+			return conn.Close(websocket.StatusCode(resp.close.Code), resp.close.Reason)
+		}
+	}
+}
+
+// echo sends every incoming message back to the client with the same message type.
+func (s *Server) echo(ctx context.Context, conn *websocket.Conn, id int64) error {
+	for {
+		typ, data, err := conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+
+		s.logf(id, "<- %s", data)
+
+		if err := conn.Write(ctx, typ, data); err != nil {
+			return err
+		}
+
+		s.logf(id, "-> %s", data)
+	}
+}
+
+// reply sends the replies of the response after its delay.
+func (s *Server) reply(ctx context.Context, conn *websocket.Conn, id int64, resp response) error {
+	if resp.delay > 0 {
+		select {
+		case <-time.After(resp.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for _, msg := range resp.replies {
+		if err := s.write(ctx, conn, id, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// push sends the push message to the client every interval until the context is canceled.
+func (s *Server) push(ctx context.Context, conn *websocket.Conn, id int64, p push) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for seq := 1; ; seq++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		msgs, err := p.message.Render(TemplateData{Seq: seq})
+		if err != nil {
+			s.logf(id, "failed to render push message: %s", err)
+			return
+		}
+
+		for _, msg := range msgs {
+			if err := s.write(ctx, conn, id, msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write sends a text message to the client and logs it.
+func (s *Server) write(ctx context.Context, conn *websocket.Conn, id int64, msg string) error {
+	if err := conn.Write(ctx, websocket.MessageText, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	s.logf(id, "-> %s", msg)
+
+	return nil
+}
+
+// logf writes a line to the traffic log prefixed with the client number.
+func (s *Server) logf(id int64, format string, args ...any) {
+	s.log.logf(id, format, args...)
+}
+
+// logger writes the traffic log of the clients, which are served concurrently.
+type logger struct {
+	output io.Writer
+	l      sync.Mutex
+}
+
+// newLogger creates a logger writing to the output, a nil output discards the log.
+func newLogger(output io.Writer) *logger {
+	if output == nil {
+		output = io.Discard
+	}
+
+	return &logger{output: output}
+}
+
+// logf writes a line prefixed with the client number.
+func (l *logger) logf(id int64, format string, args ...any) {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	_, _ = fmt.Fprintf(l.output, "[client %d] %s\n", id, fmt.Sprintf(format, args...))
+}
+
+// write calls fn with the log output, so that multi-line entries of different clients are not interleaved.
+func (l *logger) write(fn func(w io.Writer)) {
+	l.l.Lock()
+	defer l.l.Unlock()
+
+	fn(l.output)
+}
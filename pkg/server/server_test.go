@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startServer(t *testing.T, rules *Rules) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- New(rules, &bytes.Buffer{}).Serve(ctx, listener) }()
+
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	return "ws://" + listener.Addr().String()
+}
+
+func readText(ctx context.Context, t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+
+	_, data, err := conn.Read(ctx)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestServer_Echo(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, startServer(t, nil), nil)
+	require.NoError(t, err)
+
+	defer func() { _ = conn.CloseNow() }()
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("hello")))
+	assert.Equal(t, "hello", readText(ctx, t, conn))
+}
+
+func TestServer_Rules(t *testing.T) {
+	rules, err := NewRules(&Config{
+		Version:   "1",
+		OnConnect: []string{"welcome"},
+		Push:      []Push{{Message: "tick {{.Seq}}", Interval: 50 * time.Millisecond}},
+		Rules: []Rule{
+			{Match: Match{Text: "ping"}, Reply: []string{"pong {{.Seq}}"}},
+			{Match: Match{Text: "bye"}, Reply: []string{"see you"}, Close: &Close{Code: 4001, Reason: "bye"}},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, startServer(t, rules), nil)
+	require.NoError(t, err)
+
+	defer func() { _ = conn.CloseNow() }()
+
+	assert.Equal(t, "welcome", readText(ctx, t, conn))
+	assert.Equal(t, "tick 1", readText(ctx, t, conn))
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("ping")))
+
+	for {
+		if msg := readText(ctx, t, conn); msg != "tick 2" && msg != "tick 3" {
+			assert.Equal(t, "pong 1", msg)
+			break
+		}
+	}
+
+	require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte("bye")))
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			assert.Equal(t, websocket.StatusCode(4001), websocket.CloseStatus(err))
+			break
+		}
+
+		if msg := string(data); msg != "see you" {
+			assert.Contains(t, msg, "tick")
+		}
+	}
+}
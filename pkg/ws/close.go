@@ -0,0 +1,125 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/coder/websocket"
+)
+
+// CloseStatus describes the close frame that terminated a WebSocket connection.
+// Remote is set when the close handshake was initiated by the server.
+type CloseStatus struct {
+	Reason string
+	Code   int
+	Remote bool
+}
+
+// closeCodeNames contains descriptions of close status codes defined by RFC 6455 and the IANA registry.
+var closeCodeNames = map[int]string{
+	1000: "normal closure",
+	1001: "going away",
+	1002: "protocol error",
+	1003: "unsupported data",
+	1005: "no status received",
+	1006: "abnormal closure",
+	1007: "invalid frame payload data",
+	1008: "policy violation",
+	1009: "message too big",
+	1010: "mandatory extension",
+	1011: "internal error",
+	1012: "service restart",
+	1013: "try again later",
+	1014: "bad gateway",
+	1015: "TLS handshake",
+}
+
+// String returns a human-readable description of the close status, e.g. "1008 policy violation: token expired".
+func (s CloseStatus) String() string {
+	name, ok := closeCodeNames[s.Code]
+
+	switch {
+	case ok:
+	case s.Code >= 3000 && s.Code < 4000:
+		name = "registered"
+	case s.Code >= 4000 && s.Code < 5000:
+		name = "application"
+	default:
+		name = "unknown"
+	}
+
+	status := fmt.Sprintf("%d %s", s.Code, name)
+	if s.Reason != "" {
+		status += ": " + s.Reason
+	}
+
+	return status
+}
+
+// SetOnClose sets the callback function to be notified when the server closes the connection with a close frame.
+// It takes onClose, a function receiving the context and the CloseStatus sent by the server.
+func (c *Connection) SetOnClose(onClose func(context.Context, CloseStatus)) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.onClose = onClose
+}
+
+// CloseStatus returns the status of the last close frame of the connection.
+// It returns a zero CloseStatus if the connection was not closed with a close frame.
+func (c *Connection) CloseStatus() CloseStatus {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return c.closeStatus
+}
+
+// CloseWithStatus closes the WebSocket connection with the provided status code and reason.
+// It takes code of type int, a WebSocket close status code, and reason of type string.
+// It returns an error if the connection is not established or the close handshake fails.
+// The connection is not re-established after it is closed with this method.
+func (c *Connection) CloseWithStatus(code int, reason string) error {
+	c.l.Lock()
+	ws := c.ws
+	c.closed = true
+
+	if c.closeStatus.Code == 0 {
+		c.closeStatus = CloseStatus{Code: code, Reason: reason}
+	}
+
+	c.l.Unlock()
+
+	if ws == nil {
+		return fmt.Errorf("connection is not established")
+	}
+
+	return ws.Close(websocket.StatusCode(code), reason)
+}
+
+// handleCloseFrame records the close frame received from the server and notifies the onClose callback.
+// It takes ctx of type context.Context and err, the error returned while reading from the connection.
+// Close frames that acknowledge a close initiated with CloseWithStatus are ignored.
+func (c *Connection) handleCloseFrame(ctx context.Context, err error) {
+	var ce websocket.CloseError
+	if !errors.As(err, &ce) {
+		return
+	}
+
+	c.l.Lock()
+
+	if c.closed {
+		c.l.Unlock()
+		return
+	}
+
+	status := CloseStatus{Code: int(ce.Code), Reason: ce.Reason, Remote: true}
+	c.closeStatus = status
+	onClose := c.onClose
+
+	c.l.Unlock()
+
+	if onClose != nil {
+		onClose(ctx, status)
+	}
+}
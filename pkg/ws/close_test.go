@@ -0,0 +1,125 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseStatus_String(t *testing.T) {
+	tests := []struct {
+		expected string
+		status   CloseStatus
+	}{
+		{status: CloseStatus{Code: 1000}, expected: "1000 normal closure"},
+		{status: CloseStatus{Code: 1008, Reason: "token expired"}, expected: "1008 policy violation: token expired"},
+		{status: CloseStatus{Code: 3001}, expected: "3001 registered"},
+		{status: CloseStatus{Code: 2000}, expected: "2000 unknown"},
+		{status: CloseStatus{Code: 4001, Reason: "custom"}, expected: "4001 application: custom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.status.String())
+		})
+	}
+}
+
+func TestConnection_ServerClose(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusPolicyViolation, "token expired")
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	var received CloseStatus
+
+	conn.SetOnClose(func(_ context.Context, status CloseStatus) {
+		received = status
+	})
+
+	err = conn.Connect(context.Background())
+	assert.ErrorContains(t, err, "connection closed: StatusPolicyViolation token expired")
+
+	expected := CloseStatus{Code: 1008, Reason: "token expired", Remote: true}
+
+	assert.Equal(t, expected, received)
+	assert.Equal(t, expected, conn.CloseStatus())
+}
+
+func TestConnection_CloseWithStatus(t *testing.T) {
+	serverErr := make(chan error, 1)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_, _, err = c.Read(r.Context())
+		serverErr <- err
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+	conn.SetOnClose(func(context.Context, CloseStatus) {
+		t.Error("onClose must not be called for a client initiated close")
+	})
+
+	assert.ErrorContains(t, conn.CloseWithStatus(4000, "bye"), "connection is not established")
+
+	conn, err = New("ws://"+s.Listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(context.Background()) }()
+
+	select {
+	case <-conn.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	_ = conn.CloseWithStatus(4000, "bye")
+
+	select {
+	case err := <-serverErr:
+		var ce websocket.CloseError
+
+		require.True(t, errors.As(err, &ce))
+		assert.Equal(t, websocket.StatusCode(4000), ce.Code)
+		assert.Equal(t, "bye", ce.Reason)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for close frame")
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection to stop")
+	}
+
+	assert.Equal(t, CloseStatus{Code: 4000, Reason: "bye"}, conn.CloseStatus())
+}
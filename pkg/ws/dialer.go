@@ -0,0 +1,203 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	networkTCP  = "tcp"
+	networkTCP4 = "tcp4"
+	networkTCP6 = "tcp6"
+	networkUnix = "unix"
+
+	resolveParts = 3
+)
+
+// IP versions that can be forced with DialOptions.IPVersion.
+const (
+	IPv4 = 4
+	IPv6 = 6
+)
+
+// DialOptions contains settings that control how network connections to the server are established.
+// UnixSocket is a path to a Unix domain socket used instead of TCP, the URL host is still used for the Host header.
+// Resolve contains curl-style host:port:addr entries that override DNS resolution for the given host and port.
+// IPVersion forces IPv4 or IPv6 when set to 4 or 6, 0 allows both.
+// Interface is a local interface name or IP address to bind outgoing connections to.
+type DialOptions struct {
+	UnixSocket string
+	Interface  string
+	Resolve    []string
+	IPVersion  int
+}
+
+// dialer establishes network connections according to DialOptions.
+type dialer struct {
+	output     io.Writer
+	resolve    map[string]string
+	localAddr  net.Addr
+	unixSocket string
+	network    string
+}
+
+// newDialer creates a new dialer from the provided options.
+// It takes opts of type DialOptions and output of type io.Writer for verbose logging.
+// It returns a pointer to a dialer and an error if a resolve entry is malformed,
+// the IP version is not supported or the local interface cannot be used.
+func newDialer(opts DialOptions, output io.Writer) (*dialer, error) {
+	d := &dialer{
+		output:     output,
+		unixSocket: opts.UnixSocket,
+		resolve:    make(map[string]string, len(opts.Resolve)),
+	}
+
+	switch opts.IPVersion {
+	case 0:
+		d.network = networkTCP
+	case IPv4:
+		d.network = networkTCP4
+	case IPv6:
+		d.network = networkTCP6
+	default:
+		return nil, fmt.Errorf("unsupported IP version: %d", opts.IPVersion)
+	}
+
+	for _, entry := range opts.Resolve {
+		hostPort, addr, err := parseResolve(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		d.resolve[hostPort] = addr
+	}
+
+	if opts.Interface != "" {
+		ip, err := interfaceIP(opts.Interface, opts.IPVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		d.localAddr = &net.TCPAddr{IP: ip}
+
+		if d.network == networkTCP {
+			d.network = networkTCP6
+			if ip.To4() != nil {
+				d.network = networkTCP4
+			}
+		}
+	}
+
+	return d, nil
+}
+
+// DialContext connects to addr, applying the Unix socket, resolve overrides, IP version and local address settings.
+// It takes ctx of type context.Context, network and addr of type string.
+// It returns the established net.Conn or an error if the connection fails.
+func (d *dialer) DialContext(ctx context.Context, _, addr string) (net.Conn, error) {
+	if d.unixSocket != "" {
+		d.log("* Connecting to %s via unix socket %s\n", addr, d.unixSocket)
+
+		return (&net.Dialer{}).DialContext(ctx, networkUnix, d.unixSocket)
+	}
+
+	if override, ok := d.resolve[strings.ToLower(addr)]; ok {
+		d.log("* Resolved %s to %s\n", addr, override)
+
+		addr = override
+	}
+
+	return (&net.Dialer{LocalAddr: d.localAddr}).DialContext(ctx, d.network, addr)
+}
+
+// log writes a verbose message to the output if it is set.
+func (d *dialer) log(format string, args ...any) {
+	if d.output != nil {
+		_, _ = fmt.Fprintf(d.output, format, args...)
+	}
+}
+
+// parseResolve parses a curl-style resolve entry in host:port:addr format.
+// It takes entry of type string, the address can be an IPv4 or IPv6 address, optionally in square brackets.
+// It returns the host:port to override, the addr:port to connect to, and an error if the entry is malformed.
+func parseResolve(entry string) (hostPort, addr string, err error) {
+	parts := strings.SplitN(entry, ":", resolveParts)
+	if len(parts) != resolveParts || parts[0] == "" {
+		return "", "", fmt.Errorf("invalid resolve entry %q, expected host:port:addr", entry)
+	}
+
+	host, port, ip := parts[0], parts[1], strings.Trim(parts[2], "[]")
+
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", "", fmt.Errorf("invalid port in resolve entry %q", entry)
+	}
+
+	if net.ParseIP(ip) == nil {
+		return "", "", fmt.Errorf("invalid address in resolve entry %q", entry)
+	}
+
+	return net.JoinHostPort(strings.ToLower(host), port), net.JoinHostPort(ip, port), nil
+}
+
+// interfaceIP finds the local IP address to bind outgoing connections to.
+// It takes name of type string, which is either an IP address or a network interface name,
+// and ipVersion of type int, which selects the address family, 0 prefers IPv4.
+// It returns the IP address or an error if the interface does not exist or has no suitable address.
+func interfaceIP(name string, ipVersion int) (net.IP, error) {
+	if ip := net.ParseIP(name); ip != nil {
+		if !matchIPVersion(ip, ipVersion) {
+			return nil, fmt.Errorf("address %s does not match IPv%d", name, ipVersion)
+		}
+
+		return ip, nil
+	}
+
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find interface %q: %w", name, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses of interface %q: %w", name, err)
+	}
+
+	var fallback net.IP
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || !matchIPVersion(ipNet.IP, ipVersion) {
+			continue
+		}
+
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+
+		if fallback == nil {
+			fallback = ipNet.IP
+		}
+	}
+
+	if fallback == nil {
+		return nil, fmt.Errorf("interface %q has no suitable address", name)
+	}
+
+	return fallback, nil
+}
+
+// matchIPVersion reports whether ip belongs to the requested IP version, 0 matches any version.
+func matchIPVersion(ip net.IP, ipVersion int) bool {
+	switch ipVersion {
+	case IPv4:
+		return ip.To4() != nil
+	case IPv6:
+		return ip.To4() == nil
+	default:
+		return true
+	}
+}
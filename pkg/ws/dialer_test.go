@@ -0,0 +1,191 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResolve(t *testing.T) {
+	tests := []struct {
+		entry    string
+		hostPort string
+		addr     string
+		wantErr  string
+	}{
+		{entry: "example.com:443:127.0.0.1", hostPort: "example.com:443", addr: "127.0.0.1:443"},
+		{entry: "Example.COM:80:::1", hostPort: "example.com:80", addr: "[::1]:80"},
+		{entry: "example.com:8080:[2001:db8::1]", hostPort: "example.com:8080", addr: "[2001:db8::1]:8080"},
+		{entry: "example.com:443", wantErr: "expected host:port:addr"},
+		{entry: ":443:127.0.0.1", wantErr: "expected host:port:addr"},
+		{entry: "example.com:https:127.0.0.1", wantErr: "invalid port"},
+		{entry: "example.com:443:localhost", wantErr: "invalid address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			hostPort, addr, err := parseResolve(tt.entry)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.hostPort, hostPort)
+			assert.Equal(t, tt.addr, addr)
+		})
+	}
+}
+
+func TestNewDialer(t *testing.T) {
+	tests := []struct {
+		name      string
+		network   string
+		localAddr string
+		wantErr   string
+		opts      DialOptions
+	}{
+		{name: "defaults", opts: DialOptions{}, network: "tcp"},
+		{name: "ipv4", opts: DialOptions{IPVersion: IPv4}, network: "tcp4"},
+		{name: "ipv6", opts: DialOptions{IPVersion: IPv6}, network: "tcp6"},
+		{name: "invalid IP version", opts: DialOptions{IPVersion: 5}, wantErr: "unsupported IP version: 5"},
+		{name: "invalid resolve", opts: DialOptions{Resolve: []string{"example.com"}}, wantErr: "invalid resolve entry"},
+		{name: "interface address", opts: DialOptions{Interface: "127.0.0.1"}, network: "tcp4", localAddr: "127.0.0.1:0"},
+		{name: "interface IPv6 address", opts: DialOptions{Interface: "::1"}, network: "tcp6", localAddr: "[::1]:0"},
+		{name: "interface version mismatch", opts: DialOptions{Interface: "127.0.0.1", IPVersion: IPv6}, wantErr: "does not match IPv6"},
+		{name: "unknown interface", opts: DialOptions{Interface: "wsget-missing0"}, wantErr: "failed to find interface"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newDialer(tt.opts, nil)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.network, d.network)
+
+			if tt.localAddr != "" {
+				assert.Equal(t, tt.localAddr, d.localAddr.String())
+			} else {
+				assert.Nil(t, d.localAddr)
+			}
+		})
+	}
+}
+
+func TestInterfaceIP_Loopback(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	require.NoError(t, err)
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 {
+			continue
+		}
+
+		ip, err := interfaceIP(iface.Name, IPv4)
+		require.NoError(t, err)
+		assert.True(t, ip.IsLoopback())
+
+		return
+	}
+
+	t.Skip("no loopback interface found")
+}
+
+func TestConnection_Resolve(t *testing.T) {
+	hosts := make(chan string, 1)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts <- r.Host
+
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusNormalClosure, "")
+	}))
+	defer s.Close()
+
+	_, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	require.NoError(t, err)
+
+	output := &bytes.Buffer{}
+
+	conn, err := New("ws://canary.example.invalid:"+port, &Options{
+		Output: output,
+		Dial: DialOptions{
+			Resolve:   []string{"canary.example.invalid:" + port + ":127.0.0.1"},
+			Interface: "127.0.0.1",
+		},
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	assert.ErrorIs(t, conn.Connect(context.Background()), ErrConnectionClosed)
+	assert.Equal(t, "canary.example.invalid:"+port, <-hosts)
+	assert.Contains(t, output.String(), "* Resolved canary.example.invalid:"+port+" to 127.0.0.1:"+port+"\n")
+}
+
+func TestConnection_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ws.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	s := httptest.NewUnstartedServer(createEchoWSHandler())
+	s.Listener = listener
+	s.Start()
+
+	defer s.Close()
+
+	conn, err := New("ws://sidecar.local/ws", &Options{Dial: DialOptions{UnixSocket: socket}})
+	require.NoError(t, err)
+
+	received := make(chan string, 1)
+
+	conn.SetOnMessage(func(_ context.Context, data []byte, _ bool) {
+		received <- string(data)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = conn.Connect(ctx) }()
+
+	select {
+	case <-conn.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	require.NoError(t, conn.Send(ctx, "hello"))
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "hello", msg)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for response")
+	}
+}
+
+func TestNew_UnixSocketWithProxy(t *testing.T) {
+	_, err := New("ws://localhost", &Options{
+		Proxy: "http://proxy.example.com:3128",
+		Dial:  DialOptions{UnixSocket: "/tmp/ws.sock"},
+	})
+	assert.ErrorContains(t, err, "unix socket cannot be used with proxy")
+}
@@ -0,0 +1,146 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// FaultOptions configures faults injected into the connection to test how servers handle misbehaving clients.
+// Zero values disable the corresponding faults.
+type FaultOptions struct {
+	// SendLatency delays every outgoing message.
+	SendLatency time.Duration
+	// SendJitter adds a random delay of up to the value to every outgoing message.
+	SendJitter time.Duration
+	// ReadStall pauses before reading every incoming message, building up backpressure on the server.
+	ReadStall time.Duration
+	// KillAfter drops the TCP connection without a close frame after the duration since the connection is established.
+	KillAfter time.Duration
+	// DuplicateRate is the probability of sending an outgoing message twice.
+	DuplicateRate float64
+	// DropRate is the probability of silently dropping an outgoing message.
+	DropRate float64
+	// KillAfterMessages drops the TCP connection without a close frame after the number of messages sent and received.
+	KillAfterMessages int
+}
+
+// faultInjector applies the configured faults to a connection.
+// A nil faultInjector injects no faults.
+type faultInjector struct {
+	output   io.Writer
+	opts     FaultOptions
+	messages atomic.Int64
+}
+
+// newFaultInjector validates the fault options and creates the injector.
+// It takes opts of type FaultOptions and output of type io.Writer for reporting injected faults, which may be nil.
+// It returns nil if no faults are configured, and an error if a rate is outside [0, 1] or a value is negative.
+func newFaultInjector(opts FaultOptions, output io.Writer) (*faultInjector, error) {
+	if opts == (FaultOptions{}) {
+		return nil, nil
+	}
+
+	if opts.SendLatency < 0 || opts.SendJitter < 0 || opts.ReadStall < 0 || opts.KillAfter < 0 || opts.KillAfterMessages < 0 {
+		return nil, fmt.Errorf("fault durations and message count should not be negative")
+	}
+
+	if opts.DuplicateRate < 0 || opts.DuplicateRate > 1 || opts.DropRate < 0 || opts.DropRate > 1 {
+		return nil, fmt.Errorf("fault rates should be between 0 and 1")
+	}
+
+	return &faultInjector{opts: opts, output: output}, nil
+}
+
+// reset restarts the message count for a new connection.
+func (f *faultInjector) reset() {
+	if f == nil {
+		return
+	}
+
+	f.messages.Store(0)
+}
+
+// beforeSend delays the outgoing message and decides how many times it is sent.
+// It returns 0 if the message should be dropped, 2 if it should be duplicated and 1 otherwise,
+// and an error if the context is canceled while waiting.
+func (f *faultInjector) beforeSend(ctx context.Context) (int, error) {
+	if f == nil {
+		return 1, nil
+	}
+
+	delay := f.opts.SendLatency
+	if f.opts.SendJitter > 0 {
+		delay += rand.N(f.opts.SendJitter) //nolint:gosec // fault injection does not require secure randomness
+	}
+
+	if delay > 0 {
+		if err := wait(ctx, delay); err != nil {
+			return 0, err
+		}
+	}
+
+	switch {
+	case f.opts.DropRate > 0 && rand.Float64() < f.opts.DropRate: //nolint:gosec // fault injection does not require secure randomness
+		f.report("dropped outgoing message")
+		return 0, nil
+	case f.opts.DuplicateRate > 0 && rand.Float64() < f.opts.DuplicateRate: //nolint:gosec // fault injection does not require secure randomness
+		f.report("duplicated outgoing message")
+		return 2, nil
+	default:
+		return 1, nil
+	}
+}
+
+// beforeRead stalls reading of the next incoming message.
+// It returns an error if the context is canceled while waiting.
+func (f *faultInjector) beforeRead(ctx context.Context) error {
+	if f == nil || f.opts.ReadStall <= 0 {
+		return nil
+	}
+
+	return wait(ctx, f.opts.ReadStall)
+}
+
+// countMessage counts a sent or received message and kills the connection when the message limit is reached.
+func (f *faultInjector) countMessage(ws *websocket.Conn) {
+	if f == nil || f.opts.KillAfterMessages <= 0 {
+		return
+	}
+
+	if f.messages.Add(1) == int64(f.opts.KillAfterMessages) {
+		f.kill(ws, fmt.Sprintf("after %d messages", f.opts.KillAfterMessages))
+	}
+}
+
+// startKillTimer schedules killing of the connection after the configured duration.
+// It returns a function that cancels the timer.
+func (f *faultInjector) startKillTimer(ws *websocket.Conn) func() {
+	if f == nil || f.opts.KillAfter <= 0 {
+		return func() {}
+	}
+
+	timer := time.AfterFunc(f.opts.KillAfter, func() {
+		f.kill(ws, "after "+f.opts.KillAfter.String())
+	})
+
+	return func() { timer.Stop() }
+}
+
+// kill closes the underlying TCP connection without a close handshake.
+func (f *faultInjector) kill(ws *websocket.Conn, reason string) {
+	f.report("killing connection " + reason)
+	_ = ws.CloseNow()
+}
+
+// report prints the injected fault to the verbose output.
+func (f *faultInjector) report(fault string) {
+	if f.output != nil {
+		_, _ = fmt.Fprintf(f.output, "* Fault injection: %s\n", fault)
+	}
+}
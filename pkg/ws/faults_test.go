@@ -0,0 +1,213 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFaultInjector(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    FaultOptions
+		wantNil bool
+		wantErr bool
+	}{
+		{
+			name:    "no faults",
+			opts:    FaultOptions{},
+			wantNil: true,
+		},
+		{
+			name: "valid faults",
+			opts: FaultOptions{SendLatency: time.Millisecond, DropRate: 0.5, KillAfterMessages: 10},
+		},
+		{
+			name:    "negative latency",
+			opts:    FaultOptions{SendLatency: -time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "negative message count",
+			opts:    FaultOptions{KillAfterMessages: -1},
+			wantErr: true,
+		},
+		{
+			name:    "drop rate above one",
+			opts:    FaultOptions{DropRate: 1.5},
+			wantErr: true,
+		},
+		{
+			name:    "negative duplicate rate",
+			opts:    FaultOptions{DuplicateRate: -0.1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newFaultInjector(tt.opts, nil)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantNil, f == nil)
+		})
+	}
+}
+
+func TestFaultInjector_BeforeSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		report   string
+		opts     FaultOptions
+		expected int
+	}{
+		{
+			name:     "no faults",
+			expected: 1,
+		},
+		{
+			name:     "drop",
+			opts:     FaultOptions{DropRate: 1},
+			expected: 0,
+			report:   "* Fault injection: dropped outgoing message\n",
+		},
+		{
+			name:     "duplicate",
+			opts:     FaultOptions{DuplicateRate: 1},
+			expected: 2,
+			report:   "* Fault injection: duplicated outgoing message\n",
+		},
+		{
+			name:     "latency",
+			opts:     FaultOptions{SendLatency: 20 * time.Millisecond, SendJitter: time.Millisecond},
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+
+			f, err := newFaultInjector(tt.opts, output)
+			require.NoError(t, err)
+
+			start := time.Now()
+
+			copies, err := f.beforeSend(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, copies)
+			assert.Equal(t, tt.report, output.String())
+			assert.GreaterOrEqual(t, time.Since(start), tt.opts.SendLatency)
+		})
+	}
+}
+
+func TestFaultInjector_BeforeSend_ContextCanceled(t *testing.T) {
+	f, err := newFaultInjector(FaultOptions{SendLatency: time.Minute}, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = f.beforeSend(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, f.beforeRead(ctx))
+
+	f.opts.ReadStall = time.Minute
+	assert.ErrorIs(t, f.beforeRead(ctx), context.Canceled)
+}
+
+func TestConnection_Faults_KillAfterMessages(t *testing.T) {
+	serverErr := make(chan error, 1)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		for {
+			if _, _, err := c.Read(r.Context()); err != nil {
+				serverErr <- err
+				return
+			}
+		}
+	}))
+	defer s.Close()
+
+	output := &bytes.Buffer{}
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{
+		Output: output,
+		Faults: FaultOptions{KillAfterMessages: 2, DuplicateRate: 1},
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(context.Background()) }()
+
+	select {
+	case <-conn.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	require.NoError(t, conn.Send(context.Background(), "hello"))
+
+	select {
+	case err := <-serverErr:
+		assert.Equal(t, websocket.StatusCode(-1), websocket.CloseStatus(err), "connection should be dropped without a close frame")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for connection drop")
+	}
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for connect to return")
+	}
+
+	assert.Contains(t, output.String(), "* Fault injection: killing connection after 2 messages")
+}
+
+func TestConnection_Faults_KillAfter(t *testing.T) {
+	s := httptest.NewServer(createEchoWSHandler())
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{
+		Faults: FaultOptions{KillAfter: 50 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(context.Background()) }()
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+		assert.Equal(t, CloseStatus{}, conn.CloseStatus())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for connection drop")
+	}
+}
@@ -0,0 +1,92 @@
+package ws
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+const (
+	latencyWindowSize = 1000
+	percentile95      = 0.95
+)
+
+// latencyTracker collects round-trip times of ping frames.
+// Minimum, maximum and average are calculated over all samples,
+// while the 95th percentile is calculated over the most recent samples.
+type latencyTracker struct {
+	window   []time.Duration
+	sum      time.Duration
+	min      time.Duration
+	max      time.Duration
+	last     time.Duration
+	next     int
+	count    int
+	timeouts int
+	l        sync.Mutex
+}
+
+// record adds a successful ping round-trip time to the tracker.
+func (t *latencyTracker) record(rtt time.Duration) {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	if t.count == 0 || rtt < t.min {
+		t.min = rtt
+	}
+
+	if rtt > t.max {
+		t.max = rtt
+	}
+
+	t.count++
+	t.sum += rtt
+	t.last = rtt
+
+	if len(t.window) < latencyWindowSize {
+		t.window = append(t.window, rtt)
+		return
+	}
+
+	t.window[t.next] = rtt
+	t.next = (t.next + 1) % latencyWindowSize
+}
+
+// timeout registers a ping that was not answered in time.
+func (t *latencyTracker) timeout() {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	t.timeouts++
+}
+
+// stats returns a snapshot of the collected latency statistics.
+func (t *latencyTracker) stats() core.PingStats {
+	t.l.Lock()
+	defer t.l.Unlock()
+
+	stats := core.PingStats{
+		Count:    t.count,
+		Timeouts: t.timeouts,
+		Min:      t.min,
+		Max:      t.max,
+		Last:     t.last,
+	}
+
+	if t.count == 0 {
+		return stats
+	}
+
+	stats.Avg = t.sum / time.Duration(t.count)
+
+	sorted := slices.Clone(t.window)
+	slices.Sort(sorted)
+
+	idx := int(math.Ceil(float64(len(sorted))*percentile95)) - 1
+	stats.P95 = sorted[max(idx, 0)]
+
+	return stats
+}
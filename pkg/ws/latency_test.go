@@ -0,0 +1,51 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyTracker_Stats(t *testing.T) {
+	tracker := &latencyTracker{}
+
+	assert.Equal(t, core.PingStats{}, tracker.stats())
+
+	for i := 1; i <= 20; i++ {
+		tracker.record(time.Duration(i) * time.Millisecond)
+	}
+
+	tracker.record(5 * time.Millisecond)
+	tracker.timeout()
+
+	stats := tracker.stats()
+
+	assert.Equal(t, 21, stats.Count)
+	assert.Equal(t, 1, stats.Timeouts)
+	assert.Equal(t, time.Millisecond, stats.Min)
+	assert.Equal(t, 20*time.Millisecond, stats.Max)
+	assert.Equal(t, 5*time.Millisecond, stats.Last)
+	assert.Equal(t, 215*time.Millisecond/21, stats.Avg)
+	assert.Equal(t, 19*time.Millisecond, stats.P95)
+}
+
+func TestLatencyTracker_Window(t *testing.T) {
+	tracker := &latencyTracker{}
+
+	for range latencyWindowSize {
+		tracker.record(time.Second)
+	}
+
+	for range latencyWindowSize {
+		tracker.record(time.Millisecond)
+	}
+
+	stats := tracker.stats()
+
+	assert.Len(t, tracker.window, latencyWindowSize)
+	assert.Equal(t, 2*latencyWindowSize, stats.Count)
+	assert.Equal(t, time.Second, stats.Max)
+	assert.Equal(t, time.Millisecond, stats.P95)
+}
@@ -0,0 +1,187 @@
+package ws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+const (
+	rateWindowShort = 10
+	rateWindowLong  = 60
+)
+
+// rateBucket accumulates messages and bytes transferred within one second.
+type rateBucket struct {
+	second   int64
+	messages uint64
+	bytes    uint64
+}
+
+// directionCollector accumulates message statistics for one direction of the connection.
+type directionCollector struct {
+	last     time.Time
+	sizes    []uint64
+	buckets  [rateWindowLong]rateBucket
+	messages uint64
+	bytes    uint64
+	min      uint64
+	max      uint64
+	gaps     time.Duration
+}
+
+// metricsCollector counts messages and bytes per direction of the connection,
+// tracks the message size distribution and message rates over sliding windows.
+type metricsCollector struct {
+	start    time.Time
+	now      func() time.Time
+	sent     directionCollector
+	received directionCollector
+	l        sync.Mutex
+}
+
+// started marks the time the connection was established, used to calculate uptime and early rates.
+// Only the first call has effect, so that reconnections do not reset the metrics.
+func (m *metricsCollector) started() {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if m.start.IsZero() {
+		m.start = m.time()
+	}
+}
+
+// recordSent registers a message of size bytes sent to the server.
+func (m *metricsCollector) recordSent(size int) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.sent.record(uint64(size), m.time()) //nolint:gosec // size is never negative
+}
+
+// recordReceived registers a message of size bytes received from the server.
+func (m *metricsCollector) recordReceived(size int64) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.received.record(uint64(size), m.time()) //nolint:gosec // size is never negative
+}
+
+// metrics returns a snapshot of the collected statistics.
+func (m *metricsCollector) metrics() core.Metrics {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	now := m.time()
+
+	var uptime time.Duration
+	if !m.start.IsZero() {
+		uptime = now.Sub(m.start)
+	}
+
+	return core.Metrics{
+		Sent:     m.sent.snapshot(now, uptime),
+		Received: m.received.snapshot(now, uptime),
+		Uptime:   uptime,
+	}
+}
+
+// time returns the current time using the configured clock.
+func (m *metricsCollector) time() time.Time {
+	if m.now != nil {
+		return m.now()
+	}
+
+	return time.Now()
+}
+
+// record adds a message of the given size received or sent at the provided time.
+func (d *directionCollector) record(size uint64, at time.Time) {
+	if d.messages == 0 || size < d.min {
+		d.min = size
+	}
+
+	if size > d.max {
+		d.max = size
+	}
+
+	if !d.last.IsZero() {
+		d.gaps += at.Sub(d.last)
+	}
+
+	d.messages++
+	d.bytes += size
+	d.last = at
+
+	if d.sizes == nil {
+		d.sizes = make([]uint64, len(core.MessageSizeBounds)+1)
+	}
+
+	d.sizes[sizeBucket(size)]++
+
+	second := at.Unix()
+	bucket := &d.buckets[second%rateWindowLong]
+
+	if bucket.second != second {
+		*bucket = rateBucket{second: second}
+	}
+
+	bucket.messages++
+	bucket.bytes += size
+}
+
+// snapshot returns the statistics of the direction at the provided time.
+// It takes now of type time.Time and uptime, the time since the connection was established,
+// which limits the rate windows while the connection is younger than the window.
+func (d *directionCollector) snapshot(now time.Time, uptime time.Duration) core.DirectionMetrics {
+	m := core.DirectionMetrics{
+		Messages:     d.messages,
+		Bytes:        d.bytes,
+		MinSize:      d.min,
+		MaxSize:      d.max,
+		LastActivity: d.last,
+		SizeBuckets:  make([]uint64, len(core.MessageSizeBounds)+1),
+	}
+
+	copy(m.SizeBuckets, d.sizes)
+
+	if d.messages > 1 {
+		m.AvgInterval = d.gaps / time.Duration(d.messages-1)
+	}
+
+	m.Rate10s, m.ByteRate10s = d.rate(now, rateWindowShort, uptime)
+	m.Rate60s, m.ByteRate60s = d.rate(now, rateWindowLong, uptime)
+
+	return m
+}
+
+// rate calculates messages and bytes per second over the last window seconds.
+func (d *directionCollector) rate(now time.Time, window int64, uptime time.Duration) (messages, bytes float64) {
+	current := now.Unix()
+
+	for _, bucket := range d.buckets {
+		if bucket.second > current-window && bucket.second <= current {
+			messages += float64(bucket.messages)
+			bytes += float64(bucket.bytes)
+		}
+	}
+
+	seconds := float64(window)
+	if elapsed := uptime.Seconds(); elapsed > 0 && elapsed < seconds {
+		seconds = max(elapsed, 1)
+	}
+
+	return messages / seconds, bytes / seconds
+}
+
+// sizeBucket returns the index of the size distribution bucket for a message of the given size.
+func sizeBucket(size uint64) int {
+	for i, bound := range core.MessageSizeBounds {
+		if size <= uint64(bound) { //nolint:gosec // bounds are positive
+			return i
+		}
+	}
+
+	return len(core.MessageSizeBounds)
+}
@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsCollector(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	m := &metricsCollector{now: func() time.Time { return now }}
+
+	m.started()
+
+	for i := range 20 {
+		now = now.Add(500 * time.Millisecond)
+
+		m.recordReceived(int64(100 + i))
+	}
+
+	m.recordSent(10)
+	m.recordSent(5000)
+
+	metrics := m.metrics()
+
+	assert.Equal(t, 10*time.Second, metrics.Uptime)
+
+	assert.Equal(t, uint64(20), metrics.Received.Messages)
+	assert.Equal(t, uint64(2190), metrics.Received.Bytes)
+	assert.Equal(t, uint64(100), metrics.Received.MinSize)
+	assert.Equal(t, uint64(119), metrics.Received.MaxSize)
+	assert.Equal(t, uint64(109), metrics.Received.AvgSize())
+	assert.Equal(t, 500*time.Millisecond, metrics.Received.AvgInterval)
+	assert.Equal(t, now, metrics.Received.LastActivity)
+	assert.Equal(t, []uint64{0, 20, 0, 0, 0, 0, 0, 0}, metrics.Received.SizeBuckets)
+
+	// 19 messages fall into the last 10 seconds, the first one into the second before the window
+	assert.InDelta(t, 1.9, metrics.Received.Rate10s, 0.001)
+	assert.InDelta(t, 2.0, metrics.Received.Rate60s, 0.001)
+
+	assert.Equal(t, uint64(2), metrics.Sent.Messages)
+	assert.Equal(t, []uint64{1, 0, 0, 0, 1, 0, 0, 0}, metrics.Sent.SizeBuckets)
+	assert.Zero(t, metrics.Sent.AvgInterval)
+
+	now = now.Add(2 * time.Minute)
+	metrics = m.metrics()
+
+	assert.Zero(t, metrics.Received.Rate10s)
+	assert.Zero(t, metrics.Received.Rate60s)
+	assert.Equal(t, uint64(20), metrics.Received.Messages)
+}
+
+func TestMetricsCollector_Started(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	m := &metricsCollector{now: func() time.Time { return now }}
+
+	assert.Zero(t, m.metrics().Uptime)
+
+	m.started()
+
+	now = now.Add(time.Minute)
+	m.started()
+
+	assert.Equal(t, time.Minute, m.metrics().Uptime)
+}
+
+func TestSizeBucket(t *testing.T) {
+	assert.Equal(t, 0, sizeBucket(0))
+	assert.Equal(t, 0, sizeBucket(64))
+	assert.Equal(t, 1, sizeBucket(65))
+	assert.Equal(t, 6, sizeBucket(262144))
+	assert.Equal(t, 7, sizeBucket(262145))
+}
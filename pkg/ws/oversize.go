@@ -0,0 +1,161 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	OversizeClose    = "close"
+	OversizeTruncate = "truncate"
+	OversizeStream   = "stream"
+
+	oversizePreviewSize = 256
+	oversizeDirMode     = 0o755
+	oversizeFileMode    = 0o600
+)
+
+// oversizeOptions contains the policy for messages that exceed the maximum message size.
+type oversizeOptions struct {
+	policy string
+	dir    string
+	saved  atomic.Uint64
+}
+
+// parseOversizePolicy validates the oversize policy name.
+// It takes policy of type string, an empty policy is treated as close.
+// It returns the policy name and an error if the policy is not supported.
+func parseOversizePolicy(policy string) (string, error) {
+	switch policy {
+	case "", OversizeClose:
+		return OversizeClose, nil
+	case OversizeTruncate, OversizeStream:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid oversize policy: %s", policy)
+	}
+}
+
+// enabled reports whether oversized messages are handled by the connection instead of closing it.
+// For the close policy the limit is enforced by the WebSocket library, which fails the read
+// and closes the connection with the message too big status.
+func (o *oversizeOptions) enabled() bool {
+	return o.policy == OversizeTruncate || o.policy == OversizeStream
+}
+
+// readLimit returns the read limit to set on the WebSocket connection, -1 disables the library limit.
+func (o *oversizeOptions) readLimit(msgSize int64) int64 {
+	if o.enabled() {
+		return -1
+	}
+
+	return msgSize
+}
+
+// handleOversize processes a message that exceeds the maximum message size without buffering it completely.
+// It takes ctx of type context.Context, head, the already read beginning of the message,
+// rest, the reader with the remaining part of the message, and isBinary flag.
+// It returns an error if reading the message or saving it to a file fails.
+// The onMessage callback receives a text preview of the message with its size and the file it was saved to.
+func (c *Connection) handleOversize(ctx context.Context, head []byte, rest io.Reader, isBinary bool) error {
+	var (
+		path  string
+		total int64
+		err   error
+	)
+
+	if c.oversize.policy == OversizeStream {
+		path, total, err = c.oversize.save(head, rest, isBinary)
+	} else {
+		var n int64
+
+		n, err = io.Copy(io.Discard, rest)
+		total = int64(len(head)) + n
+	}
+
+	if err != nil {
+		return fmt.Errorf("fail to read oversized message: %w", err)
+	}
+
+	c.counter.payloadReceived.Add(uint64(total)) //nolint:gosec // total is never negative
+	c.metrics.recordReceived(total)
+	c.onMessage(ctx, []byte(oversizePreview(head, total, path, isBinary)), false)
+
+	return nil
+}
+
+// save streams the message to a new file in the oversize directory.
+// It takes head, the already read beginning of the message, rest, the remaining part, and isBinary flag.
+// It returns the path of the file, the total size of the message and an error if the file cannot be written.
+func (o *oversizeOptions) save(head []byte, rest io.Reader, isBinary bool) (path string, total int64, err error) {
+	if err := os.MkdirAll(o.dir, oversizeDirMode); err != nil {
+		return "", 0, fmt.Errorf("failed to create directory for oversized messages: %w", err)
+	}
+
+	ext := ".txt"
+	if isBinary {
+		ext = ".bin"
+	}
+
+	name := fmt.Sprintf("message-%s-%d%s", time.Now().Format("20060102-150405"), o.saved.Add(1), ext)
+	path = filepath.Join(o.dir, name)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, oversizeFileMode)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create file for oversized message: %w", err)
+	}
+
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to close file for oversized message: %w", closeErr)
+		}
+	}()
+
+	w := bufio.NewWriter(file)
+
+	total, err = io.Copy(w, io.MultiReader(bytes.NewReader(head), rest))
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", 0, fmt.Errorf("failed to write oversized message: %w", err)
+	}
+
+	return path, total, nil
+}
+
+// oversizePreview builds the text shown instead of an oversized message.
+// It takes head, the beginning of the message, total, the message size in bytes,
+// path, the file the message was saved to or empty, and isBinary flag.
+// Text previews are cut at a valid UTF-8 boundary, binary previews are base64 encoded.
+func oversizePreview(head []byte, total int64, path string, isBinary bool) string {
+	preview := head[:min(len(head), oversizePreviewSize)]
+
+	var text string
+
+	if isBinary {
+		text = base64.StdEncoding.EncodeToString(preview)
+	} else {
+		for len(preview) > 0 && !utf8.Valid(preview) {
+			preview = preview[:len(preview)-1]
+		}
+
+		text = string(preview)
+	}
+
+	if path == "" {
+		return fmt.Sprintf("%s... [truncated, %d bytes total]", text, total)
+	}
+
+	return fmt.Sprintf("%s... [truncated, %d bytes total, saved to %s]", text, total, path)
+}
@@ -0,0 +1,197 @@
+package ws
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOversizePolicy(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected string
+		wantErr  bool
+	}{
+		{policy: "", expected: OversizeClose},
+		{policy: OversizeClose, expected: OversizeClose},
+		{policy: OversizeTruncate, expected: OversizeTruncate},
+		{policy: OversizeStream, expected: OversizeStream},
+		{policy: "ignore", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			policy, err := parseOversizePolicy(tt.policy)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "invalid oversize policy")
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestOversizePreview(t *testing.T) {
+	long := strings.Repeat("a", oversizePreviewSize-1) + "é"
+
+	tests := []struct {
+		name     string
+		expected string
+		path     string
+		head     []byte
+		total    int64
+		isBinary bool
+	}{
+		{
+			name:     "short text",
+			head:     []byte("hello"),
+			total:    2048,
+			expected: "hello... [truncated, 2048 bytes total]",
+		},
+		{
+			name:     "text cut at rune boundary",
+			head:     []byte(long),
+			total:    4096,
+			expected: strings.Repeat("a", oversizePreviewSize-1) + "... [truncated, 4096 bytes total]",
+		},
+		{
+			name:     "binary saved to file",
+			head:     []byte{0x01, 0x02, 0x03},
+			total:    3000,
+			path:     "/tmp/message.bin",
+			isBinary: true,
+			expected: base64.StdEncoding.EncodeToString([]byte{0x01, 0x02, 0x03}) + "... [truncated, 3000 bytes total, saved to /tmp/message.bin]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, oversizePreview(tt.head, tt.total, tt.path, tt.isBinary))
+		})
+	}
+}
+
+func createOversizeServer(big string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		_ = c.Write(r.Context(), websocket.MessageText, []byte(big))
+		_ = c.Write(r.Context(), websocket.MessageText, []byte("ok"))
+
+		_, _, _ = c.Read(r.Context())
+	}))
+}
+
+func TestConnection_Oversize(t *testing.T) {
+	big := strings.Repeat("x", 10*1024)
+
+	tests := []struct {
+		name          string
+		policy        string
+		expectPreview string
+		saved         bool
+	}{
+		{
+			name:          "truncate",
+			policy:        OversizeTruncate,
+			expectPreview: strings.Repeat("x", oversizePreviewSize) + "... [truncated, 10240 bytes total]",
+		},
+		{
+			name:          "stream",
+			policy:        OversizeStream,
+			expectPreview: strings.Repeat("x", oversizePreviewSize) + "... [truncated, 10240 bytes total, saved to ",
+			saved:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := createOversizeServer(big)
+			defer s.Close()
+
+			dir := filepath.Join(t.TempDir(), "messages")
+
+			conn, err := New("ws://"+s.Listener.Addr().String(), &Options{
+				MaxMessageSize: 1024,
+				Oversize:       tt.policy,
+				OversizeDir:    dir,
+			})
+			require.NoError(t, err)
+
+			received := make(chan string, 2)
+
+			conn.SetOnMessage(func(_ context.Context, data []byte, isBinary bool) {
+				assert.False(t, isBinary)
+				received <- string(data)
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			go func() { _ = conn.Connect(ctx) }()
+
+			var msgs []string
+
+			for range 2 {
+				select {
+				case msg := <-received:
+					msgs = append(msgs, msg)
+				case <-time.After(time.Second):
+					t.Fatal("timeout waiting for messages")
+				}
+			}
+
+			assert.True(t, strings.HasPrefix(msgs[0], tt.expectPreview), msgs[0])
+			assert.Equal(t, "ok", msgs[1])
+			assert.Equal(t, uint64(len(big)+2), conn.Stats().PayloadReceived)
+
+			files, _ := os.ReadDir(dir)
+			if !tt.saved {
+				assert.Empty(t, files)
+				return
+			}
+
+			require.Len(t, files, 1)
+
+			path := filepath.Join(dir, files[0].Name())
+			assert.True(t, strings.HasSuffix(msgs[0], ", saved to "+path+"]"))
+			assert.True(t, strings.HasSuffix(path, ".txt"))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, big, string(data))
+		})
+	}
+}
+
+func TestConnection_OversizeClose(t *testing.T) {
+	s := createOversizeServer(strings.Repeat("x", 10*1024))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{MaxMessageSize: 1024})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {
+		t.Error("oversized message must not be delivered")
+	})
+
+	err = conn.Connect(context.Background())
+	assert.ErrorContains(t, err, "read limited at 1025 bytes")
+}
@@ -0,0 +1,110 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coder/websocket"
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+// Protocol returns the application protocol running on top of the connection, or nil if it exchanges raw messages.
+func (c *Connection) Protocol() core.Protocol {
+	return c.protocol
+}
+
+// openProtocol sends the handshake frames of the application protocol after the connection is established.
+// It returns an error if the frames cannot be built or sent.
+func (c *Connection) openProtocol(ctx context.Context, ws *websocket.Conn) error {
+	if c.protocol == nil {
+		return nil
+	}
+
+	frames, err := c.protocol.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s session: %w", c.protocol.Name(), err)
+	}
+
+	return c.sendFrames(ctx, ws, frames)
+}
+
+// runHeartbeat sends the heartbeats of the application protocol until the context is canceled
+// or the protocol stops them by returning a non-positive interval.
+// It returns an error if a heartbeat cannot be built or sent.
+func (c *Connection) runHeartbeat(ctx context.Context, ws *websocket.Conn) error {
+	hb, ok := c.protocol.(core.Heartbeater)
+	if !ok {
+		return nil
+	}
+
+	for {
+		interval := hb.HeartbeatInterval()
+		if interval <= 0 {
+			return nil
+		}
+
+		if wait(ctx, interval) != nil {
+			return nil
+		}
+
+		frames, err := hb.Heartbeat()
+		if err != nil {
+			return fmt.Errorf("failed to build %s heartbeat: %w", c.protocol.Name(), err)
+		}
+
+		if err := c.sendFrames(ctx, ws, frames); err != nil && ctx.Err() == nil {
+			return err
+		}
+	}
+}
+
+// deliver passes the incoming message to the onMessage callback.
+// If an application protocol is configured, the message is decoded first: replies are sent back to the server
+// and only the decoded form of the message is passed on, control frames are not passed at all.
+// Messages the protocol fails to decode are passed unchanged.
+// It returns an error if sending a reply fails.
+func (c *Connection) deliver(ctx context.Context, ws *websocket.Conn, data []byte, isBinary bool) error {
+	if c.protocol == nil {
+		c.onMessage(ctx, data, isBinary)
+		return nil
+	}
+
+	msg, err := c.protocol.Decode(data, isBinary)
+	if err != nil {
+		if c.output != nil {
+			fmt.Fprintf(c.output, "* %s: %s\n", c.protocol.Name(), err)
+		}
+
+		c.onMessage(ctx, data, isBinary)
+
+		return nil
+	}
+
+	if err := c.sendFrames(ctx, ws, msg.Replies); err != nil {
+		return err
+	}
+
+	if msg.Display != "" {
+		c.onMessage(ctx, []byte(msg.Display), false)
+	}
+
+	return nil
+}
+
+// sendFrames writes the protocol frames to ws, they are not recorded for replay
+// because the protocol sends them again on reconnection when needed.
+// It returns an error if writing a frame fails.
+func (c *Connection) sendFrames(ctx context.Context, ws *websocket.Conn, frames []core.ProtocolFrame) error {
+	for _, frame := range frames {
+		msgType := websocket.MessageText
+		if frame.Binary {
+			msgType = websocket.MessageBinary
+		}
+
+		if _, err := c.transmit(ctx, ws, msgType, frame.Data); err != nil {
+			return fmt.Errorf("failed to send %s frame: %w", c.protocol.Name(), err)
+		}
+	}
+
+	return nil
+}
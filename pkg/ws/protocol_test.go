@@ -0,0 +1,177 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/ksysoev/wsget/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnection_Protocol(t *testing.T) {
+	serverReceived := make(chan string, 3)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverReceived <- r.URL.RawQuery
+
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		for _, msg := range []string{"ping", "event", "garbage"} {
+			if msg == "event" {
+				_, data, err := c.Read(r.Context())
+				if err != nil {
+					return
+				}
+
+				serverReceived <- string(data)
+			}
+
+			if err := c.Write(r.Context(), websocket.MessageText, []byte(msg)); err != nil {
+				return
+			}
+		}
+
+		_, data, err := c.Read(r.Context())
+		if err != nil {
+			return
+		}
+
+		serverReceived <- string(data)
+
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer s.Close()
+
+	proto := core.NewMockProtocol(t)
+	proto.EXPECT().PrepareURL(mock.Anything).Run(func(u *url.URL) { u.RawQuery = "proto=1" })
+	proto.EXPECT().Subprotocols().Return(nil)
+	proto.EXPECT().Name().Return("test").Maybe()
+	proto.EXPECT().Open().Return([]core.ProtocolFrame{{Data: []byte("hello")}}, nil)
+	proto.EXPECT().Decode([]byte("ping"), false).Return(core.ProtocolMessage{Replies: []core.ProtocolFrame{{Data: []byte("pong")}}}, nil)
+	proto.EXPECT().Decode([]byte("event"), false).Return(core.ProtocolMessage{Display: `{"event":"decoded"}`}, nil)
+	proto.EXPECT().Decode([]byte("garbage"), false).Return(core.ProtocolMessage{}, errors.New("invalid frame"))
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{Protocol: proto})
+	require.NoError(t, err)
+
+	assert.Equal(t, proto, conn.Protocol())
+
+	received := make(chan string, 2)
+
+	conn.SetOnMessage(func(_ context.Context, data []byte, _ bool) {
+		received <- string(data)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() { _ = conn.Connect(ctx) }()
+
+	for _, expected := range []string{"proto=1", "hello", "pong"} {
+		select {
+		case msg := <-serverReceived:
+			assert.Equal(t, expected, msg)
+		case <-ctx.Done():
+			t.Fatalf("server did not receive %q", expected)
+		}
+	}
+
+	for _, expected := range []string{`{"event":"decoded"}`, "garbage"} {
+		select {
+		case msg := <-received:
+			assert.Equal(t, expected, msg)
+		case <-ctx.Done():
+			t.Fatalf("client did not receive %q", expected)
+		}
+	}
+
+	assert.NoError(t, conn.Close())
+}
+
+type heartbeatProtocol struct {
+	*core.MockProtocol
+	beats int
+}
+
+func (p *heartbeatProtocol) HeartbeatInterval() time.Duration {
+	if p.beats >= 2 {
+		return 0
+	}
+
+	return 10 * time.Millisecond
+}
+
+func (p *heartbeatProtocol) Heartbeat() ([]core.ProtocolFrame, error) {
+	p.beats++
+
+	return []core.ProtocolFrame{{Data: []byte("beat")}}, nil
+}
+
+func TestConnection_ProtocolHeartbeat(t *testing.T) {
+	serverReceived := make(chan string, 3)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		for {
+			_, data, err := c.Read(r.Context())
+			if err != nil {
+				return
+			}
+
+			serverReceived <- string(data)
+		}
+	}))
+	defer s.Close()
+
+	mockProto := core.NewMockProtocol(t)
+	mockProto.EXPECT().PrepareURL(mock.Anything)
+	mockProto.EXPECT().Subprotocols().Return(nil)
+	mockProto.EXPECT().Name().Return("test").Maybe()
+	mockProto.EXPECT().Open().Return(nil, nil)
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{Protocol: &heartbeatProtocol{MockProtocol: mockProto}})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() { _ = conn.Connect(ctx) }()
+
+	for range 2 {
+		select {
+		case msg := <-serverReceived:
+			assert.Equal(t, "beat", msg)
+		case <-ctx.Done():
+			t.Fatal("server did not receive heartbeat")
+		}
+	}
+
+	select {
+	case msg := <-serverReceived:
+		t.Fatalf("unexpected message after heartbeats stopped: %q", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.NoError(t, conn.Close())
+}
@@ -0,0 +1,206 @@
+package ws
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	proxySchemeHTTP    = "http"
+	proxySchemeHTTPS   = "https"
+	proxySchemeSOCKS5  = "socks5"
+	proxySchemeSOCKS5H = "socks5h"
+)
+
+// resolveProxy determines the proxy server to use for the connection to the target URL.
+// It takes proxy of type string, an explicitly configured proxy URL, and target of type *url.URL.
+// If proxy is empty, the proxy is taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+// It returns the proxy URL, or nil if the connection should be established directly.
+// It returns an error if the proxy URL is invalid or uses an unsupported scheme.
+func resolveProxy(proxy string, target *url.URL) (*url.URL, error) {
+	if proxy == "" {
+		httpURL := *target
+
+		switch target.Scheme {
+		case "ws":
+			httpURL.Scheme = proxySchemeHTTP
+		case "wss":
+			httpURL.Scheme = proxySchemeHTTPS
+		}
+
+		proxyURL, err := http.ProxyFromEnvironment(&http.Request{URL: &httpURL})
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy in environment: %w", err)
+		}
+
+		return proxyURL, nil
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", proxy, err)
+	}
+
+	switch proxyURL.Scheme {
+	case proxySchemeHTTP, proxySchemeHTTPS, proxySchemeSOCKS5, proxySchemeSOCKS5H:
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %q", proxyURL.Scheme)
+	}
+
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: host is empty", proxy)
+	}
+
+	return proxyURL, nil
+}
+
+// isSOCKSProxy reports whether the proxy URL points to a SOCKS5 proxy server.
+func isSOCKSProxy(proxyURL *url.URL) bool {
+	return proxyURL.Scheme == proxySchemeSOCKS5 || proxyURL.Scheme == proxySchemeSOCKS5H
+}
+
+// proxyDialer establishes TCP tunnels through an HTTP proxy server with the CONNECT method.
+// It is used for both ws:// and wss:// targets, so that plain WebSocket connections are tunneled
+// instead of being forwarded by the proxy as regular HTTP requests.
+type proxyDialer struct {
+	proxy     *url.URL
+	output    io.Writer
+	tlsConfig *tls.Config
+	dial      func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// newProxyDialer creates a new proxyDialer for the given HTTP or HTTPS proxy.
+// It takes proxyURL of type *url.URL, dial, a function used to open connections to the proxy,
+// output of type io.Writer for verbose logging, and skipSSLVerification to control certificate verification of HTTPS proxies.
+// It returns a pointer to a proxyDialer.
+func newProxyDialer(
+	proxyURL *url.URL,
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+	output io.Writer,
+	skipSSLVerification bool,
+) *proxyDialer {
+	return &proxyDialer{
+		proxy:  proxyURL,
+		dial:   dial,
+		output: output,
+		tlsConfig: &tls.Config{
+			ServerName:         proxyURL.Hostname(),
+			InsecureSkipVerify: skipSSLVerification, //nolint:gosec // Skip SSL verification
+		},
+	}
+}
+
+// DialContext connects to the proxy server and requests a tunnel to addr.
+// It takes ctx of type context.Context, network and addr of type string.
+// It returns a net.Conn connected to addr through the proxy.
+// It returns an error if the proxy is unreachable, the TLS handshake with an HTTPS proxy fails,
+// or the proxy rejects the CONNECT request.
+func (d *proxyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.dial(ctx, network, proxyAddr(d.proxy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %w", d.proxy.Redacted(), err)
+	}
+
+	if d.proxy.Scheme == proxySchemeHTTPS {
+		tlsConn := tls.Client(conn, d.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed TLS handshake with proxy %s: %w", d.proxy.Redacted(), err)
+		}
+
+		conn = tlsConn
+	}
+
+	tunnel, err := d.connect(ctx, conn, addr)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return tunnel, nil
+}
+
+// connect sends the CONNECT request for addr over the established proxy connection and validates the response.
+// It returns a net.Conn ready to carry the tunneled traffic, or an error if the proxy rejects the request.
+func (d *proxyDialer) connect(ctx context.Context, conn net.Conn, addr string) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+
+	if user := d.proxy.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if d.output != nil {
+		_, _ = fmt.Fprintf(d.output, "* CONNECT %s via proxy %s\n", addr, d.proxy.Redacted())
+	}
+
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("failed to send CONNECT request to proxy: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CONNECT response from proxy: %w", err)
+	}
+
+	_ = resp.Body.Close()
+
+	if d.output != nil {
+		_, _ = fmt.Fprintf(d.output, "* Proxy responded: %s %s\n", resp.Proto, resp.Status)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy rejected CONNECT request: %s", resp.Status)
+	}
+
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: br}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn that first returns the data already buffered while reading the proxy response.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read reads data from the buffer and then from the underlying connection.
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// proxyAddr returns the host and port of the proxy server, using the default port for the scheme if none is specified.
+func proxyAddr(proxyURL *url.URL) string {
+	if port := proxyURL.Port(); port != "" {
+		return net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+
+	if proxyURL.Scheme == proxySchemeHTTPS {
+		return net.JoinHostPort(proxyURL.Hostname(), "443")
+	}
+
+	return net.JoinHostPort(proxyURL.Hostname(), "80")
+}
@@ -0,0 +1,118 @@
+package ws
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	DefaultReconnectDelay    = time.Second
+	DefaultReconnectMaxDelay = 30 * time.Second
+	DefaultReconnectFactor   = 2.0
+	DefaultReconnectJitter   = 0.2
+)
+
+// State represents the lifecycle state of a WebSocket connection.
+type State uint8
+
+const (
+	StateDisconnected State = iota
+	StateConnected
+	StateReconnecting
+)
+
+// String returns a human-readable name of the connection state.
+func (s State) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	default:
+		return "unknown"
+	}
+}
+
+// ReconnectPolicy describes how a Connection restores a dropped WebSocket session.
+// Delays grow exponentially from InitialDelay by Multiplier up to MaxDelay, and each delay
+// is randomly adjusted by up to Jitter (a fraction of the delay) to avoid reconnect storms.
+// MaxAttempts limits the number of consecutive failed attempts, non-positive value means no limit.
+// If Replay is set, the requests sent since the last connect are re-sent after reconnection.
+type ReconnectPolicy struct {
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	Jitter       float64
+	MaxAttempts  int
+	Replay       bool
+}
+
+// NewReconnectPolicy creates a ReconnectPolicy with default backoff settings.
+// It takes maxAttempts of type int, limiting consecutive reconnect attempts, non-positive value means no limit.
+// It returns a pointer to a ReconnectPolicy.
+func NewReconnectPolicy(maxAttempts int) *ReconnectPolicy {
+	return &ReconnectPolicy{
+		InitialDelay: DefaultReconnectDelay,
+		MaxDelay:     DefaultReconnectMaxDelay,
+		Multiplier:   DefaultReconnectFactor,
+		Jitter:       DefaultReconnectJitter,
+		MaxAttempts:  maxAttempts,
+	}
+}
+
+// delay calculates the backoff delay before the given reconnect attempt.
+// It takes attempt of type int, starting from 1 for the first reconnect attempt.
+// It returns a time.Duration, which is never negative and never exceeds MaxDelay when MaxDelay is set.
+func (p *ReconnectPolicy) delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	d := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1) //nolint:gosec // jitter does not require secure randomness
+	}
+
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+
+	if d < 0 {
+		return 0
+	}
+
+	return time.Duration(d)
+}
+
+// exhausted reports whether the given number of attempts exceeds the configured limit.
+func (p *ReconnectPolicy) exhausted(attempt int) bool {
+	return p.MaxAttempts > 0 && attempt > p.MaxAttempts
+}
+
+// wait blocks for the given duration or until the context is canceled.
+// It returns the context error if the context is canceled before the duration elapses.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,216 @@
+package ws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState_String(t *testing.T) {
+	tests := []struct {
+		expected string
+		state    State
+	}{
+		{state: StateDisconnected, expected: "disconnected"},
+		{state: StateConnected, expected: "connected"},
+		{state: StateReconnecting, expected: "reconnecting"},
+		{state: State(100), expected: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.state.String())
+		})
+	}
+}
+
+func TestReconnectPolicy_Delay(t *testing.T) {
+	policy := &ReconnectPolicy{
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     time.Second,
+		Multiplier:   2,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, policy.delay(0))
+	assert.Equal(t, 100*time.Millisecond, policy.delay(1))
+	assert.Equal(t, 200*time.Millisecond, policy.delay(2))
+	assert.Equal(t, 400*time.Millisecond, policy.delay(3))
+	assert.Equal(t, time.Second, policy.delay(10))
+
+	policy.Jitter = 0.5
+
+	for attempt := 1; attempt < 10; attempt++ {
+		d := policy.delay(attempt)
+
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, time.Second)
+	}
+}
+
+func TestReconnectPolicy_Exhausted(t *testing.T) {
+	assert.False(t, NewReconnectPolicy(0).exhausted(100))
+	assert.False(t, NewReconnectPolicy(3).exhausted(3))
+	assert.True(t, NewReconnectPolicy(3).exhausted(4))
+}
+
+func TestConnection_Connect_Reconnect(t *testing.T) {
+	var connections atomic.Int32
+
+	received := make(chan string, 10)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		n := connections.Add(1)
+
+		_, data, err := c.Read(r.Context())
+		if err != nil {
+			return
+		}
+
+		received <- string(data)
+
+		if n == 1 {
+			_ = c.Close(websocket.StatusGoingAway, "restarting")
+			return
+		}
+
+		_ = c.Write(r.Context(), websocket.MessageText, data)
+		_ = c.Close(websocket.StatusNormalClosure, "")
+	}))
+	defer s.Close()
+
+	policy := NewReconnectPolicy(3)
+	policy.InitialDelay = 10 * time.Millisecond
+	policy.Replay = true
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{Reconnect: policy})
+	assert.NoError(t, err)
+
+	respReceived := make(chan string, 1)
+
+	conn.SetOnMessage(func(_ context.Context, data []byte, _ bool) {
+		respReceived <- string(data)
+	})
+
+	var (
+		states []State
+		l      sync.Mutex
+	)
+
+	conn.SetOnStateChange(func(_ context.Context, state State, _ int) {
+		l.Lock()
+		defer l.Unlock()
+
+		states = append(states, state)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(ctx) }()
+
+	select {
+	case <-conn.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	assert.NoError(t, conn.Send(ctx, "subscribe"))
+
+	select {
+	case resp := <-respReceived:
+		assert.Equal(t, "subscribe", resp)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for replayed request response")
+	}
+
+	assert.Equal(t, "subscribe", <-received)
+	assert.Equal(t, "subscribe", <-received)
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection to stop")
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	assert.Equal(t, []State{StateConnected, StateDisconnected, StateReconnecting, StateConnected}, states[:4])
+}
+
+func TestConnection_Connect_ReconnectExhausted(t *testing.T) {
+	var connections atomic.Int32
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if connections.Add(1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		_ = c.Close(websocket.StatusGoingAway, "restarting")
+	}))
+	defer s.Close()
+
+	policy := NewReconnectPolicy(2)
+	policy.InitialDelay = time.Millisecond
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{Reconnect: policy})
+	assert.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	err = conn.Connect(context.Background())
+	assert.ErrorContains(t, err, "failed to reconnect after 2 attempts")
+	assert.Equal(t, int32(3), connections.Load())
+}
+
+func TestConnection_Connect_NoReconnectAfterClose(t *testing.T) {
+	s := httptest.NewServer(createEchoWSHandler())
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{Reconnect: NewReconnectPolicy(0)})
+	assert.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(context.Background()) }()
+
+	select {
+	case <-conn.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	assert.NoError(t, conn.Close())
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrConnectionClosed)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection to stop")
+	}
+}
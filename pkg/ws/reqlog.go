@@ -1,10 +1,12 @@
 package ws
 
 import (
+	"cmp"
 	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"sort"
 
 	"github.com/fatih/color"
@@ -13,6 +15,7 @@ import (
 type requestLogger struct {
 	transport *http.Transport
 	output    io.Writer
+	proxy     *url.URL
 }
 
 // newRequestLogger creates a new requestLogger for HTTP client request logging.
@@ -33,6 +36,10 @@ func newRequestLogger(output io.Writer, skipSSLVerification bool) *requestLogger
 // It returns an error if the underlying transport fails to complete the request.
 func (rl *requestLogger) RoundTrip(req *http.Request) (*http.Response, error) {
 	if rl.output != nil {
+		if rl.proxy != nil {
+			_, _ = fmt.Fprintf(rl.output, "* Using proxy %s\n", rl.proxy.Redacted())
+		}
+
 		tx := color.New(color.FgGreen)
 		tx.SetWriter(rl.output)
 
@@ -49,12 +56,22 @@ func (rl *requestLogger) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 
 	if rl.output != nil {
+		if resp.TLS != nil {
+			printTLSState(rl.output, resp.TLS)
+			_, _ = fmt.Fprintln(rl.output)
+		}
+
 		rx := color.New(color.FgYellow)
 		rx.SetWriter(rl.output)
 
 		_, _ = fmt.Fprintf(rl.output, "< %s %s\n", resp.Proto, resp.Status)
 		printHeaders(resp.Header, rl.output, "<")
 		_, _ = fmt.Fprintln(rl.output)
+
+		if resp.StatusCode == http.StatusSwitchingProtocols {
+			_, _ = fmt.Fprintf(rl.output, "Negotiated extensions: %s\n", cmp.Or(resp.Header.Get("Sec-WebSocket-Extensions"), "none"))
+		}
+
 		rx.UnsetWriter(rl.output)
 	}
 
@@ -0,0 +1,265 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	sseDefaultEvent = "message"
+	sseSchemePrefix = "sse+"
+)
+
+// SSEConnection receives server-sent events from an HTTP stream.
+// Every event is delivered as a JSON message with the event name, id and data, requests are sent as POST requests to the same URL.
+type SSEConnection struct {
+	*stream
+	client   *http.Client
+	headers  http.Header
+	endpoint string
+}
+
+// sseEvent is the message delivered for a server-sent event.
+// Data contains the event data as JSON if it is a valid JSON document, otherwise as a string.
+type sseEvent struct {
+	Event string          `json:"event"`
+	ID    string          `json:"id,omitempty"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// NewSSE initializes a new server-sent events connection with specified URL and options.
+// It takes rawURL, a string with the sse+http or sse+https scheme, and opts, a pointer to Options with custom settings.
+// The dial, proxy, TLS and header options are applied to the HTTP requests.
+// It returns a pointer to SSEConnection and an error if the URL, headers or connection options are invalid.
+func NewSSE(rawURL string, opts *Options) (*SSEConnection, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSE URL %q: %w", rawURL, err)
+	}
+
+	endpoint := *u
+	endpoint.Scheme = strings.TrimPrefix(strings.ToLower(u.Scheme), sseSchemePrefix)
+
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid SSE URL scheme: %s", u.Scheme)
+	}
+
+	headers, err := parseHeaders(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &SSEConnection{
+		stream:   newStream(u, opts),
+		headers:  headers,
+		endpoint: endpoint.String(),
+	}
+
+	transport, err := newHTTPTransport(&endpoint, opts, &conn.counter)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stream stays open for the whole session, so the timeout limits only waiting for the response headers.
+	transport.transport.ResponseHeaderTimeout = opts.Timeout
+	conn.client = &http.Client{Transport: transport}
+
+	return conn, nil
+}
+
+// Connect opens the event stream and delivers the received events until the stream ends or the connection is closed.
+// It returns an error if the onMessage callback is not set, the server does not respond with 200 OK,
+// an event exceeds the maximum message size, or ErrConnectionClosed when the stream ends.
+func (c *SSEConnection) Connect(ctx context.Context) error {
+	ctx, err := c.start(ctx)
+	if err != nil {
+		return err
+	}
+
+	if c.output != nil {
+		defer func() { c.Stats().Print(c.output) }()
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if err = c.finish(err); err != nil {
+			return fmt.Errorf("failed to open SSE stream: %w", err)
+		}
+
+		return nil
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to open SSE stream: unexpected status %s", resp.Status)
+	}
+
+	c.opened()
+
+	if err = c.finish(c.readEvents(ctx, resp.Body)); err != nil {
+		return fmt.Errorf("failed to read SSE stream: %w", err)
+	}
+
+	return nil
+}
+
+// readEvents parses the event stream and delivers every dispatched event.
+// It returns io.EOF when the stream ends, or the error that terminated reading.
+func (c *SSEConnection) readEvents(ctx context.Context, body io.Reader) error {
+	scanner := newLineScanner(body, c.msgSize)
+
+	var (
+		data      strings.Builder
+		eventType string
+		lastID    string
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if data.Len() > 0 {
+				if err := c.dispatch(ctx, eventType, lastID, strings.TrimSuffix(data.String(), "\n")); err != nil {
+					return err
+				}
+			}
+
+			data.Reset()
+
+			eventType = ""
+
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if int64(data.Len()+len(value)) > c.msgSize {
+				return fmt.Errorf("message exceeds the maximum size of %d bytes", c.msgSize)
+			}
+
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				lastID = value
+			}
+		}
+	}
+
+	return scanError(scanner, c.msgSize)
+}
+
+// dispatch delivers the event as a JSON message with the event name, the last event id and the data.
+// Events without name are delivered with the default message name.
+func (c *SSEConnection) dispatch(ctx context.Context, eventType, id, data string) error {
+	event := sseEvent{Event: eventType, ID: id, Data: json.RawMessage(data)}
+
+	if event.Event == "" {
+		event.Event = sseDefaultEvent
+	}
+
+	if !json.Valid(event.Data) {
+		quoted, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to encode event data: %w", err)
+		}
+
+		event.Data = quoted
+	}
+
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	c.deliver(ctx, msg, false)
+
+	return nil
+}
+
+// Send posts the message to the URL of the stream, JSON messages are sent with the application/json content type.
+// It takes ctx of type context.Context and msg of type string.
+// It returns an error if the connection is not established or the server does not respond with a 2xx status.
+func (c *SSEConnection) Send(ctx context.Context, msg string) error {
+	contentType := "text/plain"
+	if json.Valid([]byte(msg)) {
+		contentType = "application/json"
+	}
+
+	return c.post(ctx, contentType, []byte(msg))
+}
+
+// SendBinary posts the data to the URL of the stream with the application/octet-stream content type.
+// It takes ctx of type context.Context and data of type []byte.
+// It returns an error if the connection is not established or the server does not respond with a 2xx status.
+func (c *SSEConnection) SendBinary(ctx context.Context, data []byte) error {
+	return c.post(ctx, "application/octet-stream", data)
+}
+
+// post sends the data in a POST request to the URL of the stream.
+func (c *SSEConnection) post(ctx context.Context, contentType string, data []byte) error {
+	select {
+	case <-c.ready:
+	case <-ctx.Done():
+		return fmt.Errorf("context canceled while waiting to send: %w", ctx.Err())
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("failed to send request: unexpected status %s", resp.Status)
+	}
+
+	c.sent(len(data))
+
+	return nil
+}
+
+// newRequest creates the HTTP request to the URL of the stream with the configured headers.
+func (c *SSEConnection) newRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header = c.headers.Clone()
+
+	return req, nil
+}
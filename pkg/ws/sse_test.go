@@ -0,0 +1,172 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSEConnection_Events(t *testing.T) {
+	posted := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			posted <- r.Header.Get("Content-Type") + " " + string(body)
+
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, ": comment\n\n")
+		_, _ = fmt.Fprint(w, "data: plain text\n\n")
+		_, _ = fmt.Fprint(w, "event: update\r\nid: 42\r\ndata: {\"price\":\r\ndata: 10}\r\n\r\n")
+		_, _ = fmt.Fprint(w, "event: tick\ndata\n\n")
+		_, _ = fmt.Fprint(w, "data:last\n\n")
+	}))
+	defer server.Close()
+
+	output := &bytes.Buffer{}
+
+	conn, err := NewSSE("sse+"+server.URL, &Options{Headers: []string{"X-Token: secret"}, Output: output})
+	require.NoError(t, err)
+
+	assert.Equal(t, "sse+"+server.URL, conn.URL())
+
+	var received []string
+
+	conn.SetOnMessage(func(_ context.Context, data []byte, isBinary bool) {
+		assert.False(t, isBinary)
+
+		received = append(received, string(data))
+	})
+
+	err = conn.Connect(context.Background())
+	require.ErrorIs(t, err, ErrConnectionClosed)
+
+	// The summary is printed when the stream ends, before any request is sent.
+	stats := conn.Stats()
+
+	assert.NotZero(t, stats.WireReceived)
+	assert.Contains(t, output.String(), fmt.Sprintf("Received: %d bytes payload, %d bytes on wire", stats.PayloadReceived, stats.WireReceived))
+
+	assert.Equal(t, []string{
+		`{"event":"message","data":"plain text"}`,
+		`{"event":"update","id":"42","data":{"price":10}}`,
+		`{"event":"tick","id":"42","data":""}`,
+		`{"event":"message","id":"42","data":"last"}`,
+	}, received)
+
+	require.NoError(t, conn.Send(context.Background(), `{"buy":1}`))
+	assert.Equal(t, `application/json {"buy":1}`, <-posted)
+
+	require.NoError(t, conn.Send(context.Background(), "hello"))
+	assert.Equal(t, "text/plain hello", <-posted)
+
+	metrics := conn.Metrics()
+	assert.Equal(t, uint64(4), metrics.Received.Messages)
+	assert.Equal(t, uint64(2), metrics.Sent.Messages)
+}
+
+func TestSSEConnection_Errors(t *testing.T) {
+	tests := []struct {
+		handler     http.HandlerFunc
+		name        string
+		expectedErr string
+	}{
+		{
+			name: "unexpected status",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expectedErr: "failed to open SSE stream: unexpected status 404 Not Found",
+		},
+		{
+			name: "event too large",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = fmt.Fprint(w, "data: 12345\ndata: 67890\n\n")
+			},
+			expectedErr: "failed to read SSE stream: connection error: message exceeds the maximum size of 8 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			conn, err := NewSSE("sse+"+server.URL, &Options{MaxMessageSize: 8})
+			require.NoError(t, err)
+
+			conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+			assert.EqualError(t, conn.Connect(context.Background()), tt.expectedErr)
+		})
+	}
+}
+
+func TestSSEConnection_Close(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	conn, err := NewSSE(strings.Replace(server.URL, "http://", "SSE+HTTP://", 1), nil)
+	require.NoError(t, err)
+
+	assert.EqualError(t, conn.Close(), "connection is not established")
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(context.Background()) }()
+
+	select {
+	case <-conn.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("connection is not established")
+	}
+
+	assert.EqualError(t, conn.Send(context.Background(), "hi"), "failed to send request: unexpected status 403 Forbidden")
+	assert.ErrorIs(t, conn.Ping(context.Background()), ErrNotSupported)
+
+	require.NoError(t, conn.CloseWithStatus(1000, "bye"))
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrConnectionClosed)
+	case <-time.After(time.Second):
+		t.Fatal("connection is not closed")
+	}
+
+	assert.Equal(t, CloseStatus{Code: 1000, Reason: "bye"}, conn.CloseStatus())
+}
+
+func TestNewSSE_InvalidScheme(t *testing.T) {
+	_, err := NewSSE("sse+ftp://example.com", nil)
+	assert.EqualError(t, err, "invalid SSE URL scheme: sse+ftp")
+}
@@ -0,0 +1,93 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+)
+
+// WireStats contains the byte counters of a WebSocket connection.
+// Payload counters contain the uncompressed size of the messages,
+// while wire counters contain the number of bytes actually transferred over the network,
+// including the handshake, frame headers and, for secure connections, TLS overhead.
+type WireStats struct {
+	PayloadSent     uint64
+	PayloadReceived uint64
+	WireSent        uint64
+	WireReceived    uint64
+}
+
+// wireCounter accumulates the number of bytes transferred through the connections it dials.
+type wireCounter struct {
+	payloadSent     atomic.Uint64
+	payloadReceived atomic.Uint64
+	wireSent        atomic.Uint64
+	wireReceived    atomic.Uint64
+}
+
+// countingConn wraps net.Conn to count the bytes read from and written to the network.
+type countingConn struct {
+	net.Conn
+	counter *wireCounter
+}
+
+// Read reads data from the underlying connection and counts the received bytes.
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.counter.wireReceived.Add(uint64(n)) //nolint:gosec // n is never negative
+
+	return n, err
+}
+
+// Write writes data to the underlying connection and counts the sent bytes.
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.counter.wireSent.Add(uint64(n)) //nolint:gosec // n is never negative
+
+	return n, err
+}
+
+// dialContext wraps the provided dial function so that every established connection is counted.
+// It takes dial, a function with the signature of net.Dialer.DialContext.
+// It returns a dial function suitable for http.Transport.DialContext.
+func (wc *wireCounter) dialContext(
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		return &countingConn{Conn: conn, counter: wc}, nil
+	}
+}
+
+// stats returns a snapshot of the accumulated counters.
+func (wc *wireCounter) stats() WireStats {
+	return WireStats{
+		PayloadSent:     wc.payloadSent.Load(),
+		PayloadReceived: wc.payloadReceived.Load(),
+		WireSent:        wc.wireSent.Load(),
+		WireReceived:    wc.wireReceived.Load(),
+	}
+}
+
+// Print writes a human-readable summary of the byte counters to the provided output.
+// It takes out of type io.Writer.
+// The summary contains payload and wire byte counts per direction and the ratio between them.
+func (s WireStats) Print(out io.Writer) {
+	_, _ = fmt.Fprintf(out, "Sent: %d bytes payload, %d bytes on wire (%s)\n", s.PayloadSent, s.WireSent, ratio(s.WireSent, s.PayloadSent))
+	_, _ = fmt.Fprintf(out, "Received: %d bytes payload, %d bytes on wire (%s)\n", s.PayloadReceived, s.WireReceived, ratio(s.WireReceived, s.PayloadReceived))
+}
+
+// ratio formats the wire to payload ratio as a percentage.
+func ratio(wire, payload uint64) string {
+	if payload == 0 {
+		return "n/a"
+	}
+
+	return fmt.Sprintf("%.1f%%", float64(wire)/float64(payload)*100)
+}
@@ -0,0 +1,165 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountingConn(t *testing.T) {
+	client, server := net.Pipe()
+
+	defer func() {
+		_ = client.Close()
+		_ = server.Close()
+	}()
+
+	counter := &wireCounter{}
+	conn := &countingConn{Conn: client, counter: counter}
+
+	go func() {
+		buf := make([]byte, 5)
+		_, _ = server.Read(buf)
+		_, _ = server.Write([]byte("abc"))
+	}()
+
+	n, err := conn.Write([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	buf := make([]byte, 10)
+	n, err = conn.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	assert.Equal(t, WireStats{WireSent: 5, WireReceived: 3}, counter.stats())
+}
+
+func TestWireCounter_DialContext(t *testing.T) {
+	counter := &wireCounter{}
+
+	dial := counter.dialContext(func(context.Context, string, string) (net.Conn, error) {
+		return nil, assert.AnError
+	})
+
+	_, err := dial(context.Background(), "tcp", "localhost:0")
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestWireStats_Print(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	WireStats{PayloadSent: 200, WireSent: 50}.Print(buf)
+
+	assert.Equal(t, "Sent: 200 bytes payload, 50 bytes on wire (25.0%)\nReceived: 0 bytes payload, 0 bytes on wire (n/a)\n", buf.String())
+}
+
+func TestParseCompressionMode(t *testing.T) {
+	tests := []struct {
+		mode     string
+		expected websocket.CompressionMode
+		wantErr  bool
+	}{
+		{mode: "", expected: websocket.CompressionDisabled},
+		{mode: CompressionDisabled, expected: websocket.CompressionDisabled},
+		{mode: CompressionContextTakeover, expected: websocket.CompressionContextTakeover},
+		{mode: CompressionNoContextTakeover, expected: websocket.CompressionNoContextTakeover},
+		{mode: "gzip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			mode, err := parseCompressionMode(tt.mode)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "invalid compression mode")
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, mode)
+		})
+	}
+}
+
+func TestConnection_Compression(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, &websocket.AcceptOptions{CompressionMode: websocket.CompressionContextTakeover})
+		if err != nil {
+			return
+		}
+
+		for {
+			msgType, data, err := c.Read(r.Context())
+			if err != nil {
+				return
+			}
+
+			if err := c.Write(r.Context(), msgType, data); err != nil {
+				return
+			}
+		}
+	}))
+	defer s.Close()
+
+	output := &bytes.Buffer{}
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{
+		Output:               output,
+		Compression:          CompressionContextTakeover,
+		CompressionThreshold: 1,
+	})
+	assert.NoError(t, err)
+
+	respReceived := make(chan struct{})
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {
+		close(respReceived)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = conn.Connect(ctx)
+	}()
+
+	select {
+	case <-conn.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	payload := strings.Repeat("compressible payload ", 100)
+	assert.NoError(t, conn.Send(ctx, payload))
+
+	select {
+	case <-respReceived:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for response")
+	}
+
+	cancel()
+	<-done
+
+	stats := conn.Stats()
+
+	assert.Equal(t, uint64(len(payload)), stats.PayloadSent)
+	assert.Equal(t, uint64(len(payload)), stats.PayloadReceived)
+	assert.Less(t, stats.WireSent, stats.PayloadSent)
+	assert.Less(t, stats.WireReceived, stats.PayloadReceived)
+	assert.Contains(t, output.String(), "Negotiated extensions: permessage-deflate")
+	assert.Contains(t, output.String(), fmt.Sprintf("Sent: %d bytes payload, %d bytes on wire", stats.PayloadSent, stats.WireSent))
+	assert.Contains(t, output.String(), fmt.Sprintf("Received: %d bytes payload, %d bytes on wire", stats.PayloadReceived, stats.WireReceived))
+	assert.NotContains(t, output.String(), "Sent: 0 bytes payload")
+}
@@ -0,0 +1,211 @@
+package ws
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TCPConnection exchanges newline-delimited messages over a raw TCP connection, optionally secured with TLS.
+// Every received line is delivered as a message, text messages are sent with a trailing newline
+// and binary messages are written as is.
+type TCPConnection struct {
+	*stream
+	dialer    *dialer
+	tlsConfig *tls.Config
+	conn      net.Conn
+	timeout   time.Duration
+}
+
+// NewTCP initializes a new TCP connection with specified URL and options.
+// It takes rawURL, a string with the tcp or tls scheme and the host:port address, and opts, a pointer to Options with custom settings.
+// The dial and, for the tls scheme, TLS options are applied to the connection.
+// It returns a pointer to TCPConnection and an error if the URL or connection options are invalid.
+func NewTCP(rawURL string, opts *Options) (*TCPConnection, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TCP URL %q: %w", rawURL, err)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != SchemeTCP && scheme != SchemeTLS {
+		return nil, fmt.Errorf("invalid TCP URL scheme: %s", u.Scheme)
+	}
+
+	if u.Port() == "" {
+		return nil, fmt.Errorf("port is required: %s", rawURL)
+	}
+
+	if opts.Proxy != "" {
+		return nil, fmt.Errorf("proxy is %w", ErrNotSupported)
+	}
+
+	netDialer, err := newDialer(opts.Dial, opts.Output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dial options: %w", err)
+	}
+
+	conn := &TCPConnection{
+		stream:  newStream(u, opts),
+		dialer:  netDialer,
+		timeout: opts.Timeout,
+	}
+
+	if scheme == SchemeTLS {
+		if conn.tlsConfig, err = newTLSConfig(opts.TLS, opts.SkipSSLVerification); err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+		}
+
+		if conn.tlsConfig.ServerName == "" {
+			conn.tlsConfig.ServerName = u.Hostname()
+		}
+	}
+
+	return conn, nil
+}
+
+// Connect dials the server and delivers the received lines until the connection is closed.
+// It returns an error if the onMessage callback is not set, the connection or TLS handshake fails,
+// a line exceeds the maximum message size, or ErrConnectionClosed when the server closes the connection.
+func (c *TCPConnection) Connect(ctx context.Context) error {
+	ctx, err := c.start(ctx)
+	if err != nil {
+		return err
+	}
+
+	if c.output != nil {
+		defer func() { c.Stats().Print(c.output) }()
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		if err = c.finish(err); err != nil {
+			return fmt.Errorf("failed to dial TCP: %w", err)
+		}
+
+		return nil
+	}
+
+	c.l.Lock()
+	c.conn = conn
+	c.l.Unlock()
+
+	// The connection is closed when the context is canceled, so that the blocked read returns.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	defer func() { _ = conn.Close() }()
+
+	c.opened()
+
+	if err = c.finish(c.readLines(ctx, conn)); err != nil {
+		return fmt.Errorf("failed to read from TCP: %w", err)
+	}
+
+	return nil
+}
+
+// dial establishes the TCP connection and performs the TLS handshake for the tls scheme.
+// The connection timeout covers both the TCP connection and the TLS handshake.
+func (c *TCPConnection) dial(ctx context.Context) (net.Conn, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	startTime := time.Now()
+
+	conn, err := c.counter.dialContext(c.dialer.DialContext)(ctx, networkTCP, c.url.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.tlsConfig != nil {
+		tlsConn := tls.Client(conn, c.tlsConfig)
+
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+
+			return nil, fmt.Errorf("TLS handshake failed: %w", err)
+		}
+
+		if c.output != nil {
+			state := tlsConn.ConnectionState()
+			printTLSState(c.output, &state)
+		}
+
+		conn = tlsConn
+	}
+
+	if c.output != nil {
+		_, _ = fmt.Fprintf(c.output, "Connected to %s in %v\n", conn.RemoteAddr(), time.Since(startTime))
+	}
+
+	return conn, nil
+}
+
+// readLines delivers every line received over conn as a text message.
+// It returns io.EOF when the server closes the connection, or the error that terminated reading.
+func (c *TCPConnection) readLines(ctx context.Context, conn net.Conn) error {
+	scanner := newLineScanner(conn, c.msgSize)
+
+	for scanner.Scan() {
+		c.deliver(ctx, []byte(scanner.Text()), false)
+	}
+
+	return scanError(scanner, c.msgSize)
+}
+
+// Send writes the message followed by a newline to the connection.
+// It takes ctx of type context.Context and msg of type string.
+// It returns an error if the connection is not established or writing fails.
+func (c *TCPConnection) Send(ctx context.Context, msg string) error {
+	return c.write(ctx, []byte(msg+"\n"))
+}
+
+// SendBinary writes the data to the connection as is, without a delimiter.
+// It takes ctx of type context.Context and data of type []byte.
+// It returns an error if the connection is not established or writing fails.
+func (c *TCPConnection) SendBinary(ctx context.Context, data []byte) error {
+	return c.write(ctx, data)
+}
+
+// write waits for the connection to be established and writes the data to it.
+func (c *TCPConnection) write(ctx context.Context, data []byte) error {
+	select {
+	case <-c.ready:
+	case <-ctx.Done():
+		return fmt.Errorf("context canceled while waiting to send: %w", ctx.Err())
+	}
+
+	c.l.Lock()
+	conn := c.conn
+	c.l.Unlock()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+		defer func() { _ = conn.SetWriteDeadline(time.Time{}) }()
+	}
+
+	if _, err := conn.Write(data); err != nil {
+		if err = handleError(err); err != nil {
+			return fmt.Errorf("failed to write to TCP: %w", err)
+		}
+
+		return nil
+	}
+
+	c.sent(len(data))
+
+	return nil
+}
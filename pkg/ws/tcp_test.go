@@ -0,0 +1,199 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveLines accepts a single connection, greets the client with the "hello" line, replies to every received line
+// with its copy prefixed by "echo: " and closes the connection after the "quit" line.
+func serveLines(t *testing.T, listener net.Listener) {
+	t.Helper()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		_, _ = conn.Write([]byte("hello\r\n"))
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			if scanner.Text() == "quit" {
+				return
+			}
+
+			_, _ = conn.Write([]byte("echo: " + scanner.Text() + "\n"))
+		}
+	}()
+}
+
+func TestTCPConnection(t *testing.T) {
+	plain, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { _ = plain.Close() }()
+
+	server := httptest.NewUnstartedServer(nil)
+	server.StartTLS()
+	server.Close()
+
+	secure, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	secure = tls.NewListener(secure, server.TLS)
+
+	defer func() { _ = secure.Close() }()
+
+	tests := []struct {
+		listener net.Listener
+		name     string
+		url      string
+	}{
+		{name: "tcp", url: "tcp://" + plain.Addr().String(), listener: plain},
+		{name: "tls", url: "tls://" + secure.Addr().String(), listener: secure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serveLines(t, tt.listener)
+
+			output := &bytes.Buffer{}
+
+			conn, err := NewTCP(tt.url, &Options{SkipSSLVerification: true, Output: output})
+			require.NoError(t, err)
+
+			received := make(chan string, 3)
+
+			conn.SetOnMessage(func(_ context.Context, data []byte, isBinary bool) {
+				assert.False(t, isBinary)
+
+				received <- string(data)
+			})
+
+			done := make(chan error, 1)
+
+			go func() { done <- conn.Connect(context.Background()) }()
+
+			require.NoError(t, conn.Send(context.Background(), "ping"))
+			require.NoError(t, conn.SendBinary(context.Background(), []byte("pong\n")))
+
+			assert.Equal(t, "hello", <-received)
+			assert.Equal(t, "echo: ping", <-received)
+			assert.Equal(t, "echo: pong", <-received)
+
+			require.NoError(t, conn.Send(context.Background(), "quit"))
+
+			select {
+			case err := <-done:
+				assert.ErrorIs(t, err, ErrConnectionClosed)
+			case <-time.After(time.Second):
+				t.Fatal("connection is not closed by the server")
+			}
+
+			assert.Equal(t, CloseStatus{}, conn.CloseStatus())
+			assert.Equal(t, uint64(3), conn.Metrics().Sent.Messages)
+			assert.Equal(t, uint64(3), conn.Metrics().Received.Messages)
+
+			stats := conn.Stats()
+
+			assert.NotZero(t, stats.WireSent)
+			assert.NotZero(t, stats.WireReceived)
+			assert.Contains(t, output.String(), fmt.Sprintf("Sent: %d bytes payload, %d bytes on wire", stats.PayloadSent, stats.WireSent))
+			assert.Contains(t, output.String(), fmt.Sprintf("Received: %d bytes payload, %d bytes on wire", stats.PayloadReceived, stats.WireReceived))
+		})
+	}
+}
+
+func TestTCPConnection_Close(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer func() { _ = listener.Close() }()
+
+	serveLines(t, listener)
+
+	conn, err := NewTCP("tcp://"+listener.Addr().String(), nil)
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(context.Background()) }()
+
+	<-conn.Ready()
+
+	require.NoError(t, conn.Close())
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrConnectionClosed)
+	case <-time.After(time.Second):
+		t.Fatal("connection is not closed")
+	}
+
+	assert.Equal(t, CloseStatus{Code: 1000, Reason: "closing connection"}, conn.CloseStatus())
+}
+
+func TestNewTCP_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		opts        *Options
+		expectedErr string
+	}{
+		{name: "missing port", url: "tcp://localhost", expectedErr: "port is required: tcp://localhost"},
+		{name: "proxy", url: "tcp://localhost:80", opts: &Options{Proxy: "http://proxy:8080"}, expectedErr: "proxy is not supported by the transport"},
+		{name: "invalid TLS version", url: "tls://localhost:443", opts: &Options{TLS: TLSOptions{MinVersion: "2.0"}}, expectedErr: "invalid TLS configuration: unsupported TLS version: 2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTCP(tt.url, tt.opts)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	tests := []struct {
+		expected  any
+		url       string
+		websocket bool
+	}{
+		{url: "ws://localhost", expected: &Connection{}, websocket: true},
+		{url: "wss://localhost", expected: &Connection{}, websocket: true},
+		{url: "sse+https://localhost/events", expected: &SSEConnection{}},
+		{url: "sse+http://localhost/events", expected: &SSEConnection{}},
+		{url: "tcp://localhost:7000", expected: &TCPConnection{}},
+		{url: "TLS://localhost:7000", expected: &TCPConnection{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			conn, err := NewTransport(tt.url, nil)
+			require.NoError(t, err)
+
+			assert.IsType(t, tt.expected, conn)
+			assert.Equal(t, "localhost", conn.Hostname())
+			assert.Equal(t, tt.websocket, IsWebSocket(tt.url))
+		})
+	}
+
+	_, err := NewTransport("tcp://localhost", nil)
+	assert.EqualError(t, err, "port is required: tcp://localhost")
+}
@@ -0,0 +1,148 @@
+package ws
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// HandshakeTiming contains the duration of the phases of a WebSocket handshake.
+// Phases that did not happen, like DNS lookup for IP addresses or TLS handshake for ws:// URLs, are zero.
+// Upgrade is the time between sending the upgrade request and receiving the first byte of the response,
+// which is the time spent by the server in the upgrade handler.
+type HandshakeTiming struct {
+	DNSLookup    time.Duration
+	TCPConnect   time.Duration
+	TLSHandshake time.Duration
+	Upgrade      time.Duration
+	Total        time.Duration
+}
+
+// String returns a human-readable breakdown of the handshake phases.
+func (t HandshakeTiming) String() string {
+	return fmt.Sprintf(
+		"DNS lookup: %v, TCP connect: %v, TLS handshake: %v, upgrade: %v",
+		t.DNSLookup, t.TCPConnect, t.TLSHandshake, t.Upgrade,
+	)
+}
+
+// handshakeTracer records the timestamps of handshake phases reported by httptrace.
+// Callbacks can be invoked concurrently, for example when several addresses are dialed in parallel.
+type handshakeTracer struct {
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	l            sync.Mutex
+}
+
+// newHandshakeTracer creates a new handshakeTracer with the handshake start time set to now.
+func newHandshakeTracer() *handshakeTracer {
+	return &handshakeTracer{start: time.Now()}
+}
+
+// clientTrace returns httptrace.ClientTrace hooks that record the handshake phases.
+func (ht *handshakeTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { ht.mark(&ht.dnsStart, true) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { ht.mark(&ht.dnsDone, false) },
+		ConnectStart: func(string, string) {
+			ht.mark(&ht.connectStart, true)
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				ht.mark(&ht.connectDone, false)
+			}
+		},
+		TLSHandshakeStart: func() { ht.mark(&ht.tlsStart, true) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			ht.mark(&ht.tlsDone, false)
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { ht.mark(&ht.wroteRequest, false) },
+		GotFirstResponseByte: func() { ht.mark(&ht.firstByte, false) },
+	}
+}
+
+// mark stores the current time in ts.
+// If first is true, only the first occurrence is recorded, otherwise the latest one.
+func (ht *handshakeTracer) mark(ts *time.Time, first bool) {
+	ht.l.Lock()
+	defer ht.l.Unlock()
+
+	if first && !ts.IsZero() {
+		return
+	}
+
+	*ts = time.Now()
+}
+
+// timing calculates the duration of the recorded handshake phases.
+// It returns HandshakeTiming with the total duration measured until now.
+func (ht *handshakeTracer) timing() HandshakeTiming {
+	ht.l.Lock()
+	defer ht.l.Unlock()
+
+	return HandshakeTiming{
+		DNSLookup:    between(ht.dnsStart, ht.dnsDone),
+		TCPConnect:   between(ht.connectStart, ht.connectDone),
+		TLSHandshake: between(ht.tlsStart, ht.tlsDone),
+		Upgrade:      between(ht.wroteRequest, ht.firstByte),
+		Total:        time.Since(ht.start),
+	}
+}
+
+// between returns the duration between start and end, or zero if any of them was not recorded.
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+
+	return end.Sub(start)
+}
+
+// milliseconds converts d into fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// timingRecord is the JSON representation of the handshake timing with durations in milliseconds.
+type timingRecord struct {
+	URL          string  `json:"url"`
+	DNSLookup    float64 `json:"dns_lookup_ms"`
+	TCPConnect   float64 `json:"tcp_connect_ms"`
+	TLSHandshake float64 `json:"tls_handshake_ms"`
+	Upgrade      float64 `json:"upgrade_ms"`
+	Total        float64 `json:"total_ms"`
+}
+
+// writeTiming writes the handshake timing as a single line JSON object to out.
+// It takes out of type io.Writer, wsURL of the connection and timing of type HandshakeTiming.
+// It returns an error if the timing cannot be written.
+func writeTiming(out io.Writer, wsURL string, timing HandshakeTiming) error {
+	data, err := json.Marshal(timingRecord{
+		URL:          wsURL,
+		DNSLookup:    milliseconds(timing.DNSLookup),
+		TCPConnect:   milliseconds(timing.TCPConnect),
+		TLSHandshake: milliseconds(timing.TLSHandshake),
+		Upgrade:      milliseconds(timing.Upgrade),
+		Total:        milliseconds(timing.Total),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode handshake timing: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(out, "%s\n", data); err != nil {
+		return fmt.Errorf("failed to write handshake timing: %w", err)
+	}
+
+	return nil
+}
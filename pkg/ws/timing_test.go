@@ -0,0 +1,128 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandshakeTiming_String(t *testing.T) {
+	timing := HandshakeTiming{
+		DNSLookup:    time.Millisecond,
+		TCPConnect:   2 * time.Millisecond,
+		TLSHandshake: 3 * time.Millisecond,
+		Upgrade:      4 * time.Millisecond,
+		Total:        10 * time.Millisecond,
+	}
+
+	assert.Equal(t, "DNS lookup: 1ms, TCP connect: 2ms, TLS handshake: 3ms, upgrade: 4ms", timing.String())
+}
+
+func TestBetween(t *testing.T) {
+	start := time.Now()
+
+	assert.Equal(t, time.Second, between(start, start.Add(time.Second)))
+	assert.Zero(t, between(time.Time{}, start))
+	assert.Zero(t, between(start, time.Time{}))
+	assert.Zero(t, between(start, start.Add(-time.Second)))
+}
+
+func TestHandshakeTracer(t *testing.T) {
+	tracer := newHandshakeTracer()
+	trace := tracer.clientTrace()
+
+	trace.ConnectStart("tcp", "127.0.0.1:80")
+	firstStart := tracer.connectStart
+
+	trace.ConnectStart("tcp", "[::1]:80")
+	assert.Equal(t, firstStart, tracer.connectStart)
+
+	trace.ConnectDone("tcp", "[::1]:80", assert.AnError)
+	assert.True(t, tracer.connectDone.IsZero())
+
+	trace.ConnectDone("tcp", "127.0.0.1:80", nil)
+	trace.WroteRequest(httptrace.WroteRequestInfo{})
+	trace.GotFirstResponseByte()
+
+	timing := tracer.timing()
+
+	assert.Zero(t, timing.DNSLookup)
+	assert.Zero(t, timing.TLSHandshake)
+	assert.GreaterOrEqual(t, timing.Total, timing.TCPConnect+timing.Upgrade)
+}
+
+func TestWriteTiming(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	err := writeTiming(out, "wss://example.com", HandshakeTiming{
+		DNSLookup:    1500 * time.Microsecond,
+		TCPConnect:   2 * time.Millisecond,
+		TLSHandshake: 3 * time.Millisecond,
+		Upgrade:      4 * time.Millisecond,
+		Total:        11 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"url": "wss://example.com",
+		"dns_lookup_ms": 1.5,
+		"tcp_connect_ms": 2,
+		"tls_handshake_ms": 3,
+		"upgrade_ms": 4,
+		"total_ms": 11
+	}`, out.String())
+	assert.Equal(t, byte('\n'), out.Bytes()[out.Len()-1])
+}
+
+func TestConnection_Timing(t *testing.T) {
+	s := httptest.NewTLSServer(createEchoWSHandler())
+	defer s.Close()
+
+	timing := &bytes.Buffer{}
+	output := &bytes.Buffer{}
+
+	conn, err := New("wss://"+s.Listener.Addr().String(), &Options{
+		Output:              output,
+		Timing:              timing,
+		SkipSSLVerification: true,
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(ctx) }()
+
+	select {
+	case <-conn.Ready():
+	case err := <-done:
+		t.Fatalf("unexpected connection error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	cancel()
+	<-done
+
+	var record timingRecord
+
+	require.NoError(t, json.Unmarshal(timing.Bytes(), &record))
+
+	assert.Equal(t, "wss://"+s.Listener.Addr().String(), record.URL)
+	assert.Zero(t, record.DNSLookup)
+	assert.Positive(t, record.TCPConnect)
+	assert.Positive(t, record.TLSHandshake)
+	assert.Positive(t, record.Upgrade)
+	assert.GreaterOrEqual(t, record.Total, record.TCPConnect+record.TLSHandshake+record.Upgrade)
+
+	assert.Regexp(t, `WebSocket handshake completed in \S+ \(DNS lookup: 0s, TCP connect: \S+, TLS handshake: \S+, upgrade: \S+\)`, output.String())
+}
@@ -0,0 +1,171 @@
+package ws
+
+import (
+	"cmp"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const keyLogFileMode = 0o600
+
+// TLSOptions contains the TLS settings used for secure WebSocket connections.
+// CertFile and KeyFile point to PEM encoded client certificate and private key for mutual TLS,
+// if KeyFile is empty the private key is expected in CertFile.
+// CAFile points to a PEM bundle of certificate authorities that replaces the system pool.
+// MinVersion is the minimum accepted TLS version: 1.0, 1.1, 1.2 or 1.3.
+// ServerName overrides the host name used for SNI and server certificate verification.
+// KeyLogFile points to a file where TLS session secrets are appended in NSS key log format,
+// which allows tools like Wireshark to decrypt captured traffic.
+type TLSOptions struct {
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	MinVersion string
+	ServerName string
+	KeyLogFile string
+}
+
+// newTLSConfig builds a tls.Config from the provided options.
+// It takes opts of type TLSOptions and skipVerify of type bool to disable server certificate verification.
+// It returns a pointer to tls.Config and an error if the certificates cannot be loaded or the TLS version is invalid.
+func newTLSConfig(opts TLSOptions, skipVerify bool) (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: skipVerify, //nolint:gosec // Skip SSL verification
+		ServerName:         opts.ServerName,
+	}
+
+	minVersion, err := parseTLSVersion(opts.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.MinVersion = minVersion
+
+	if opts.CertFile != "" {
+		keyFile := opts.KeyFile
+		if keyFile == "" {
+			keyFile = opts.CertFile
+		}
+
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	} else if opts.KeyFile != "" {
+		return nil, fmt.Errorf("client key is provided without client certificate")
+	}
+
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid CA certificates found in %s", opts.CAFile)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if opts.KeyLogFile != "" {
+		keyLog := keyLogFile(opts.KeyLogFile)
+		if _, err := keyLog.Write(nil); err != nil {
+			return nil, err
+		}
+
+		cfg.KeyLogWriter = keyLog
+	}
+
+	return cfg, nil
+}
+
+// parseTLSVersion converts a TLS version name into its numeric identifier.
+// It takes version of type string, an empty version means the default minimum version.
+// It returns the TLS version identifier and an error if the version is not supported.
+func parseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "":
+		return 0, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version: %s", version)
+	}
+}
+
+// keyLogFile is an io.Writer that appends TLS key log lines to the file with the given path.
+// The file is opened for every write, so no file handle is kept open between handshakes.
+type keyLogFile string
+
+// Write appends p to the key log file, creating the file if it does not exist.
+// It returns the number of bytes written and an error if the file cannot be opened or written.
+func (f keyLogFile) Write(p []byte) (int, error) {
+	file, err := os.OpenFile(string(f), os.O_APPEND|os.O_CREATE|os.O_WRONLY, keyLogFileMode)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open TLS key log file: %w", err)
+	}
+
+	n, err := file.Write(p)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return n, err
+}
+
+// printTLSState writes the negotiated TLS parameters and the server certificate chain to the output.
+// It takes out of type io.Writer and state of type *tls.ConnectionState.
+// For every certificate it prints the subject, issuer, subject alternative names and expiration date.
+func printTLSState(out io.Writer, state *tls.ConnectionState) {
+	_, _ = fmt.Fprintf(
+		out,
+		"* TLS %s, cipher %s, ALPN %s\n",
+		strings.TrimPrefix(tls.VersionName(state.Version), "TLS "),
+		tls.CipherSuiteName(state.CipherSuite),
+		cmp.Or(state.NegotiatedProtocol, "none"),
+	)
+
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(out, "* Server certificate chain:")
+
+	for i, cert := range state.PeerCertificates {
+		sans := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses))
+		sans = append(sans, cert.DNSNames...)
+
+		for _, ip := range cert.IPAddresses {
+			sans = append(sans, ip.String())
+		}
+
+		expiry := cert.NotAfter.UTC().Format(time.RFC3339)
+		if time.Now().After(cert.NotAfter) {
+			expiry += " (expired)"
+		}
+
+		_, _ = fmt.Fprintf(out, "*  %d subject: %s\n", i, cert.Subject)
+		_, _ = fmt.Fprintf(out, "*    issuer: %s\n", cert.Issuer)
+
+		if len(sans) > 0 {
+			_, _ = fmt.Fprintf(out, "*    SANs: %s\n", strings.Join(sans, ", "))
+		}
+
+		_, _ = fmt.Fprintf(out, "*    expires: %s\n", expiry)
+	}
+}
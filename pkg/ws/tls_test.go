@@ -0,0 +1,385 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+func createTestCert(t *testing.T, dir, name string, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return &testCert{cert: cert, key: key, certFile: certFile, keyFile: keyFile}
+}
+
+func createTestPKI(t *testing.T) (ca, server, client *testCert) {
+	t.Helper()
+
+	dir := t.TempDir()
+	notAfter := time.Now().Add(time.Hour)
+
+	ca = createTestCert(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "wsget test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+
+	server = createTestCert(t, dir, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "gateway.internal"},
+		DNSNames:     []string{"gateway.internal"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+
+	client = createTestCert(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "wsget client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	return ca, server, client
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected uint16
+		wantErr  bool
+	}{
+		{version: "", expected: 0},
+		{version: "1.0", expected: tls.VersionTLS10},
+		{version: "1.1", expected: tls.VersionTLS11},
+		{version: "1.2", expected: tls.VersionTLS12},
+		{version: "1.3", expected: tls.VersionTLS13},
+		{version: "2.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			version, err := parseTLSVersion(tt.version)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "unsupported TLS version")
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	ca, _, client := createTestPKI(t)
+
+	invalidCA := filepath.Join(t.TempDir(), "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidCA, []byte("not a certificate"), 0o600))
+
+	tests := []struct {
+		name    string
+		wantErr string
+		opts    TLSOptions
+	}{
+		{
+			name: "empty options",
+			opts: TLSOptions{},
+		},
+		{
+			name: "full options",
+			opts: TLSOptions{
+				CertFile:   client.certFile,
+				KeyFile:    client.keyFile,
+				CAFile:     ca.certFile,
+				MinVersion: "1.2",
+				ServerName: "gateway.internal",
+			},
+		},
+		{
+			name:    "key without certificate",
+			opts:    TLSOptions{KeyFile: client.keyFile},
+			wantErr: "client key is provided without client certificate",
+		},
+		{
+			name:    "missing key in certificate file",
+			opts:    TLSOptions{CertFile: client.certFile},
+			wantErr: "failed to load client certificate",
+		},
+		{
+			name:    "missing CA file",
+			opts:    TLSOptions{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr: "failed to read CA certificates",
+		},
+		{
+			name:    "invalid CA file",
+			opts:    TLSOptions{CAFile: invalidCA},
+			wantErr: "no valid CA certificates found",
+		},
+		{
+			name:    "invalid TLS version",
+			opts:    TLSOptions{MinVersion: "1.4"},
+			wantErr: "unsupported TLS version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := newTLSConfig(tt.opts, false)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Nil(t, cfg)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.opts.ServerName, cfg.ServerName)
+			assert.Equal(t, tt.opts.CertFile != "", len(cfg.Certificates) == 1)
+			assert.Equal(t, tt.opts.CAFile != "", cfg.RootCAs != nil)
+		})
+	}
+}
+
+func TestConnection_MutualTLS(t *testing.T) {
+	ca, server, client := createTestPKI(t)
+
+	serverCert, err := tls.LoadX509KeyPair(server.certFile, server.keyFile)
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	s := httptest.NewUnstartedServer(createEchoWSHandler())
+	s.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+	s.StartTLS()
+
+	defer s.Close()
+
+	tests := []struct {
+		name    string
+		opts    TLSOptions
+		wantErr bool
+	}{
+		{
+			name: "client certificate and private CA",
+			opts: TLSOptions{
+				CertFile:   client.certFile,
+				KeyFile:    client.keyFile,
+				CAFile:     ca.certFile,
+				ServerName: "gateway.internal",
+				MinVersion: "1.3",
+			},
+		},
+		{
+			name: "without client certificate",
+			opts: TLSOptions{
+				CAFile:     ca.certFile,
+				ServerName: "gateway.internal",
+			},
+			wantErr: true,
+		},
+		{
+			name: "without private CA",
+			opts: TLSOptions{
+				CertFile: client.certFile,
+				KeyFile:  client.keyFile,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := New("wss://"+s.Listener.Addr().String(), &Options{TLS: tt.opts})
+			require.NoError(t, err)
+
+			conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+
+			go func() { done <- conn.Connect(ctx) }()
+
+			if tt.wantErr {
+				select {
+				case err := <-done:
+					assert.Error(t, err)
+				case <-time.After(5 * time.Second):
+					t.Fatal("timeout waiting for handshake failure")
+				}
+
+				cancel()
+
+				return
+			}
+
+			select {
+			case <-conn.Ready():
+			case err := <-done:
+				t.Fatalf("unexpected connection error: %v", err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for connection")
+			}
+
+			cancel()
+			<-done
+		})
+	}
+}
+
+func TestConnection_TLSInspection(t *testing.T) {
+	ca, server, _ := createTestPKI(t)
+
+	serverCert, err := tls.LoadX509KeyPair(server.certFile, server.keyFile)
+	require.NoError(t, err)
+
+	s := httptest.NewUnstartedServer(createEchoWSHandler())
+	s.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	s.StartTLS()
+
+	defer s.Close()
+
+	keyLog := filepath.Join(t.TempDir(), "keys.log")
+	output := &bytes.Buffer{}
+
+	conn, err := New("wss://"+s.Listener.Addr().String(), &Options{
+		Output: output,
+		TLS: TLSOptions{
+			CAFile:     ca.certFile,
+			ServerName: "gateway.internal",
+			KeyLogFile: keyLog,
+		},
+	})
+	require.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(ctx) }()
+
+	select {
+	case <-conn.Ready():
+	case err := <-done:
+		t.Fatalf("unexpected connection error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for connection")
+	}
+
+	cancel()
+	<-done
+
+	out := output.String()
+	assert.Contains(t, out, "* TLS 1.3, cipher TLS_")
+	assert.Contains(t, out, "* Server certificate chain:\n")
+	assert.Contains(t, out, "*  0 subject: CN=gateway.internal\n")
+	assert.Contains(t, out, "*    issuer: CN=wsget test CA\n")
+	assert.Contains(t, out, "*    SANs: gateway.internal, 127.0.0.1\n")
+	assert.Contains(t, out, "*    expires: ")
+
+	keys, err := os.ReadFile(keyLog)
+	require.NoError(t, err)
+	assert.Contains(t, string(keys), "CLIENT_TRAFFIC_SECRET_0 ")
+}
+
+func TestKeyLogFile_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.log")
+	keyLog := keyLogFile(path)
+
+	n, err := keyLog.Write([]byte("line1\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+
+	_, err = keyLog.Write([]byte("line2\n"))
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "line1\nline2\n", string(data))
+
+	_, err = keyLogFile(filepath.Join(t.TempDir(), "missing", "keys.log")).Write(nil)
+	assert.ErrorContains(t, err, "failed to open TLS key log file")
+}
+
+func TestPrintTLSState(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	printTLSState(out, &tls.ConnectionState{
+		Version:            tls.VersionTLS12,
+		CipherSuite:        tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		NegotiatedProtocol: "http/1.1",
+		PeerCertificates: []*x509.Certificate{{
+			Subject:  pkix.Name{CommonName: "old.example.com"},
+			Issuer:   pkix.Name{CommonName: "Old CA"},
+			NotAfter: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		}},
+	})
+
+	assert.Equal(t, "* TLS 1.2, cipher TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, ALPN http/1.1\n"+
+		"* Server certificate chain:\n"+
+		"*  0 subject: CN=old.example.com\n"+
+		"*    issuer: CN=Old CA\n"+
+		"*    expires: 2020-01-02T03:04:05Z (expired)\n", out.String())
+}
@@ -0,0 +1,286 @@
+package ws
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/ksysoev/wsget/pkg/core"
+)
+
+// URL schemes of the transports other than WebSocket.
+const (
+	SchemeSSE         = "sse+https"
+	SchemeSSEInsecure = "sse+http"
+	SchemeTCP         = "tcp"
+	SchemeTLS         = "tls"
+)
+
+var ErrNotSupported = errors.New("not supported by the transport")
+
+// Transport is a connection to the server over one of the supported transports.
+// WebSocket connections are created for ws:// and wss:// URLs, server-sent events streams for sse+http:// and sse+https://,
+// and line-delimited TCP connections for tcp:// and tls:// URLs.
+type Transport interface {
+	core.ConnectionHandler
+	Connect(ctx context.Context) error
+	Ready() <-chan struct{}
+	Hostname() string
+	CloseStatus() CloseStatus
+	Close() error
+}
+
+// NewTransport creates the connection for the transport selected by the scheme of the URL.
+// It takes rawURL of type string and opts, a pointer to Options with custom settings.
+// It returns the Transport and an error if the URL or the options are invalid.
+func NewTransport(rawURL string, opts *Options) (Transport, error) {
+	switch transportScheme(rawURL) {
+	case SchemeSSE, SchemeSSEInsecure:
+		conn, err := NewSSE(rawURL, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return conn, nil
+	case SchemeTCP, SchemeTLS:
+		conn, err := NewTCP(rawURL, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return conn, nil
+	default:
+		conn, err := New(rawURL, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return conn, nil
+	}
+}
+
+// IsWebSocket reports whether the URL is served by the WebSocket transport.
+func IsWebSocket(rawURL string) bool {
+	switch transportScheme(rawURL) {
+	case SchemeSSE, SchemeSSEInsecure, SchemeTCP, SchemeTLS:
+		return false
+	default:
+		return true
+	}
+}
+
+// transportScheme returns the lowercase scheme of the URL, or an empty string if the URL has no scheme.
+func transportScheme(rawURL string) string {
+	scheme, _, found := strings.Cut(rawURL, "://")
+	if !found {
+		return ""
+	}
+
+	return strings.ToLower(scheme)
+}
+
+// stream contains the state shared by the transports that receive messages from a byte stream.
+// Such transports have no WebSocket framing, so pings are not supported and closing the connection
+// simply terminates the stream.
+type stream struct {
+	output      io.Writer
+	onMessage   func(context.Context, []byte, bool)
+	url         *url.URL
+	ready       chan struct{}
+	cancel      context.CancelFunc
+	closeStatus CloseStatus
+	metrics     metricsCollector
+	counter     wireCounter
+	msgSize     int64
+	l           sync.Mutex
+	closed      bool
+}
+
+// newStream creates the shared state of a stream transport for the URL u.
+func newStream(u *url.URL, opts *Options) *stream {
+	msgSize := opts.MaxMessageSize
+	if msgSize <= 0 {
+		msgSize = DefaultMaxMessageSize
+	}
+
+	return &stream{
+		url:     u,
+		output:  opts.Output,
+		ready:   make(chan struct{}),
+		msgSize: msgSize,
+	}
+}
+
+// SetOnMessage sets the callback function to handle incoming messages on the connection.
+func (s *stream) SetOnMessage(onMessage func(context.Context, []byte, bool)) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	s.onMessage = onMessage
+}
+
+// URL returns the server address of the connection.
+func (s *stream) URL() string {
+	return s.url.String()
+}
+
+// Hostname returns the host name part of the server address.
+func (s *stream) Hostname() string {
+	return s.url.Hostname()
+}
+
+// Protocol returns nil, application protocols are available only over WebSocket.
+func (s *stream) Protocol() core.Protocol {
+	return nil
+}
+
+// Ping returns ErrNotSupported, the stream transports have no ping frames.
+func (s *stream) Ping(_ context.Context) error {
+	return fmt.Errorf("ping is %w", ErrNotSupported)
+}
+
+// PingStats returns empty statistics, the stream transports have no ping frames.
+func (s *stream) PingStats() core.PingStats {
+	return core.PingStats{}
+}
+
+// Metrics returns a snapshot of the message statistics of the connection.
+func (s *stream) Metrics() core.Metrics {
+	return s.metrics.metrics()
+}
+
+// Stats returns a snapshot of the payload and wire byte counters of the connection.
+func (s *stream) Stats() WireStats {
+	return s.counter.stats()
+}
+
+// Ready returns a channel that is closed when the connection is established.
+func (s *stream) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// CloseStatus returns the status the connection was closed with by CloseWithStatus.
+// It returns a zero CloseStatus if the connection was not closed locally.
+func (s *stream) CloseStatus() CloseStatus {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	return s.closeStatus
+}
+
+// Close terminates the connection with the normal closure status.
+// It returns an error if the connection is not established.
+func (s *stream) Close() error {
+	return s.CloseWithStatus(int(websocket.StatusNormalClosure), "closing connection")
+}
+
+// CloseWithStatus terminates the connection, the status is recorded but not sent, the stream transports have no close frames.
+// It takes code of type int and reason of type string.
+// It returns an error if the connection is not established.
+func (s *stream) CloseWithStatus(code int, reason string) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.cancel == nil {
+		return fmt.Errorf("connection is not established")
+	}
+
+	s.closed = true
+
+	if s.closeStatus.Code == 0 {
+		s.closeStatus = CloseStatus{Code: code, Reason: reason}
+	}
+
+	s.cancel()
+
+	return nil
+}
+
+// start prepares the connection for Connect.
+// It returns the context canceled by CloseWithStatus, and an error if the onMessage callback is not set
+// or the connection is already started.
+func (s *stream) start(ctx context.Context) (context.Context, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	if s.onMessage == nil {
+		return nil, fmt.Errorf("onMessage callback is not set")
+	}
+
+	if s.cancel != nil {
+		return nil, ErrAlreadyConnected
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	return ctx, nil
+}
+
+// opened marks the connection as established.
+func (s *stream) opened() {
+	s.metrics.started()
+	close(s.ready)
+}
+
+// finish converts the error that terminated the stream into the result of Connect.
+// It returns ErrConnectionClosed if the connection was closed with CloseWithStatus or by the server,
+// and nil if the context was canceled.
+func (s *stream) finish(err error) error {
+	s.l.Lock()
+	closed := s.closed
+	s.l.Unlock()
+
+	if closed {
+		return ErrConnectionClosed
+	}
+
+	return handleError(err)
+}
+
+// deliver registers the received message and passes it to the onMessage callback.
+func (s *stream) deliver(ctx context.Context, data []byte, isBinary bool) {
+	s.counter.payloadReceived.Add(uint64(len(data)))
+	s.metrics.recordReceived(int64(len(data)))
+
+	s.l.Lock()
+	onMessage := s.onMessage
+	s.l.Unlock()
+
+	onMessage(ctx, data, isBinary)
+}
+
+// sent registers a message of size bytes sent to the server.
+func (s *stream) sent(size int) {
+	s.counter.payloadSent.Add(uint64(size)) //nolint:gosec // size is never negative
+	s.metrics.recordSent(size)
+}
+
+// newLineScanner creates a scanner of the lines of r, with the line terminators removed.
+// It takes limit of type int64, the maximum length of a line.
+func newLineScanner(r io.Reader, limit int64) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, int(limit)+1) //nolint:gosec // limit is bounded by the maximum message size
+
+	return scanner
+}
+
+// scanError converts the error of the line scanner into the error that terminates the stream.
+// It returns io.EOF if the stream ended and an error if a line exceeds the maximum message size.
+func scanError(scanner *bufio.Scanner, limit int64) error {
+	err := scanner.Err()
+
+	switch {
+	case err == nil:
+		return io.EOF
+	case errors.Is(err, bufio.ErrTooLong):
+		return fmt.Errorf("message exceeds the maximum size of %d bytes", limit)
+	default:
+		return err
+	}
+}
@@ -1,12 +1,14 @@
 package ws
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
 	"sync"
@@ -14,36 +16,88 @@ import (
 	"time"
 
 	"github.com/coder/websocket"
+	"github.com/ksysoev/wsget/pkg/core"
 )
 
 const (
-	DefaultMaxMessageSize = 1024 * 1024
+	DefaultMaxMessageSize    = 1024 * 1024
+	DefaultKeepaliveFailures = 3
+
+	CompressionDisabled          = "disabled"
+	CompressionContextTakeover   = "context-takeover"
+	CompressionNoContextTakeover = "no-context-takeover"
 )
 
-var ErrConnectionClosed = errors.New("connection closed")
+var (
+	ErrConnectionClosed = errors.New("connection closed")
+	ErrAlreadyConnected = errors.New("connection already established")
+	ErrNoSubprotocol    = errors.New("server did not select any of the requested subprotocols")
+	ErrDeadConnection   = errors.New("connection is dead")
+)
 
 type reader interface {
 	Read(p []byte) (n int, err error)
 }
 
+type sentMessage struct {
+	data    []byte
+	msgType websocket.MessageType
+}
+
 type Connection struct {
-	output    io.Writer
-	url       *url.URL
-	ws        *websocket.Conn
-	onMessage func(context.Context, []byte, bool)
-	opts      *websocket.DialOptions
-	ready     chan struct{}
-	msgSize   int64
-	l         sync.Mutex
+	output        io.Writer
+	timing        io.Writer
+	reconnect     *ReconnectPolicy
+	faults        *faultInjector
+	protocol      core.Protocol
+	ws            *websocket.Conn
+	onMessage     func(context.Context, []byte, bool)
+	onStateChange func(context.Context, State, int)
+	onClose       func(context.Context, CloseStatus)
+	opts          *websocket.DialOptions
+	url           *url.URL
+	ready         chan struct{}
+	connected     chan struct{}
+	oversize      oversizeOptions
+	sent          []sentMessage
+	closeStatus   CloseStatus
+	latency       latencyTracker
+	metrics       metricsCollector
+	counter       wireCounter
+	keepalive     keepaliveOptions
+	msgSize       int64
+	l             sync.Mutex
+	closed        bool
 }
 
 type Options struct {
-	Output              io.Writer
-	UserAgent           string
-	Headers             []string
-	MaxMessageSize      int64
-	Timeout             time.Duration
-	SkipSSLVerification bool
+	Output               io.Writer
+	Timing               io.Writer
+	Reconnect            *ReconnectPolicy
+	Protocol             core.Protocol
+	TLS                  TLSOptions
+	UserAgent            string
+	Proxy                string
+	Compression          string
+	Oversize             string
+	OversizeDir          string
+	Subprotocols         []string
+	Headers              []string
+	Dial                 DialOptions
+	Faults               FaultOptions
+	MaxMessageSize       int64
+	Timeout              time.Duration
+	Keepalive            time.Duration
+	KeepaliveTimeout     time.Duration
+	CompressionThreshold int
+	KeepaliveFailures    int
+	SkipSSLVerification  bool
+}
+
+type keepaliveOptions struct {
+	interval time.Duration
+	timeout  time.Duration
+	failures int
 }
 
 // New initializes a new WebSocket connection configuration with specified URL and options.
@@ -63,11 +117,132 @@ func New(wsURL string, opts *Options) (*Connection, error) {
 		return nil, fmt.Errorf("failed to parse WebSocket URL %q: %w", wsURL, err)
 	}
 
-	httpCli := &http.Client{
-		Transport: newRequestLogger(opts.Output, opts.SkipSSLVerification),
-		Timeout:   opts.Timeout,
+	compressionMode, err := parseCompressionMode(opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := parseHeaders(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	subprotocols := opts.Subprotocols
+
+	if opts.Protocol != nil {
+		opts.Protocol.PrepareURL(parsedURL)
+
+		if len(subprotocols) == 0 {
+			subprotocols = opts.Protocol.Subprotocols()
+		}
 	}
 
+	oversizePolicy, err := parseOversizePolicy(opts.Oversize)
+	if err != nil {
+		return nil, err
+	}
+
+	faults, err := newFaultInjector(opts.Faults, opts.Output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fault options: %w", err)
+	}
+
+	msgSize := opts.MaxMessageSize
+	if msgSize <= 0 {
+		msgSize = DefaultMaxMessageSize
+	}
+
+	ready := make(chan struct{})
+
+	conn := &Connection{
+		url:       parsedURL,
+		reconnect: opts.Reconnect,
+		faults:    faults,
+		protocol:  opts.Protocol,
+		ready:     ready,
+		connected: ready,
+		msgSize:   msgSize,
+		output:    opts.Output,
+		timing:    opts.Timing,
+		oversize: oversizeOptions{
+			policy: oversizePolicy,
+			dir:    opts.OversizeDir,
+		},
+		keepalive: keepaliveOptions{
+			interval: opts.Keepalive,
+			timeout:  cmp.Or(opts.KeepaliveTimeout, opts.Keepalive),
+			failures: cmp.Or(opts.KeepaliveFailures, DefaultKeepaliveFailures),
+		},
+	}
+
+	transport, err := newHTTPTransport(parsedURL, opts, &conn.counter)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.opts = &websocket.DialOptions{
+		HTTPClient: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+		},
+		HTTPHeader:           headers,
+		Subprotocols:         subprotocols,
+		CompressionMode:      compressionMode,
+		CompressionThreshold: opts.CompressionThreshold,
+	}
+
+	return conn, nil
+}
+
+// newHTTPTransport creates the HTTP transport used to reach the server with the dial, proxy and TLS options applied.
+// It takes target of type *url.URL, the server address used to select the proxy, opts of type *Options,
+// and counter of type *wireCounter, which counts the bytes transferred through the dialed connections.
+// It returns a pointer to requestLogger and an error if the dial, proxy or TLS options are invalid.
+func newHTTPTransport(target *url.URL, opts *Options, counter *wireCounter) (*requestLogger, error) {
+	netDialer, err := newDialer(opts.Dial, opts.Output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dial options: %w", err)
+	}
+
+	var proxyURL *url.URL
+
+	switch {
+	case opts.Dial.UnixSocket != "" && opts.Proxy != "":
+		return nil, fmt.Errorf("unix socket cannot be used with proxy")
+	case opts.Dial.UnixSocket == "":
+		if proxyURL, err = resolveProxy(opts.Proxy, target); err != nil {
+			return nil, err
+		}
+	}
+
+	tlsConfig, err := newTLSConfig(opts.TLS, opts.SkipSSLVerification)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+	}
+
+	transport := newRequestLogger(opts.Output, opts.SkipSSLVerification)
+	transport.transport.TLSClientConfig = tlsConfig
+	transport.proxy = proxyURL
+
+	dial := counter.dialContext(netDialer.DialContext)
+
+	switch {
+	case proxyURL == nil:
+		transport.transport.DialContext = dial
+	case isSOCKSProxy(proxyURL):
+		transport.transport.Proxy = http.ProxyURL(proxyURL)
+		transport.transport.DialContext = dial
+	default:
+		transport.transport.DialContext = newProxyDialer(proxyURL, dial, opts.Output, opts.SkipSSLVerification).DialContext
+	}
+
+	return transport, nil
+}
+
+// parseHeaders converts the headers in the "Name: value" format into http.Header.
+// The user agent from opts is added unless the User-Agent header is provided explicitly.
+// It returns an error if a header has no colon.
+func parseHeaders(opts *Options) (http.Header, error) {
 	headers := make(http.Header)
 
 	for _, headerInput := range opts.Headers {
@@ -83,20 +258,23 @@ func New(wsURL string, opts *Options) (*Connection, error) {
 		headers.Set("User-Agent", opts.UserAgent)
 	}
 
-	wsOpts := &websocket.DialOptions{
-		HTTPClient: httpCli,
-		HTTPHeader: headers,
-	}
-
-	var msgSize int64 = DefaultMaxMessageSize
+	return headers, nil
+}
 
-	return &Connection{
-		url:     parsedURL,
-		opts:    wsOpts,
-		ready:   make(chan struct{}),
-		msgSize: msgSize,
-		output:  opts.Output,
-	}, nil
+// parseCompressionMode converts the compression mode name into a websocket.CompressionMode.
+// It takes mode of type string, an empty mode is treated as disabled compression.
+// It returns an error if the mode name is not supported.
+func parseCompressionMode(mode string) (websocket.CompressionMode, error) {
+	switch mode {
+	case "", CompressionDisabled:
+		return websocket.CompressionDisabled, nil
+	case CompressionContextTakeover:
+		return websocket.CompressionContextTakeover, nil
+	case CompressionNoContextTakeover:
+		return websocket.CompressionNoContextTakeover, nil
+	default:
+		return 0, fmt.Errorf("invalid compression mode: %s", mode)
+	}
 }
 
 // SetOnMessage sets the callback function to handle incoming messages on the connection.
@@ -109,52 +287,288 @@ func (c *Connection) SetOnMessage(onMessage func(context.Context, []byte, bool))
 	c.onMessage = onMessage
 }
 
+// SetOnStateChange sets the callback function to be notified about connection state changes.
+// It takes onStateChange, a function receiving the context, the new State and the reconnect attempt number.
+// The attempt number is 0 for the initial connection and is reset after every successful reconnection.
+func (c *Connection) SetOnStateChange(onStateChange func(context.Context, State, int)) {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.onStateChange = onStateChange
+}
+
 // Connect establishes a WebSocket connection using the specified context.
 // It returns an error if the onMessage callback is not set, the connection attempt fails,
 // or if a connection is already established.
 // The method locks the connection during setup to ensure thread safety and sets a default read limit on the WebSocket.
+// If a reconnect policy is configured, a dropped connection is re-established with exponential backoff
+// until the policy's attempt limit is reached, the context is canceled or the connection is closed with Close.
 func (c *Connection) Connect(ctx context.Context) error {
 	if c.onMessage == nil {
 		return fmt.Errorf("onMessage callback is not set")
 	}
 
-	startTime := time.Now()
-	ws, resp, err := websocket.Dial(ctx, c.url.String(), c.opts)
+	if c.output != nil {
+		defer func() { c.Stats().Print(c.output) }()
+	}
+
+	ws, err := c.dial(ctx)
+	if err != nil || ws == nil {
+		return err
+	}
+
+	err = c.serve(ctx, ws, 0)
+
+	for attempt := 1; c.canReconnect(ctx, err); attempt++ {
+		if attempt == 1 {
+			c.disconnect()
+			c.notifyState(ctx, StateDisconnected, 0)
+		}
+
+		if c.reconnect.exhausted(attempt) {
+			return fmt.Errorf("failed to reconnect after %d attempts: %w", attempt-1, err)
+		}
+
+		c.notifyState(ctx, StateReconnecting, attempt)
+
+		if wait(ctx, c.reconnect.delay(attempt)) != nil {
+			return nil
+		}
+
+		conn, dialErr := c.dial(ctx)
+		if dialErr != nil {
+			err = dialErr
+			continue
+		}
+
+		if conn == nil {
+			return nil
+		}
+
+		err = c.serve(ctx, conn, attempt)
+		attempt = 0
+	}
+
+	return err
+}
+
+// dial performs the WebSocket handshake with the server and reports the timing of the handshake phases.
+// It takes ctx of type context.Context to control the handshake lifetime.
+// It returns the established websocket.Conn, or nil without error if the context was canceled during the handshake.
+// It returns an error if the handshake fails.
+func (c *Connection) dial(ctx context.Context) (*websocket.Conn, error) {
+	tracer := newHandshakeTracer()
+	ws, resp, err := websocket.Dial(httptrace.WithClientTrace(ctx, tracer.clientTrace()), c.url.String(), c.opts)
+	timing := tracer.timing()
 
 	if c.output != nil {
-		handshakeDuration := time.Since(startTime)
+		fmt.Fprintf(c.output, "WebSocket handshake completed in %v (%s)\n", timing.Total, timing)
+	}
 
-		fmt.Fprintf(c.output, "WebSocket handshake completed in %v\n", handshakeDuration)
+	if c.timing != nil && err == nil {
+		if err := writeTiming(c.timing, c.url.String(), timing); err != nil {
+			_ = ws.CloseNow()
+			return nil, err
+		}
 	}
 
 	if err != nil {
 		err = handleError(err)
 		if err != nil {
-			return fmt.Errorf("failed to dial WebSocket: %w", err)
+			return nil, fmt.Errorf("failed to dial WebSocket: %w", err)
 		}
 
-		return nil
+		return nil, nil
 	}
 
 	if resp.Body != nil {
 		_ = resp.Body.Close()
 	}
 
+	if len(c.opts.Subprotocols) > 0 && ws.Subprotocol() == "" {
+		_ = ws.Close(websocket.StatusProtocolError, "no subprotocol selected")
+
+		return nil, fmt.Errorf("%w, requested: %s", ErrNoSubprotocol, strings.Join(c.opts.Subprotocols, ", "))
+	}
+
+	return ws, nil
+}
+
+// serve registers the established WebSocket connection and processes incoming messages until it is closed.
+// It takes ctx of type context.Context, ws of type *websocket.Conn and attempt, the reconnect attempt that produced ws.
+// It returns ErrAlreadyConnected if another connection is active, or the error that terminated message handling.
+// After a reconnection it re-sends the recorded requests if the reconnect policy enables replay.
+func (c *Connection) serve(ctx context.Context, ws *websocket.Conn, attempt int) error {
 	c.l.Lock()
 
 	if c.ws != nil {
 		c.l.Unlock()
-		return fmt.Errorf("connection already established")
+		return ErrAlreadyConnected
 	}
 
 	c.ws = ws
-	close(c.ready)
+	c.closeStatus = CloseStatus{}
+	close(c.connected)
+
+	var replay []sentMessage
+	if attempt > 0 {
+		replay, c.sent = c.sent, nil
+	}
+
+	c.l.Unlock()
+
+	ws.SetReadLimit(c.oversize.readLimit(c.msgSize))
+	c.metrics.started()
+	c.faults.reset()
+
+	stopKillTimer := c.faults.startKillTimer(ws)
+	defer stopKillTimer()
+
+	c.notifyState(ctx, StateConnected, attempt)
+
+	if err := c.openProtocol(ctx, ws); err != nil {
+		return err
+	}
+
+	for _, msg := range replay {
+		if err := c.write(ctx, ws, msg.msgType, msg.data); err != nil {
+			return fmt.Errorf("failed to replay request: %w", err)
+		}
+	}
+
+	var background []func(context.Context, *websocket.Conn) error
+
+	if c.keepalive.interval > 0 {
+		background = append(background, c.runKeepalive)
+	}
+
+	if _, ok := c.protocol.(core.Heartbeater); ok {
+		background = append(background, c.runHeartbeat)
+	}
+
+	if len(background) == 0 {
+		return c.handleResponses(ctx, ws)
+	}
+
+	bgCtx, stopBackground := context.WithCancel(ctx)
+	bgDone := make(chan error, len(background))
+
+	for _, task := range background {
+		go func() { bgDone <- task(bgCtx, ws) }()
+	}
+
+	err := c.handleResponses(ctx, ws)
+
+	stopBackground()
+
+	var bgErr error
+
+	for range background {
+		if taskErr := <-bgDone; taskErr != nil && bgErr == nil {
+			bgErr = taskErr
+		}
+	}
+
+	if bgErr != nil {
+		return bgErr
+	}
+
+	return err
+}
+
+// runKeepalive periodically sends ping frames over ws until the context is canceled.
+// It takes ctx of type context.Context and ws of type *websocket.Conn.
+// It returns ErrDeadConnection and closes ws without a close handshake
+// if the configured number of consecutive pings is not answered in time, otherwise it returns nil.
+func (c *Connection) runKeepalive(ctx context.Context, ws *websocket.Conn) error {
+	ticker := time.NewTicker(c.keepalive.interval)
+	defer ticker.Stop()
 
+	failures := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, c.keepalive.timeout)
+		startTime := time.Now()
+		err := ws.Ping(timeoutCtx)
+
+		cancel()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err == nil {
+			c.latency.record(time.Since(startTime))
+
+			failures = 0
+
+			continue
+		}
+
+		c.latency.timeout()
+
+		if failures++; failures >= c.keepalive.failures {
+			_ = ws.CloseNow()
+
+			return fmt.Errorf("%w: %d consecutive pings failed: %w", ErrDeadConnection, failures, err)
+		}
+	}
+}
+
+// canReconnect reports whether the connection should be re-established after it was terminated with err.
+func (c *Connection) canReconnect(ctx context.Context, err error) bool {
+	if c.reconnect == nil || err == nil || ctx.Err() != nil || errors.Is(err, ErrAlreadyConnected) {
+		return false
+	}
+
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	return !c.closed
+}
+
+// disconnect drops the reference to the terminated WebSocket connection,
+// so that pending and future sends wait for the next successful reconnection.
+func (c *Connection) disconnect() {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.ws == nil {
+		return
+	}
+
+	c.ws = nil
+	c.connected = make(chan struct{})
+}
+
+// notifyState invokes the state change callback, if it is set, with the given state and attempt number.
+func (c *Connection) notifyState(ctx context.Context, state State, attempt int) {
+	c.l.Lock()
+	onStateChange := c.onStateChange
 	c.l.Unlock()
 
-	ws.SetReadLimit(c.msgSize)
+	if onStateChange != nil {
+		onStateChange(ctx, state, attempt)
+	}
+}
+
+// Subprotocol returns the subprotocol selected by the server during the handshake.
+// It returns an empty string if the connection is not established or no subprotocol was negotiated.
+func (c *Connection) Subprotocol() string {
+	c.l.Lock()
+	defer c.l.Unlock()
 
-	return c.handleResponses(ctx, ws)
+	if c.ws == nil {
+		return ""
+	}
+
+	return c.ws.Subprotocol()
 }
 
 // Hostname retrieves the host name part of the URL stored in the Connection struct.
@@ -163,14 +577,25 @@ func (c *Connection) Hostname() string {
 	return c.url.Hostname()
 }
 
+// URL returns the WebSocket server address of the connection.
+func (c *Connection) URL() string {
+	return c.url.String()
+}
+
 // handleResponses manages incoming messages on a WebSocket connection until the context is canceled.
 // It takes a context (ctx) for cancellation control and a websocket connection (ws) for message communication.
 // It returns an error if there is an issue reading from the WebSocket or if handling a message fails.
 // The function terminates without error if the context is canceled.
 func (c *Connection) handleResponses(ctx context.Context, ws *websocket.Conn) error {
 	for ctx.Err() == nil {
+		if err := c.faults.beforeRead(ctx); err != nil {
+			return nil
+		}
+
 		msgType, reader, err := ws.Reader(ctx)
 		if err != nil {
+			c.handleCloseFrame(ctx, err)
+
 			err = handleError(err)
 			if err != nil {
 				return fmt.Errorf("failed to read from WebSocket: %w", err)
@@ -179,7 +604,7 @@ func (c *Connection) handleResponses(ctx context.Context, ws *websocket.Conn) er
 			return nil
 		}
 
-		if err := c.handleMessage(ctx, msgType, reader); err != nil {
+		if err := c.handleMessage(ctx, ws, msgType, reader); err != nil {
 			err = handleError(err)
 			if err != nil {
 				return fmt.Errorf("failed to handle message: %w", err)
@@ -187,26 +612,46 @@ func (c *Connection) handleResponses(ctx context.Context, ws *websocket.Conn) er
 
 			return nil
 		}
+
+		c.faults.countMessage(ws)
 	}
 
 	return nil
 }
 
 // handleMessage processes an incoming WebSocket message for the Connection.
-// It takes ctx of type context.Context, msgType of type websocket.MessageType, and msgReader of type reader.
+// It takes ctx of type context.Context, ws of type *websocket.Conn for protocol replies,
+// msgType of type websocket.MessageType, and msgReader of type reader.
 // It returns an error if reading from the reader fails.
 // The function reads all data from msgReader and invokes the onMessage callback with the read data and a binary flag.
-func (c *Connection) handleMessage(ctx context.Context, msgType websocket.MessageType, msgReader reader) error {
+func (c *Connection) handleMessage(ctx context.Context, ws *websocket.Conn, msgType websocket.MessageType, msgReader reader) error {
 	isBinary := msgType == websocket.MessageBinary
 
-	data, err := io.ReadAll(msgReader)
+	if !c.oversize.enabled() {
+		data, err := io.ReadAll(msgReader)
+		if err != nil {
+			return fmt.Errorf("fail to read message: %w", err)
+		}
+
+		c.counter.payloadReceived.Add(uint64(len(data)))
+		c.metrics.recordReceived(int64(len(data)))
+
+		return c.deliver(ctx, ws, data, isBinary)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(msgReader, c.msgSize+1))
 	if err != nil {
 		return fmt.Errorf("fail to read message: %w", err)
 	}
 
-	c.onMessage(ctx, data, isBinary)
+	if int64(len(data)) > c.msgSize {
+		return c.handleOversize(ctx, data, msgReader, isBinary)
+	}
 
-	return nil
+	c.counter.payloadReceived.Add(uint64(len(data)))
+	c.metrics.recordReceived(int64(len(data)))
+
+	return c.deliver(ctx, ws, data, isBinary)
 }
 
 // handleError processes an error arising from a WebSocket connection.
@@ -239,102 +684,152 @@ func handleError(err error) error {
 // It returns an error if the context is canceled or if there is a failure writing to the WebSocket.
 // The function waits for the connection to be ready before sending the message.
 func (c *Connection) Send(ctx context.Context, msg string) error {
-	select {
-	case <-c.ready:
-	case <-ctx.Done():
-		return fmt.Errorf("context canceled while waiting to send: %w", ctx.Err())
+	ws, err := c.waitConnection(ctx)
+	if err != nil {
+		return fmt.Errorf("context canceled while waiting to send: %w", err)
 	}
 
-	c.l.Lock()
-	ws := c.ws
-	c.l.Unlock()
-
 	if ws == nil {
 		return fmt.Errorf("connection not established")
 	}
 
-	err := ws.Write(ctx, websocket.MessageText, []byte(msg))
-	if err != nil {
-		err = handleError(err)
-		if err != nil {
-			return fmt.Errorf("failed to write to WebSocket: %w", err)
-		}
-	}
-
-	return nil
+	return c.write(ctx, ws, websocket.MessageText, []byte(msg))
 }
 
 // SendBinary transmits binary data over an established WebSocket connection within a given context.
 // It takes ctx of type context.Context and data of type []byte as parameters.
 // It returns an error if the context is canceled or if there is a failure writing to the WebSocket.
 func (c *Connection) SendBinary(ctx context.Context, data []byte) error {
+	ws, err := c.waitConnection(ctx)
+	if err != nil {
+		return fmt.Errorf("context canceled while waiting to send: %w", err)
+	}
+
+	if ws == nil {
+		return fmt.Errorf("connection not established")
+	}
+
+	return c.write(ctx, ws, websocket.MessageBinary, data)
+}
+
+// waitConnection waits until the WebSocket connection is established.
+// It takes ctx of type context.Context to limit the waiting time.
+// It returns the current websocket.Conn, which may be nil, or the context error if the context is canceled first.
+func (c *Connection) waitConnection(ctx context.Context) (*websocket.Conn, error) {
+	c.l.Lock()
+	connected := c.connected
+	c.l.Unlock()
+
 	select {
-	case <-c.ready:
+	case <-connected:
 	case <-ctx.Done():
-		return fmt.Errorf("context canceled while waiting to send: %w", ctx.Err())
+		return nil, ctx.Err()
 	}
 
 	c.l.Lock()
-	ws := c.ws
-	c.l.Unlock()
+	defer c.l.Unlock()
 
-	if ws == nil {
-		return fmt.Errorf("connection not established")
+	return c.ws, nil
+}
+
+// write sends a message of the given type over ws and records it for replay if the reconnect policy requires it.
+// It returns an error if there is a failure writing to the WebSocket.
+func (c *Connection) write(ctx context.Context, ws *websocket.Conn, msgType websocket.MessageType, data []byte) error {
+	written, err := c.transmit(ctx, ws, msgType, data)
+	if err != nil || !written {
+		return err
 	}
 
-	err := ws.Write(ctx, websocket.MessageBinary, data)
+	if c.reconnect != nil && c.reconnect.Replay {
+		c.l.Lock()
+		c.sent = append(c.sent, sentMessage{data: data, msgType: msgType})
+		c.l.Unlock()
+	}
+
+	return nil
+}
+
+// transmit sends a message of the given type over ws applying the configured faults.
+// It returns false without error if the connection was closed while writing,
+// and an error if there is a failure writing to the WebSocket.
+func (c *Connection) transmit(ctx context.Context, ws *websocket.Conn, msgType websocket.MessageType, data []byte) (bool, error) {
+	copies, err := c.faults.beforeSend(ctx)
 	if err != nil {
-		err = handleError(err)
+		return false, fmt.Errorf("context canceled while delaying send: %w", err)
+	}
+
+	for range copies {
+		err := ws.Write(ctx, msgType, data)
 		if err != nil {
-			return fmt.Errorf("failed to write to WebSocket: %w", err)
+			err = handleError(err)
+			if err != nil {
+				return false, fmt.Errorf("failed to write to WebSocket: %w", err)
+			}
+
+			return false, nil
 		}
+
+		c.counter.payloadSent.Add(uint64(len(data)))
+		c.metrics.recordSent(len(data))
+		c.faults.countMessage(ws)
 	}
 
-	return nil
+	return true, nil
 }
 
 // Ping sends a ping frame to the WebSocket server to check the connection's liveness.
 // It takes ctx of type context.Context as a parameter.
 // It returns an error if the context is canceled or if there is a failure sending the ping
 func (c *Connection) Ping(ctx context.Context) error {
-	select {
-	case <-c.ready:
-	case <-ctx.Done():
-		return fmt.Errorf("context canceled while waiting to ping: %w", ctx.Err())
+	ws, err := c.waitConnection(ctx)
+	if err != nil {
+		return fmt.Errorf("context canceled while waiting to ping: %w", err)
 	}
 
-	c.l.Lock()
-	ws := c.ws
-	c.l.Unlock()
-
 	if ws == nil {
 		return fmt.Errorf("connection not established")
 	}
 
-	err := ws.Ping(ctx)
+	startTime := time.Now()
+
+	err = ws.Ping(ctx)
 	if err != nil {
 		err = handleError(err)
 		if err != nil {
 			return fmt.Errorf("failed to send ping: %w", err)
 		}
+
+		return nil
 	}
 
+	c.latency.record(time.Since(startTime))
+
 	return nil
 }
 
+// PingStats returns the round-trip time statistics of the pings sent over the connection,
+// including both keepalive and manually requested pings.
+func (c *Connection) PingStats() core.PingStats {
+	return c.latency.stats()
+}
+
 // Close shuts down an established WebSocket connection gracefully.
 // It returns an error if the connection is not yet established.
-// The function ensures a normal closure status is sent to the WebSocket server.
+// The function ensures a normal closure status is sent to the WebSocket server and disables reconnection.
 func (c *Connection) Close() error {
-	c.l.Lock()
-	ws := c.ws
-	c.l.Unlock()
+	return c.CloseWithStatus(int(websocket.StatusNormalClosure), "closing connection")
+}
 
-	if ws == nil {
-		return fmt.Errorf("connection is not established")
-	}
+// Stats returns a snapshot of the payload and wire byte counters of the connection.
+// Comparing payload and wire counters shows the bandwidth impact of permessage-deflate compression.
+func (c *Connection) Stats() WireStats {
+	return c.counter.stats()
+}
 
-	return ws.Close(websocket.StatusNormalClosure, "closing connection")
+// Metrics returns a snapshot of the message statistics of the connection.
+// The statistics are accumulated over the whole lifetime of the connection, including reconnections.
+func (c *Connection) Metrics() core.Metrics {
+	return c.metrics.metrics()
 }
 
 // Ready returns a channel that is closed when the WebSocket connection is established.
@@ -106,7 +106,7 @@ func TestConnection_HandleMessage(t *testing.T) {
 				},
 			}
 
-			err := conn.handleMessage(context.Background(), tt.msgType, msgReader)
+			err := conn.handleMessage(context.Background(), nil, tt.msgType, msgReader)
 
 			if tt.expectErr {
 				assert.Error(t, err)
@@ -176,6 +176,14 @@ func TestNew(t *testing.T) {
 			options:   &Options{},
 			wantError: true,
 		},
+		{
+			name: "Invalid compression mode",
+			url:  "ws://localhost:8080",
+			options: &Options{
+				Compression: "gzip",
+			},
+			wantError: true,
+		},
 		{
 			name:      "Nil options treated as defaults",
 			url:       "ws://localhost:8080",
@@ -852,3 +860,130 @@ func TestConnection_HandleMessage_Binary(t *testing.T) {
 		t.Fatal("timeout waiting for isBinary flag")
 	}
 }
+
+func TestConnection_Subprotocol(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"v2.json"}})
+		if err != nil {
+			return
+		}
+
+		_, _, _ = c.Read(r.Context())
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name         string
+		expected     string
+		expectedErr  error
+		subprotocols []string
+	}{
+		{
+			name:         "server selects subprotocol",
+			subprotocols: []string{"v1.json", "v2.json"},
+			expected:     "v2.json",
+		},
+		{
+			name:         "server selects none",
+			subprotocols: []string{"mqtt"},
+			expectedErr:  ErrNoSubprotocol,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := New("ws://"+s.Listener.Addr().String(), &Options{Subprotocols: tt.subprotocols})
+			assert.NoError(t, err)
+			assert.Equal(t, "", conn.Subprotocol())
+
+			conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+
+			go func() { done <- conn.Connect(ctx) }()
+
+			if tt.expectedErr != nil {
+				err := <-done
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.ErrorContains(t, err, "requested: mqtt")
+
+				cancel()
+
+				return
+			}
+
+			select {
+			case <-conn.Ready():
+			case <-time.After(time.Second):
+				t.Fatal("timeout waiting for connection")
+			}
+
+			assert.Equal(t, tt.expected, conn.Subprotocol())
+
+			cancel()
+			<-done
+		})
+	}
+}
+
+func TestConnection_Keepalive(t *testing.T) {
+	s := httptest.NewServer(createEchoWSHandler())
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{Keepalive: 10 * time.Millisecond})
+	assert.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		return conn.PingStats().Count >= 3
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, 0, conn.PingStats().Timeouts)
+}
+
+func TestConnection_Keepalive_DeadConnection(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = c.CloseNow() }()
+
+		// The server never reads from the connection, so pings are never answered.
+		<-r.Context().Done()
+	}))
+	defer s.Close()
+
+	conn, err := New("ws://"+s.Listener.Addr().String(), &Options{
+		Keepalive:         10 * time.Millisecond,
+		KeepaliveTimeout:  10 * time.Millisecond,
+		KeepaliveFailures: 2,
+	})
+	assert.NoError(t, err)
+
+	conn.SetOnMessage(func(context.Context, []byte, bool) {})
+
+	done := make(chan error, 1)
+
+	go func() { done <- conn.Connect(context.Background()) }()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrDeadConnection)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for dead connection detection")
+	}
+
+	assert.Equal(t, 2, conn.PingStats().Timeouts)
+}